@@ -106,6 +106,11 @@ func (a *accounter) Add(n int64) int64 {
 	return atomic.AddInt64(&a.current, n)
 }
 
+// Rollback takes back bytes which have to be transferred again.
+func (a *accounter) Rollback(n int64) int64 {
+	return atomic.AddInt64(&a.current, -n)
+}
+
 func (a *accounter) NewProxyReader(r io.ReadCloser) *accountingReader {
 	return &accountingReader{r, a}
 }
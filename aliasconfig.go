@@ -32,7 +32,9 @@ func isValidAliasName(aliasName string) bool {
 }
 
 // getAliasURL expands aliased (name/path) to full URL, used by url-parser.
+// Aliases defined by ‘MC_HOST_<alias>’ environment variables take precedence.
 func getAliasURL(aliasedURL string, aliases map[string]string) string {
+	aliases = withEnvAliases(aliases)
 	for aliasName, aliasValue := range aliases {
 		if strings.HasPrefix(aliasedURL, aliasName) {
 			// Match found. Expand it.
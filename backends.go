@@ -0,0 +1,26 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+// Storage backends compiled into mc, they register themselves with pkg/client. To compile in
+// another backend, add a file to this package with a blank import of the backend's package.
+import (
+	_ "github.com/minio/mc/pkg/client/fs"
+	_ "github.com/minio/mc/pkg/client/mem"
+	_ "github.com/minio/mc/pkg/client/s3v2"
+	_ "github.com/minio/mc/pkg/client/s3v4"
+)
@@ -0,0 +1,152 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/transfer"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// bandwidthConfig - upload and download limits for cp and mirror, in config file.
+type bandwidthConfig struct {
+	Upload   string              `json:"upload,omitempty"`
+	Download string              `json:"download,omitempty"`
+	Schedule []bandwidthSchedule `json:"schedule,omitempty"`
+}
+
+// bandwidthSchedule - limits which replace the default ones between From and To
+// local time, formatted as ‘15:04’. Empty or ‘unlimited’ lifts the limit.
+type bandwidthSchedule struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Upload   string `json:"upload,omitempty"`
+	Download string `json:"download,omitempty"`
+}
+
+// ‘--limit-upload unlimited’ lifts any configured limit.
+const rateUnlimited = "unlimited"
+
+// parseRate parses rates such as ‘20MiB/s’ into bytes per second, 0 stands for unlimited.
+func parseRate(rate string) (int64, *probe.Error) {
+	rate = strings.TrimSpace(rate)
+	if rate == "" || rate == rateUnlimited {
+		return 0, nil
+	}
+	bytes, e := humanize.ParseBytes(strings.TrimSuffix(rate, "/s"))
+	if e != nil || bytes == 0 {
+		return 0, errInvalidRate(rate).Trace()
+	}
+	return int64(bytes), nil
+}
+
+// parseTimeOfDay parses ‘15:04’ into minutes since midnight.
+func parseTimeOfDay(value string) (int, *probe.Error) {
+	t, e := time.Parse("15:04", strings.TrimSpace(value))
+	if e != nil {
+		return 0, probe.NewError(e)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// bandwidthWindow - parsed bandwidthSchedule.
+type bandwidthWindow struct {
+	from, to         int // minutes since midnight
+	upload, download int64
+}
+
+// contains returns true if now falls in this window, windows may wrap around midnight.
+func (w bandwidthWindow) contains(now time.Time) bool {
+	minute := now.Hour()*60 + now.Minute()
+	if w.from <= w.to {
+		return minute >= w.from && minute < w.to
+	}
+	return minute >= w.from || minute < w.to
+}
+
+// newBandwidthLimits - instantiate limits for upload and download rates, schedule overrides them by time of day.
+func newBandwidthLimits(upload, download string, schedule []bandwidthSchedule) (*transfer.Limits, *probe.Error) {
+	uploadRate, err := parseRate(upload)
+	if err != nil {
+		return nil, err.Trace(upload)
+	}
+	downloadRate, err := parseRate(download)
+	if err != nil {
+		return nil, err.Trace(download)
+	}
+	var windows []bandwidthWindow
+	for _, s := range schedule {
+		var w bandwidthWindow
+		if w.from, err = parseTimeOfDay(s.From); err != nil {
+			return nil, errInvalidSchedule(s.From).Trace()
+		}
+		if w.to, err = parseTimeOfDay(s.To); err != nil {
+			return nil, errInvalidSchedule(s.To).Trace()
+		}
+		if w.upload, err = parseRate(s.Upload); err != nil {
+			return nil, err.Trace(s.Upload)
+		}
+		if w.download, err = parseRate(s.Download); err != nil {
+			return nil, err.Trace(s.Download)
+		}
+		windows = append(windows, w)
+	}
+	limits := &transfer.Limits{
+		Upload: func(now time.Time) int64 {
+			for _, w := range windows {
+				if w.contains(now) {
+					return w.upload
+				}
+			}
+			return uploadRate
+		},
+		Download: func(now time.Time) int64 {
+			for _, w := range windows {
+				if w.contains(now) {
+					return w.download
+				}
+			}
+			return downloadRate
+		},
+	}
+	return limits, nil
+}
+
+// getBandwidthLimits returns limits for a session, its upload and download
+// limits override the ones in config file.
+func getBandwidthLimits(session *sessionV2) (*transfer.Limits, *probe.Error) {
+	config, err := getMcConfig()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	bandwidth := bandwidthConfig{}
+	if config.Bandwidth != nil {
+		bandwidth = *config.Bandwidth
+	}
+	upload := bandwidth.Upload
+	if session.Header.LimitUpload != "" {
+		upload = session.Header.LimitUpload
+	}
+	download := bandwidth.Download
+	if session.Header.LimitDownload != "" {
+		download = session.Header.LimitDownload
+	}
+	return newBandwidthLimits(upload, download, bandwidth.Schedule)
+}
@@ -0,0 +1,69 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestParseRate(c *C) {
+	rate, perr := parseRate("20MiB/s")
+	c.Assert(perr, IsNil)
+	c.Assert(rate, Equals, int64(20*1024*1024))
+
+	rate, perr = parseRate("1MB")
+	c.Assert(perr, IsNil)
+	c.Assert(rate, Equals, int64(1000*1000))
+
+	rate, perr = parseRate("")
+	c.Assert(perr, IsNil)
+	c.Assert(rate, Equals, int64(0))
+
+	rate, perr = parseRate("unlimited")
+	c.Assert(perr, IsNil)
+	c.Assert(rate, Equals, int64(0))
+
+	_, perr = parseRate("fast")
+	c.Assert(perr, Not(IsNil))
+	_, perr = parseRate("0/s")
+	c.Assert(perr, Not(IsNil))
+}
+
+func (s *TestSuite) TestBandwidthSchedule(c *C) {
+	limits, perr := newBandwidthLimits("1MiB/s", "", []bandwidthSchedule{
+		{From: "23:00", To: "05:00", Upload: "unlimited"},
+		{From: "12:00", To: "13:00", Download: "1KiB/s"},
+	})
+	c.Assert(perr, IsNil)
+
+	at := func(clock string) time.Time {
+		t, e := time.Parse("15:04", clock)
+		c.Assert(e, IsNil)
+		return t
+	}
+	c.Assert(limits.Upload(at("22:59")), Equals, int64(1024*1024))
+	c.Assert(limits.Upload(at("23:00")), Equals, int64(0))
+	c.Assert(limits.Upload(at("04:59")), Equals, int64(0))
+	c.Assert(limits.Upload(at("05:00")), Equals, int64(1024*1024))
+	c.Assert(limits.Download(at("11:00")), Equals, int64(0))
+	c.Assert(limits.Download(at("12:30")), Equals, int64(1024))
+
+	_, perr = newBandwidthLimits("", "", []bandwidthSchedule{{From: "25:00", To: "05:00"}})
+	c.Assert(perr, Not(IsNil))
+}
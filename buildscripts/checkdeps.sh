@@ -24,7 +24,7 @@ _init() {
     CLANG_VERSION="7.0.0"
     YASM_VERSION="1.2.0"
     GIT_VERSION="1.0"
-    GO_VERSION="1.5.1"
+    GO_VERSION="1.13"
     OSX_VERSION="10.8"
     UNAME=$(uname -sm)
 
@@ -105,7 +105,7 @@ check_golang_env() {
     if [ $? -eq 1 ]; then
         echo "ERROR"
         echo "GOROOT environment variable missing, please refer to Go installation document"
-        echo "https://github.com/minio/mc/blob/master/INSTALLGO.md#install-go-13"
+        echo "https://github.com/minio/mc/blob/master/INSTALLGO.md#install-go-113"
         exit 1
     fi
 
@@ -113,7 +113,7 @@ check_golang_env() {
     if [ $? -eq 1 ]; then
         echo "ERROR"
         echo "GOPATH environment variable missing, please refer to Go installation document"
-        echo "https://github.com/minio/mc/blob/master/INSTALLGO.md#install-go-13"
+        echo "https://github.com/minio/mc/blob/master/INSTALLGO.md#install-go-113"
         exit 1
     fi
 
@@ -121,7 +121,7 @@ check_golang_env() {
 
     if [ -z "${go_binary_path}" ] ; then
         echo "Cannot find go binary in your PATH configuration, please refer to Go installation document"
-        echo "https://github.com/minio/mc/blob/master/INSTALLGO.md#install-go-13"
+        echo "https://github.com/minio/mc/blob/master/INSTALLGO.md#install-go-113"
         exit -1
     fi
 
@@ -133,7 +133,7 @@ check_golang_env() {
     if [[ !"$(dirname ${new_go_binary_path})" =~ *"${GOROOT%%*(/)}"* ]] ; then
         echo "The go binary found in your PATH configuration does not belong to the Go installation pointed by your GOROOT environment," \
             "please refer to Go installation document"
-        echo "https://github.com/minio/mc/blob/master/INSTALLGO.md#install-go-13"
+        echo "https://github.com/minio/mc/blob/master/INSTALLGO.md#install-go-113"
         exit -1
     fi
 }
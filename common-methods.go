@@ -20,33 +20,21 @@ import (
 	"io"
 	"os"
 	"runtime"
-	"strings"
-	"sync"
+	"time"
 
 	"github.com/minio/mc/pkg/client"
-	"github.com/minio/mc/pkg/client/fs"
-	"github.com/minio/mc/pkg/client/s3v2"
-	"github.com/minio/mc/pkg/client/s3v4"
+	"github.com/minio/mc/pkg/transfer"
 	"github.com/minio/minio-xl/pkg/probe"
 )
 
+// transferOptions - options for the transfer package, resolving URLs through config and stat cache.
+func transferOptions() transfer.Options {
+	return transfer.Options{NewClient: url2Client, Stat: url2Stat}
+}
+
 // Check if the target URL represents folder. It may or may not exist yet.
 func isTargetURLDir(targetURL string) bool {
-	targetURLParse := client.NewURL(targetURL)
-	_, targetContent, perr := url2Stat(targetURL)
-	if perr != nil {
-		if targetURLParse.Path == string(targetURLParse.Separator) && targetURLParse.Scheme != "" {
-			return false
-		}
-		if strings.HasSuffix(targetURLParse.Path, string(targetURLParse.Separator)) {
-			return true
-		}
-		return false
-	}
-	if !targetContent.Type.IsDir() { // Target is a dir.
-		return false
-	}
-	return true
+	return transfer.IsTargetURLDir(globalContext, transferOptions(), targetURL)
 }
 
 // getSource gets a reader from URL
@@ -55,7 +43,7 @@ func getSource(sourceURL string) (reader io.ReadCloser, length int64, err *probe
 	if err != nil {
 		return nil, 0, err.Trace()
 	}
-	return sourceClnt.Get(0, 0)
+	return sourceClnt.Get(globalContext, 0, 0)
 }
 
 // putTarget writes to URL from reader. If length=0, read until EOF.
@@ -64,7 +52,7 @@ func putTarget(targetURL string, length int64, reader io.Reader) *probe.Error {
 	if err != nil {
 		return err.Trace(targetURL)
 	}
-	err = targetClnt.Put(length, reader)
+	err = targetClnt.Put(globalContext, length, reader)
 	if err != nil {
 		return err.Trace(targetURL)
 	}
@@ -73,121 +61,110 @@ func putTarget(targetURL string, length int64, reader io.Reader) *probe.Error {
 
 // putTargets writes to URL from reader. If length=0, read until EOF.
 func putTargets(targetURLs []string, length int64, reader io.Reader) *probe.Error {
-	var tgtReaders []*io.PipeReader
-	var tgtWriters []*io.PipeWriter
-	var tgtClients []client.Client
-	errCh := make(chan *probe.Error)
-	defer close(errCh)
+	return transfer.PutTargets(globalContext, transferOptions(), targetURLs, length, reader)
+}
 
-	for _, targetURL := range targetURLs {
-		tgtClient, err := url2Client(targetURL)
-		if err != nil {
-			return err.Trace(targetURL)
-		}
-		tgtClients = append(tgtClients, tgtClient)
-		tgtReader, tgtWriter := io.Pipe()
-		tgtReaders = append(tgtReaders, tgtReader)
-		tgtWriters = append(tgtWriters, tgtWriter)
+// getNewClient gives a new client interface, from the backend named by host config or else by URL scheme.
+func getNewClient(urlStr string, auth hostConfig) (client.Client, *probe.Error) {
+	url := client.NewURL(urlStr)
+	backend, ok := client.LookupBackend(url, auth.API)
+	if !ok {
+		return nil, errInitClient(urlStr).Trace()
 	}
-
-	go func() {
-		var writers []io.Writer
-		for _, tgtWriter := range tgtWriters {
-			writers = append(writers, io.Writer(tgtWriter))
+	config := new(client.Config)
+	config.AccessKeyID = func() string {
+		if auth.AccessKeyID == globalAccessKeyID {
+			return ""
 		}
-
-		multiTgtWriter := io.MultiWriter(writers...)
-		var e error
-		switch length {
-		case 0:
-			_, e = io.Copy(multiTgtWriter, reader)
-		default:
-			_, e = io.CopyN(multiTgtWriter, reader, length)
+		return auth.AccessKeyID
+	}()
+	config.SecretAccessKey = func() string {
+		if auth.SecretAccessKey == globalSecretAccessKey {
+			return ""
 		}
-		for _, tgtWriter := range tgtWriters {
-			if e != nil {
-				tgtWriter.CloseWithError(e)
+		return auth.SecretAccessKey
+	}()
+	config.SessionToken = auth.SessionToken
+	if auth.rotating {
+		config.Refresh = func() (client.Credentials, error) {
+			auth, err := getHostConfig(urlStr)
+			if err != nil {
+				return client.Credentials{}, err.ToGoError()
 			}
-			tgtWriter.Close()
+			return client.Credentials{
+				AccessKeyID:     auth.AccessKeyID,
+				SecretAccessKey: auth.SecretAccessKey,
+				SessionToken:    auth.SessionToken,
+				Expiration:      auth.expiration,
+			}, nil
 		}
-	}()
-
-	var wg sync.WaitGroup
-	errorCh := make(chan *probe.Error, len(tgtClients))
-
-	func() { // Parallel putObject
-		defer close(errorCh) // Each routine gets to return one err status.
-		for i := range tgtClients {
-			wg.Add(1)
-			// make local copy for go routine
-			tgtClient := tgtClients[i]
-			tgtReader := tgtReaders[i]
-
-			go func(targetClient client.Client, reader io.ReadCloser, errorCh chan<- *probe.Error) {
-				defer wg.Done()
-				defer reader.Close()
-				err := targetClient.Put(length, reader)
-				if err != nil {
-					errorCh <- err.Trace()
-					return
-				}
-			}(tgtClient, tgtReader, errorCh)
+	}
+	config.AppName = "Minio"
+	config.AppVersion = globalMCVersion
+	config.AppComments = []string{os.Args[0], runtime.GOOS, runtime.GOARCH}
+	config.HostURL = urlStr
+	config.Debug = globalDebugFlag
+	config.Record = globalRecorder
+	config.Replay = globalReplayer
+	config.Faults = globalFaults
+	config.HAR = globalHAR
+	config.RequestsPerSecond = auth.RequestsPerSecond
+	for _, timeout := range []struct {
+		value string
+		to    *time.Duration
+	}{
+		{auth.Timeout, &config.Timeout},
+		{auth.ConnectTimeout, &config.Transport.ConnectTimeout},
+		{auth.ReadTimeout, &config.Transport.ReadTimeout},
+	} {
+		if timeout.value == "" {
+			continue
 		}
-		wg.Wait()
-	}()
-
-	// Return on first error encounter.
-	err := <-errorCh
-	if err != nil {
-		return err.Trace()
+		duration, e := time.ParseDuration(timeout.value)
+		if e != nil || duration < 0 {
+			return nil, errInvalidTimeout(timeout.value).Trace(urlStr)
+		}
+		*timeout.to = duration
 	}
-
-	return nil // success.
-}
-
-// getNewClient gives a new client interface
-func getNewClient(urlStr string, auth hostConfig) (client.Client, *probe.Error) {
-	url := client.NewURL(urlStr)
-	switch url.Type {
-	case client.Object: // Minio and S3 compatible cloud storage
-		s3Config := new(client.Config)
-		s3Config.AccessKeyID = func() string {
-			if auth.AccessKeyID == globalAccessKeyID {
-				return ""
-			}
-			return auth.AccessKeyID
-		}()
-		s3Config.SecretAccessKey = func() string {
-			if auth.SecretAccessKey == globalSecretAccessKey {
-				return ""
-			}
-			return auth.SecretAccessKey
-		}()
-		s3Config.AppName = "Minio"
-		s3Config.AppVersion = globalMCVersion
-		s3Config.AppComments = []string{os.Args[0], runtime.GOOS, runtime.GOARCH}
-		s3Config.HostURL = urlStr
-		s3Config.Debug = globalDebugFlag
-
-		var s3Client client.Client
-		var err *probe.Error
-		if auth.API == "S3v2" {
-			s3Client, err = s3v2.New(s3Config)
-		} else {
-			s3Client, err = s3v4.New(s3Config)
+	if !client.IsValidLookup(auth.Lookup) {
+		return nil, errInvalidLookup(auth.Lookup).Trace(urlStr)
+	}
+	config.Lookup = auth.Lookup
+	switch auth.ClockOffset {
+	case "":
+	case clockOffsetAuto:
+		config.AutoClockOffset = true
+	default:
+		offset, e := time.ParseDuration(auth.ClockOffset)
+		if e != nil {
+			return nil, errInvalidClockOffset(auth.ClockOffset).Trace(urlStr)
 		}
-		if err != nil {
-			return nil, err.Trace()
+		config.ClockOffset = offset
+	}
+	config.Region = auth.Region
+	config.Transport.CAFile = auth.CABundle
+	config.Transport.CertFile = auth.ClientCert
+	config.Transport.KeyFile = auth.ClientKey
+	config.Transport.Insecure = auth.Insecure
+	config.Transport.Proxy = auth.Proxy
+	config.Transport.MaxIdleConns = auth.MaxIdleConns
+	newClient, err := backend.New(config)
+	if err != nil {
+		return nil, err.Trace()
+	}
+	if auth.FallbackAPI != "" && auth.FallbackAPI != backend.API {
+		if !client.IsBackendAPI(auth.FallbackAPI) {
+			return nil, errInvalidFallbackAPI(auth.FallbackAPI).Trace(urlStr)
 		}
-		return s3Client, nil
-	case client.Filesystem:
-		fsClient, err := fs.New(urlStr)
+		fallbackBackend, _ := client.LookupBackend(url, auth.FallbackAPI)
+		fallbackConfig := *config
+		fallbackClient, err := fallbackBackend.New(&fallbackConfig)
 		if err != nil {
 			return nil, err.Trace()
 		}
-		return fsClient, nil
+		newClient = client.NewFallbackClient(newClient, fallbackClient)
 	}
-	return nil, errInitClient(urlStr).Trace()
+	return newClient, nil
 }
 
 func url2Client(url string) (client.Client, *probe.Error) {
@@ -79,3 +79,28 @@ func (s *TestSuite) TestCommonMethods(c *C) {
 	_, perr = url2Client("http://test.minio.io" + "/bucket/fail")
 	c.Assert(perr, Not(IsNil))
 }
+
+func (s *TestSuite) TestStatCache(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	objectPath := filepath.Join(root, "object1")
+	perr := putTarget(objectPath, 5, bytes.NewReader([]byte("hello")))
+	c.Assert(perr, IsNil)
+
+	startStatCache()
+	_, content, perr := url2Stat(objectPath)
+	c.Assert(perr, IsNil)
+	// Modifying returned content must not affect the cache.
+	content.Name = "modified"
+
+	c.Assert(os.Remove(objectPath), IsNil)
+	_, content, perr = url2Stat(objectPath)
+	c.Assert(perr, IsNil)
+	c.Assert(content.Name, Not(Equals), "modified")
+	stopStatCache()
+
+	_, _, perr = url2Stat(objectPath)
+	c.Assert(perr, Not(IsNil))
+}
@@ -163,7 +163,7 @@ func listAliases() {
 	fatalIf(err.Trace(configPath), "Unable to load config path")
 
 	// convert interface{} back to its original struct
-	newConf := config.Data().(*configV5)
+	newConf := config.Data().(*configV7)
 	for k, v := range newConf.Aliases {
 		Prints("%s\n", AliasMessage{
 			op:    "list",
@@ -178,18 +178,14 @@ func removeAlias(alias string) {
 	if strings.TrimSpace(alias) == "" {
 		fatalIf(errDummy().Trace(), "Alias or URL cannot be empty.")
 	}
-	config, err := newConfig()
-	fatalIf(err.Trace(), "Failed to initialize ‘quick’ configuration data structure.")
-
-	configPath := mustGetMcConfigPath()
-	err = config.Load(configPath)
-	fatalIf(err.Trace(configPath), "Unable to load config path")
+	config, err := loadConfig()
+	fatalIf(err.Trace(), "Unable to load config path")
 	if !isValidAliasName(alias) {
 		fatalIf(errDummy().Trace(), fmt.Sprintf("Alias name ‘%s’ is invalid, valid examples are: mybucket, Area51, Grand-Nagus", alias))
 	}
 
 	// convert interface{} back to its original struct
-	newConf := config.Data().(*configV5)
+	newConf := config.Data().(*configV7)
 	if _, ok := newConf.Aliases[alias]; !ok {
 		fatalIf(errDummy().Trace(), fmt.Sprintf("Alias ‘%s’ does not exist.", alias))
 	}
@@ -211,10 +207,7 @@ func addAlias(alias, url string) {
 	if alias == "" || url == "" {
 		fatalIf(errDummy().Trace(), "Alias or URL cannot be empty.")
 	}
-	config, err := newConfig()
-	fatalIf(err.Trace(globalMCConfigVersion), "Failed to initialize ‘quick’ configuration data structure.")
-
-	err = config.Load(mustGetMcConfigPath())
+	config, err := loadConfig()
 	fatalIf(err.Trace(), "Unable to load config path")
 
 	url = strings.TrimSuffix(url, "/")
@@ -225,7 +218,7 @@ func addAlias(alias, url string) {
 		fatalIf(errDummy().Trace(), fmt.Sprintf("Alias name ‘%s’ is invalid, valid examples are: mybucket, Area51, Grand-Nagus", alias))
 	}
 	// convert interface{} back to its original struct
-	newConf := config.Data().(*configV5)
+	newConf := config.Data().(*configV7)
 	if oldURL, ok := newConf.Aliases[alias]; ok {
 		fatalIf(errDummy().Trace(), fmt.Sprintf("Alias ‘%s’ already exists for ‘%s’.", alias, oldURL))
 	}
@@ -1,13 +1,21 @@
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"os"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/minio-xl/pkg/probe"
 	"github.com/minio/minio-xl/pkg/quick"
@@ -17,29 +25,51 @@ var configHostCmd = cli.Command{
 	Name:   "host",
 	Usage:  "List, modify and remove hosts in configuration file.",
 	Action: mainConfigHost,
+	Flags:  []cli.Flag{showSecretsFlag, noCheckFlag},
 	CustomHelpTemplate: `NAME:
    mc config {{.Name}} - {{.Usage}}
 
 USAGE:
-   mc config {{.Name}} OPERATION [ARGS...]
+   mc config {{.Name}} [FLAGS] OPERATION [ARGS...]
 
    OPERATION = add | list | remove
 
+   Hosts are checked by listing their buckets before they are added, and their API is detected unless given.
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
 EXAMPLES:
-   1. Add host configuration for a URL, using default signature V4. For security reasons turn off bash history
+   1. Add host configuration interactively, prompting for its endpoint, access keys and API. Keys are not echoed.
+      $ mc config {{.Name}} add
+
+   2. Add host configuration for a URL, using signature V4 unless the host only accepts V2. For security reasons turn off bash history
       $ set +o history
       $ mc config {{.Name}} add s3.amazonaws.com BKIKJAA5BMMU2RHO6IBB V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12
       $ set -o history
 
-   2. Add host configuration for a URL, using s3 api v2. For security reasons turn off bash history
+   3. Add host configuration for a URL, using s3 api v2. For security reasons turn off bash history
       $ set +o history
       $ mc config {{.Name}} add s3.amazonaws.com BKIKJAA5BMMU2RHO6IBB V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12 S3v2
       $ set -o history
 
-   3. List all hosts.
+   4. Add host configuration for a host which cannot be reached yet, without checking its access keys.
+      $ set +o history
+      $ mc config {{.Name}} --no-check add https://minio.internal:9000 BKIKJAA5BMMU2RHO6IBB V7f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12 S3v4
+      $ set -o history
+
+   5. Add host configuration for buckets starting with ‘finance-’, it takes precedence over the host wide one. The most specific host matching a URL is used.
+      $ set +o history
+      $ mc config {{.Name}} add s3.amazonaws.com/finance-* AKIAJ5BMMU2RHOSEXAMPL W9f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSr5xy
+      $ set -o history
+
+   6. List all hosts, secret access keys are masked.
       $ mc config {{.Name}} list
 
-   4. Remove host config.
+   7. List all hosts with their secret access keys.
+      $ mc config {{.Name}} --show-secrets list
+
+   8. Remove host config.
       $ mc config {{.Name}} remove s3.amazonaws.com
 
 `,
@@ -69,7 +99,7 @@ func (a HostMessage) String() string {
 		return console.Colorize("HostMessage", "Removed host ‘"+a.Host+"’ successfully.")
 	}
 	if a.op == "add" {
-		return console.Colorize("HostMessage", "Added host ‘"+a.Host+"’ successfully.")
+		return console.Colorize("HostMessage", "Added host ‘"+a.Host+"’ with API ‘"+a.API+"’ successfully.")
 	}
 	// should never reach here
 	return ""
@@ -96,6 +126,10 @@ func checkConfigHostSyntax(ctx *cli.Context) {
 	}
 	switch strings.TrimSpace(ctx.Args().First()) {
 	case "add":
+		if len(ctx.Args().Tail()) == 0 && isatty.IsTerminal(os.Stdin.Fd()) {
+			// Prompted for interactively.
+			return
+		}
 		if len(ctx.Args().Tail()) < 3 || len(ctx.Args().Tail()) > 4 {
 			fatalIf(errInvalidArgument().Trace(), "Incorrect number of arguments for add host command.")
 		}
@@ -144,30 +178,36 @@ func mainConfigHost(ctx *cli.Context) {
 
 	switch strings.TrimSpace(arg) {
 	case "add":
-		addHost(tailArgs.Get(0), tailArgs.Get(1), tailArgs.Get(2), tailArgs.Get(3))
+		if len(tailArgs) == 0 {
+			hostGlob, accessKeyID, secretAccessKey, api := readHost()
+			addHost(hostGlob, accessKeyID, secretAccessKey, api, ctx.Bool("no-check"))
+			return
+		}
+		addHost(tailArgs.Get(0), tailArgs.Get(1), tailArgs.Get(2), tailArgs.Get(3), ctx.Bool("no-check"))
 	case "remove":
 		removeHost(tailArgs.Get(0))
 	case "list":
-		listHosts()
+		listHosts(ctx.Bool("show-secrets"))
 	}
 }
 
-func listHosts() {
-	config, err := newConfig()
-	fatalIf(err.Trace(), "Failed to initialize ‘quick’ configuration data structure.")
-
-	configPath := mustGetMcConfigPath()
-	err = config.Load(configPath)
-	fatalIf(err.Trace(configPath), "Unable to load config path")
+// listHosts lists hosts, masking their secret access keys unless showSecrets.
+func listHosts(showSecrets bool) {
+	config, err := loadConfig()
+	fatalIf(err.Trace(), "Unable to load config path")
 
 	// convert interface{} back to its original struct
-	newConf := config.Data().(*configV5)
+	newConf := config.Data().(*configV7)
 	for k, v := range newConf.Hosts {
+		secretAccessKey := v.SecretAccessKey
+		if !showSecrets {
+			secretAccessKey = maskSecret(secretAccessKey)
+		}
 		Prints("%s\n", HostMessage{
 			op:              "list",
 			Host:            k,
 			AccessKeyID:     v.AccessKeyID,
-			SecretAccessKey: v.SecretAccessKey,
+			SecretAccessKey: secretAccessKey,
 			API:             v.API,
 		})
 	}
@@ -181,15 +221,11 @@ func removeHost(hostGlob string) {
 	if strings.TrimSpace(hostGlob) == "dl.minio.io:9000" {
 		fatalIf(errDummy().Trace(), "‘"+hostGlob+"’ is reserved hostname and cannot be removed.")
 	}
-	config, err := newConfig()
-	fatalIf(err.Trace(globalMCConfigVersion), "Failed to initialize ‘quick’ configuration data structure.")
-
-	configPath := mustGetMcConfigPath()
-	err = config.Load(configPath)
-	fatalIf(err.Trace(configPath), "Unable to load config path")
+	config, err := loadConfig()
+	fatalIf(err.Trace(), "Unable to load config path")
 
 	// convert interface{} back to its original struct
-	newConf := config.Data().(*configV5)
+	newConf := config.Data().(*configV7)
 	if _, ok := newConf.Hosts[hostGlob]; !ok {
 		fatalIf(errDummy().Trace(), fmt.Sprintf("Host glob ‘%s’ does not exist.", hostGlob))
 	}
@@ -224,23 +260,34 @@ func isValidAccessKey(accessKeyID string) bool {
 	return regex.MatchString(accessKeyID)
 }
 
-// addHost - add new host
-func addHost(hostGlob, accessKeyID, secretAccessKey, api string) {
+// readHost prompts on the terminal for the endpoint, access keys and API of a host to add. Keys
+// are read without echo.
+func readHost() (hostGlob, accessKeyID, secretAccessKey, api string) {
+	endpoint, err := readLine("Endpoint, such as ‘https://s3.amazonaws.com’: ")
+	fatalIf(err.Trace(), "Unable to read endpoint.")
+	accessKeyID, err = readSecret("Access key ID: ")
+	fatalIf(err.Trace(), "Unable to read access key ID.")
+	secretAccessKey, err = readSecret("Secret access key: ")
+	fatalIf(err.Trace(), "Unable to read secret access key.")
+	api, err = readLine("API, ‘" + strings.Join(probeAPIs, "’ or ‘") + "’, empty to detect it: ")
+	fatalIf(err.Trace(), "Unable to read API.")
+	return strings.TrimSuffix(endpoint, "/"), strings.TrimSpace(accessKeyID), strings.TrimSpace(secretAccessKey), api
+}
+
+// addHost - add new host, checking its access keys and detecting its API unless noCheck.
+func addHost(hostGlob, accessKeyID, secretAccessKey, api string, noCheck bool) {
 	if strings.TrimSpace(hostGlob) == "" {
 		fatalIf(errDummy().Trace(), "Unable to proceed, empty arguments provided.")
 	}
-	if strings.TrimSpace(api) == "" {
-		api = "S3v4"
+	if !parseHostPattern(hostGlob).isValid() {
+		fatalIf(errInvalidArgument().Trace(), "Invalid host ‘"+hostGlob+"’, valid examples are: s3.amazonaws.com, *.example.com:9000, https://s3.amazonaws.com/finance-*")
 	}
-	if strings.TrimSpace(api) != "S3v2" && strings.TrimSpace(api) != "S3v4" {
-		fatalIf(errInvalidArgument().Trace(), "Unrecognized version name provided, supported inputs are ‘S3v4’, ‘S3v2’")
+	api = strings.TrimSpace(api)
+	if api != "" && !client.IsBackendAPI(api) {
+		fatalIf(errInvalidArgument().Trace(), "Unrecognized API name provided, supported inputs are ‘"+strings.Join(client.BackendAPIs(), "’, ‘")+"’")
 	}
-	config, err := newConfig()
-	fatalIf(err.Trace(globalMCConfigVersion), "Failed to initialize ‘quick’ configuration data structure.")
-
-	configPath := mustGetMcConfigPath()
-	err = config.Load(configPath)
-	fatalIf(err.Trace(configPath), "Unable to load config path")
+	config, err := loadConfig()
+	fatalIf(err.Trace(), "Unable to load config path")
 
 	if len(accessKeyID) != 0 {
 		if !isValidAccessKey(accessKeyID) {
@@ -253,12 +300,20 @@ func addHost(hostGlob, accessKeyID, secretAccessKey, api string) {
 		}
 	}
 	// convert interface{} back to its original struct
-	newConf := config.Data().(*configV5)
-	newConf.Hosts[hostGlob] = hostConfig{
-		AccessKeyID:     accessKeyID,
-		SecretAccessKey: secretAccessKey,
-		API:             api,
+	newConf := config.Data().(*configV7)
+	// Keep settings which are not managed by ‘add’, such as requestsPerSecond.
+	hostCfg := newConf.Hosts[hostGlob]
+	hostCfg.AccessKeyID = accessKeyID
+	hostCfg.SecretAccessKey = secretAccessKey
+	if !noCheck {
+		api, err = checkHost(hostGlob, hostCfg, api)
+		fatalIf(err.Trace(hostGlob), diagnoseHost(hostGlob, err)+" Add it with ‘--no-check’ to skip checking.")
 	}
+	if api == "" {
+		api = probeAPIs[0]
+	}
+	hostCfg.API = api
+	newConf.Hosts[hostGlob] = hostCfg
 	newConfig, err := quick.New(newConf)
 	fatalIf(err.Trace(globalMCConfigVersion), "Failed to initialize ‘quick’ configuration data structure.")
 
@@ -269,7 +324,116 @@ func addHost(hostGlob, accessKeyID, secretAccessKey, api string) {
 		op:              "add",
 		Host:            hostGlob,
 		AccessKeyID:     accessKeyID,
-		SecretAccessKey: secretAccessKey,
+		SecretAccessKey: maskSecret(secretAccessKey),
 		API:             api,
 	})
 }
+
+// probeAPIs are signature versions checkHost tries, newest first.
+var probeAPIs = []string{"S3v4", "S3v2"}
+
+// checkHost lists the buckets, or the bucket, of hostGlob with api, or else with each of
+// probeAPIs, and returns the first API whose requests the host accepts. Anonymous hosts and
+// hosts with wildcards are not checked.
+func checkHost(hostGlob string, hostCfg hostConfig, api string) (string, *probe.Error) {
+	p := parseHostPattern(hostGlob)
+	if hostCfg.AccessKeyID == "" {
+		return api, nil
+	}
+	// Brackets of IPv6 addresses are no character class.
+	for _, glob := range []string{strings.TrimSuffix(strings.TrimPrefix(p.host, "["), "]"), p.port, p.bucket} {
+		if strings.ContainsAny(glob, "*?[\\") {
+			return api, nil
+		}
+	}
+	apis := probeAPIs
+	if api != "" {
+		apis = []string{api}
+	}
+	hostPort := p.host
+	if p.port != "" {
+		hostPort += ":" + p.port
+	}
+	urlStr := hostScheme(p.scheme, hostPort) + "://" + hostPort + "/" + p.bucket
+	return checkHostURL(urlStr, hostCfg, apis)
+}
+
+// hostScheme returns the scheme hosts are checked with: that of their pattern, that of an
+// alias of the host, or else ‘https’. Hosts which do not speak TLS are not retried with
+// ‘http’, keys are sent in the clear only if the user asks for it.
+func hostScheme(scheme, hostPort string) string {
+	if scheme != "" {
+		return scheme
+	}
+	if config, err := getMcConfig(); err == nil {
+		names := make([]string, 0, len(config.Aliases))
+		for name := range config.Aliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			u := client.NewURL(config.Aliases[name])
+			if u.Type == client.Object && u.Host == hostPort {
+				return u.Scheme
+			}
+		}
+	}
+	return "https"
+}
+
+// checkHostURL lists urlStr with each of apis, and returns the first API whose requests the
+// host accepts.
+func checkHostURL(urlStr string, hostCfg hostConfig, apis []string) (string, *probe.Error) {
+	var err *probe.Error
+	for _, api := range apis {
+		hostCfg.API = api
+		clnt, perr := getNewClient(urlStr, hostCfg)
+		if perr != nil {
+			return api, perr.Trace(urlStr)
+		}
+		content := <-clnt.List(globalContext, false, false)
+		if content.Err == nil {
+			return api, nil
+		}
+		err = content.Err.Trace(urlStr, api)
+		switch content.Err.ToGoError().(type) {
+		case client.SignatureRejected:
+			continue
+		case client.AccessDenied:
+			// Valid keys, though not allowed to list buckets.
+			return api, nil
+		}
+		return api, err
+	}
+	return apis[0], err
+}
+
+// diagnoseHost tells what went wrong when err failed checking host, for the most common causes.
+func diagnoseHost(host string, err *probe.Error) string {
+	if err == nil {
+		return ""
+	}
+	e := err.ToGoError()
+	var dnsError *net.DNSError
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalidHostname x509.HostnameError
+	var invalidCertificate x509.CertificateInvalidError
+	var noTLS tls.RecordHeaderError
+	switch {
+	case errors.As(e, &dnsError):
+		return "Unable to resolve host ‘" + host + "’, please check its name."
+	case errors.As(e, &unknownAuthority), errors.As(e, &invalidHostname), errors.As(e, &invalidCertificate):
+		return "Unable to verify the certificate of host ‘" + host + "’, please check its ‘caBundle’."
+	case errors.As(e, &noTLS):
+		return "Host ‘" + host + "’ does not speak TLS. If it serves plain HTTP, add it as ‘http://" + strings.TrimPrefix(host, "https://") + "’ to send requests unencrypted."
+	}
+	switch e.(type) {
+	case client.SignatureRejected, client.AccessKeyRejected:
+		return "Host ‘" + host + "’ rejected the access keys."
+	case client.ClockSkewed:
+		return "Host ‘" + host + "’ rejected the time of the request."
+	case client.OperationTimeout, net.Error:
+		return "Unable to connect to host ‘" + host + "’, please check its address and port."
+	}
+	return "Unable to check the access keys of host ‘" + host + "’."
+}
@@ -35,6 +35,7 @@ var configCmd = cli.Command{
 	Subcommands: []cli.Command{
 		configAliasCmd,
 		configHostCmd,
+		configSecretsCmd,
 		configVersionCmd,
 	},
 	CustomHelpTemplate: `NAME:
@@ -0,0 +1,135 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/secrets"
+	"github.com/minio/minio-xl/pkg/probe"
+	"github.com/minio/minio-xl/pkg/quick"
+)
+
+// Choose where secrets are stored.
+var configSecretsCmd = cli.Command{
+	Name:   "secrets",
+	Usage:  "Choose where secret access keys of hosts are stored.",
+	Action: mainConfigSecrets,
+	CustomHelpTemplate: `NAME:
+   mc config {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc config {{.Name}} [STORE]
+
+   STORE = plain | passphrase | keyring | file
+
+EXAMPLES:
+   1. Show where secrets are stored.
+      $ mc config {{.Name}}
+
+   2. Encrypt secrets in config file with a passphrase, or change the passphrase. Set MC_CONFIG_PASSPHRASE for non interactive use.
+      $ mc config {{.Name}} passphrase
+
+   3. Move secrets to the keyring of the operating system, or to a file readable only by you where there is none.
+      $ mc config {{.Name}} keyring
+
+   4. Keep secrets in plain text in config file again.
+      $ mc config {{.Name}} plain
+
+`,
+}
+
+// SecretsMessage container for secrets store message
+type SecretsMessage struct {
+	op    string
+	Store string `json:"store"`
+}
+
+// String secrets store message
+func (s SecretsMessage) String() string {
+	if s.op == "set" {
+		return "Secrets are now stored in ‘" + s.Store + "’ store."
+	}
+	return s.Store
+}
+
+// JSON jsonified secrets store message
+func (s SecretsMessage) JSON() string {
+	jsonMessageBytes, e := json.Marshal(s)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(jsonMessageBytes)
+}
+
+func mainConfigSecrets(ctx *cli.Context) {
+	if ctx.Args().First() == "help" || len(ctx.Args()) > 1 {
+		cli.ShowCommandHelpAndExit(ctx, "secrets", 1) // last argument is exit code
+	}
+	store := strings.TrimSpace(ctx.Args().First())
+
+	config, err := loadConfig()
+	fatalIf(err.Trace(), "Unable to load config path")
+
+	// convert interface{} back to its original struct
+	newConf := config.Data().(*configV7)
+	oldSecrets := newConf.Secrets
+	if store == "" {
+		store = oldSecrets.Store
+		if store == "" {
+			store = secretsStorePlain
+		}
+		Prints("%s\n", SecretsMessage{op: "show", Store: store})
+		return
+	}
+
+	switch store {
+	case secretsStorePlain:
+		newConf.Secrets = secretsConfig{}
+	case secretsStorePassphrase:
+		// Read a new passphrase, unless given by environment.
+		configPassphrase = ""
+		newConf.Secrets = secretsConfig{Store: store}
+	case secretsStoreKeyring:
+		if !secrets.KeyringAvailable() {
+			if runtime.GOOS != "linux" {
+				fatalIf(probe.NewError(secrets.ErrKeyringUnavailable), "Unable to use keyring, please choose ‘"+secretsStorePassphrase+"’ store instead.")
+			}
+			console.Infoln("No keyring available, falling back to ‘" + secretsStoreFile + "’ store.")
+			store = secretsStoreFile
+		}
+		newConf.Secrets = secretsConfig{Store: store}
+	case secretsStoreFile:
+		newConf.Secrets = secretsConfig{Store: store}
+	default:
+		fatalIf(errInvalidSecretsStore(store).Trace(), "Unable to change secrets store.")
+	}
+
+	newConfig, err := quick.New(newConf)
+	fatalIf(err.Trace(globalMCConfigVersion), "Failed to initialize ‘quick’ configuration data structure.")
+	err = writeConfig(newConfig)
+	fatalIf(err.Trace(store), "Unable to save secrets to ‘"+store+"’ store.")
+
+	if oldSecrets.Store != newConf.Secrets.Store {
+		err = clearSecrets(oldSecrets)
+		fatalIf(err.Trace(oldSecrets.Store), "Unable to remove secrets from ‘"+oldSecrets.Store+"’ store.")
+	}
+	Prints("%s\n", SecretsMessage{op: "set", Store: store})
+}
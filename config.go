@@ -28,6 +28,25 @@ import (
 	"github.com/minio/minio-xl/pkg/quick"
 )
 
+type configV7 struct {
+	Version string                `json:"version"`
+	Aliases map[string]string     `json:"alias"`
+	Hosts   map[string]hostConfig `json:"hosts"`
+	// Secrets tells where secrets of hosts are stored, see secrets.go.
+	Secrets secretsConfig `json:"secrets"`
+	// Parallel is the default number of transfers for cp and mirror, or ‘auto’.
+	Parallel string `json:"parallel,omitempty"`
+	// Bandwidth limits cp and mirror, ‘--limit-upload’ and ‘--limit-download’ override it.
+	Bandwidth *bandwidthConfig `json:"bandwidth,omitempty"`
+}
+
+type configV6 struct {
+	Version string                `json:"version"`
+	Aliases map[string]string     `json:"alias"`
+	Hosts   map[string]hostConfig `json:"hosts"`
+	Secrets secretsConfig         `json:"secrets"`
+}
+
 type configV5 struct {
 	Version string                `json:"version"`
 	Aliases map[string]string     `json:"alias"`
@@ -71,7 +90,12 @@ type configV101 configV2
 type configV1 configV2
 
 // cached variables should *NEVER* be accessed directly from outside this file.
-var cache sync.Pool
+var cache = struct {
+	sync.Mutex
+	config *configV7
+	// secretsLoaded is set once secrets of hosts are filled into config.
+	secretsLoaded bool
+}{}
 
 // customConfigDir contains the whole path to config dir. Only access via get/set functions.
 var mcCustomConfigDir string
@@ -79,6 +103,11 @@ var mcCustomConfigDir string
 // setMcConfigDir - construct minio client config folder.
 func setMcConfigDir(configDir string) {
 	mcCustomConfigDir = configDir
+	// Config cached from any previous folder is stale.
+	cache.Lock()
+	cache.config = nil
+	cache.secretsLoaded = false
+	cache.Unlock()
 }
 
 // getMcConfigDir - construct minio client config folder.
@@ -136,8 +165,9 @@ func mustGetMcConfigPath() string {
 	return path
 }
 
-// getMcConfig - reads configuration file and returns config
-func getMcConfig() (*configV5, *probe.Error) {
+// getMcConfig - reads configuration file and returns config, without secrets of hosts kept in
+// a store, see getMcConfigSecrets
+func getMcConfig() (*configV7, *probe.Error) {
 	if !isMcConfigExists() {
 		return nil, errInvalidArgument().Trace()
 	}
@@ -148,11 +178,13 @@ func getMcConfig() (*configV5, *probe.Error) {
 	}
 
 	// Cached in private global variable.
-	if v := cache.Get(); v != nil { // Use previously cached config.
-		return v.(quick.Config).Data().(*configV5), nil
+	cache.Lock()
+	defer cache.Unlock()
+	if cache.config != nil { // Use previously cached config.
+		return cache.config, nil
 	}
 
-	conf := new(configV5)
+	conf := new(configV7)
 	conf.Version = globalMCConfigVersion
 	qconf, err := quick.New(conf)
 	if err != nil {
@@ -163,12 +195,30 @@ func getMcConfig() (*configV5, *probe.Error) {
 	if err != nil {
 		return nil, err.Trace()
 	}
-	cache.Put(qconf)
-	return qconf.Data().(*configV5), nil
+	cache.config = qconf.Data().(*configV7)
+	return cache.config, nil
+}
+
+// getMcConfigSecrets - same as getMcConfig, with secrets of hosts filled in from their store.
+// They are read on first use, so that commands on local paths never ask for a passphrase.
+func getMcConfigSecrets() (*configV7, *probe.Error) {
+	conf, err := getMcConfig()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	cache.Lock()
+	defer cache.Unlock()
+	if !cache.secretsLoaded {
+		if err = loadSecrets(conf); err != nil {
+			return nil, err.Trace()
+		}
+		cache.secretsLoaded = true
+	}
+	return conf, nil
 }
 
 // mustGetMcConfig - reads configuration file and returns configs, exits on error
-func mustGetMcConfig() *configV5 {
+func mustGetMcConfig() *configV7 {
 	config, err := getMcConfig()
 	fatalIf(err.Trace(), "Unable to read mc configuration.")
 	return config
@@ -186,7 +236,26 @@ func isMcConfigExists() bool {
 	return true
 }
 
-// writeConfig - write configuration file
+// loadConfig - reads configuration file for changes, with secrets of hosts filled in
+func loadConfig() (quick.Config, *probe.Error) {
+	config, err := newConfig()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	configPath, err := getMcConfigPath()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	if err = config.Load(configPath); err != nil {
+		return nil, err.Trace(configPath)
+	}
+	if err = loadSecrets(config.Data().(*configV7)); err != nil {
+		return nil, err.Trace(configPath)
+	}
+	return config, nil
+}
+
+// writeConfig - write configuration file, secrets of hosts go to their store
 func writeConfig(config quick.Config) *probe.Error {
 	if config == nil {
 		return errInvalidArgument().Trace()
@@ -195,6 +264,11 @@ func writeConfig(config quick.Config) *probe.Error {
 	if err != nil {
 		return err.Trace()
 	}
+	if conf, ok := config.Data().(*configV7); ok && conf.Secrets.Store != "" {
+		if config, err = saveSecrets(conf); err != nil {
+			return err.Trace()
+		}
+	}
 	configPath, err := getMcConfigPath()
 	if err != nil {
 		return err.Trace()
@@ -202,6 +276,11 @@ func writeConfig(config quick.Config) *probe.Error {
 	if err := config.Save(configPath); err != nil {
 		return err.Trace()
 	}
+	// Drop cached config, it is re-read on next access.
+	cache.Lock()
+	cache.config = nil
+	cache.secretsLoaded = false
+	cache.Unlock()
 	return nil
 }
 
@@ -216,6 +295,10 @@ func migrateConfig() {
 	migrateConfigV3ToV4()
 	// Migrate config V4 to V5
 	migrateConfigV4ToV5()
+	// Migrate config V5 to V6
+	migrateConfigV5ToV6()
+	// Migrate config V6 to V7
+	migrateConfigV6ToV7()
 }
 
 func fixConfig() {
@@ -390,7 +473,7 @@ func migrateConfigV4ToV5() {
 				API:             "S3v4",
 			}
 		}
-		confV5.Version = globalMCConfigVersion
+		confV5.Version = "5"
 
 		mcNewConfigV5, err := quick.New(confV5)
 		fatalIf(err.Trace(), "Unable to initialize quick config for config version ‘5’.")
@@ -402,6 +485,61 @@ func migrateConfigV4ToV5() {
 	}
 }
 
+// Migrate config version ‘5’ to ‘6’, secrets stay in plain text until a store is chosen
+// with ‘mc config secrets’.
+func migrateConfigV5ToV6() {
+	if !isMcConfigExists() {
+		return
+	}
+	mcConfigV5, err := quick.Load(mustGetMcConfigPath(), newConfigV5())
+	fatalIf(err.Trace(), "Unable to load mc config V5.")
+
+	// update to newer version
+	if mcConfigV5.Version() == "5" {
+		confV5 := mcConfigV5.Data().(*configV5)
+		confV6 := new(configV6)
+		confV6.Aliases = confV5.Aliases
+		confV6.Hosts = confV5.Hosts
+		confV6.Version = "6"
+
+		mcNewConfigV6, err := quick.New(confV6)
+		fatalIf(err.Trace(), "Unable to initialize quick config for config version ‘6’.")
+
+		err = mcNewConfigV6.Save(mustGetMcConfigPath())
+		fatalIf(err.Trace(), "Unable to save config version ‘6’.")
+
+		console.Infof("Successfully migrated %s from version ‘5’ to version ‘6’.\n", mustGetMcConfigPath())
+	}
+}
+
+// Migrate config version ‘6’ to ‘7’, which adds defaults for parallel transfers and
+// bandwidth limits of cp and mirror.
+func migrateConfigV6ToV7() {
+	if !isMcConfigExists() {
+		return
+	}
+	mcConfigV6, err := quick.Load(mustGetMcConfigPath(), newConfigV6())
+	fatalIf(err.Trace(), "Unable to load mc config V6.")
+
+	// update to newer version
+	if mcConfigV6.Version() == "6" {
+		confV6 := mcConfigV6.Data().(*configV6)
+		confV7 := new(configV7)
+		confV7.Aliases = confV6.Aliases
+		confV7.Hosts = confV6.Hosts
+		confV7.Secrets = confV6.Secrets
+		confV7.Version = globalMCConfigVersion
+
+		mcNewConfigV7, err := quick.New(confV7)
+		fatalIf(err.Trace(), "Unable to initialize quick config for config version ‘7’.")
+
+		err = mcNewConfigV7.Save(mustGetMcConfigPath())
+		fatalIf(err.Trace(), "Unable to save config version ‘7’.")
+
+		console.Infof("Successfully migrated %s from version ‘6’ to version ‘7’.\n", mustGetMcConfigPath())
+	}
+}
+
 // Fix config version ‘3’, by removing broken struct tags
 func fixConfigV3() {
 	if !isMcConfigExists() {
@@ -529,6 +667,26 @@ func newConfigV4() *configV4 {
 
 func newConfigV5() *configV5 {
 	conf := new(configV5)
+	conf.Version = "5"
+	// make sure to allocate map's otherwise Golang
+	// exits silently without providing any errors
+	conf.Hosts = make(map[string]hostConfig)
+	conf.Aliases = make(map[string]string)
+	return conf
+}
+
+func newConfigV6() *configV6 {
+	conf := new(configV6)
+	conf.Version = "6"
+	// make sure to allocate map's otherwise Golang
+	// exits silently without providing any errors
+	conf.Hosts = make(map[string]hostConfig)
+	conf.Aliases = make(map[string]string)
+	return conf
+}
+
+func newConfigV7() *configV7 {
+	conf := new(configV7)
 	conf.Version = globalMCConfigVersion
 	// make sure to allocate map's otherwise Golang
 	// exits silently without providing any errors
@@ -585,7 +743,7 @@ func newConfigV5() *configV5 {
 
 // newConfig - get new config interface
 func newConfig() (config quick.Config, err *probe.Error) {
-	config, err = quick.New(newConfigV5())
+	config, err = quick.New(newConfigV7())
 	if err != nil {
 		return nil, err.Trace()
 	}
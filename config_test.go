@@ -17,9 +17,16 @@
 package main
 
 import (
+	"io/ioutil"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/s3fake"
+	"github.com/minio/minio-xl/pkg/quick"
 	. "gopkg.in/check.v1"
 )
 
@@ -88,3 +95,148 @@ func (s *TestSuite) TestConfigHostContext(c *C) {
 	// reset back
 	console.IsExited = false
 }
+
+func (s *TestSuite) TestConfigHostCheck(c *C) {
+	hostCfg, perr := getHostConfig("http://127.0.0.1:9000")
+	c.Assert(perr, IsNil)
+	accessKeyID, secretAccessKey := hostCfg.AccessKeyID, hostCfg.SecretAccessKey
+	clock := time.Time{}
+	server := s3fake.New(s3fake.Config{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey, Now: func() time.Time {
+		if clock.IsZero() {
+			return time.Now()
+		}
+		return clock
+	}})
+	s3 := httptest.NewServer(server)
+	defer s3.Close()
+	tlsS3 := httptest.NewTLSServer(server)
+	defer tlsS3.Close()
+
+	add := func(args ...string) bool {
+		console.IsExited = false
+		err := app.Run(append([]string{os.Args[0], "config", "host"}, args...))
+		c.Assert(err, IsNil)
+		defer func() { console.IsExited = false }()
+		return !console.IsExited
+	}
+	otherSecret := strings.Repeat("x", len(secretAccessKey))
+	otherKey := strings.Repeat("X", len(accessKeyID))
+	c.Assert(add("add", s3.URL, accessKeyID, otherSecret), Equals, false)
+	c.Assert(add("add", s3.URL, otherKey, secretAccessKey, "S3v2"), Equals, false)
+	c.Assert(add("--no-check", "add", s3.URL, accessKeyID, otherSecret), Equals, true)
+	c.Assert(add("add", s3.URL, accessKeyID, secretAccessKey), Equals, true)
+	c.Assert(add("remove", s3.URL), Equals, true)
+
+	// Common failures are told apart.
+	for _, t := range []struct {
+		host      string
+		diagnosis string
+	}{
+		{s3.URL, ".*rejected the time.*"},
+		{tlsS3.URL, ".*certificate.*"},
+		{strings.Replace(s3.URL, "http://", "https://", 1), ".*does not speak TLS.*"},
+		{"http://host.invalid", ".*resolve.*"},
+	} {
+		clock = time.Now().Add(time.Hour)
+		_, perr = checkHost(t.host, hostConfig{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey}, "S3v4")
+		c.Assert(perr, Not(IsNil), Commentf(t.host))
+		c.Assert(diagnoseHost(t.host, perr), Matches, t.diagnosis)
+	}
+
+	// Hosts without scheme are checked with ‘https’, never with ‘http’ behind the user's back.
+	clock = time.Time{}
+	hostPort := strings.TrimPrefix(s3.URL, "http://")
+	_, perr = checkHost(hostPort, hostConfig{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey}, "")
+	c.Assert(perr, Not(IsNil))
+	c.Assert(diagnoseHost(hostPort, perr), Matches, ".*does not speak TLS.*‘http://"+hostPort+"’.*")
+	c.Assert(hostScheme("", "play.minio.io:9000"), Equals, "https") // alias ‘play’
+	c.Assert(hostScheme("", "example.test:9000"), Equals, "https")
+	c.Assert(hostScheme("http", "play.minio.io:9000"), Equals, "http")
+}
+
+func (s *TestSuite) TestConfigSecrets(c *C) {
+	os.Setenv(envConfigPassphrase, "passphrase")
+	defer os.Unsetenv(envConfigPassphrase)
+	defer func() { configPassphrase = "" }()
+	configPath := mustGetMcConfigPath()
+	secretsPath := filepath.Join(filepath.Dir(configPath), secretsFile)
+	secret := "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+
+	for _, store := range []string{secretsStorePassphrase, secretsStoreFile, secretsStorePlain} {
+		console.IsExited = false
+		err := app.Run([]string{os.Args[0], "config", "secrets", store})
+		c.Assert(err, IsNil)
+		c.Assert(console.IsExited, Equals, false)
+
+		data, e := ioutil.ReadFile(configPath)
+		c.Assert(e, IsNil)
+		c.Assert(strings.Contains(string(data), secret), Equals, store == secretsStorePlain, Commentf(store))
+		_, e = os.Stat(secretsPath)
+		c.Assert(e == nil, Equals, store == secretsStoreFile, Commentf(store))
+
+		hostCfg, perr := getHostConfig("http://127.0.0.1:9000")
+		c.Assert(perr, IsNil)
+		c.Assert(hostCfg.SecretAccessKey, Equals, secret)
+
+		if store == secretsStorePassphrase {
+			// Without a passphrase local paths work, hosts needing keys fail.
+			configPassphrase = ""
+			os.Unsetenv(envConfigPassphrase)
+			cache.Lock()
+			cache.config, cache.secretsLoaded = nil, false
+			cache.Unlock()
+			hostCfg, perr = getHostConfig(os.TempDir())
+			c.Assert(perr, IsNil)
+			c.Assert(hostCfg.API, Equals, "fs")
+			_, perr = getHostConfig("http://127.0.0.1:9000")
+			c.Assert(perr, Not(IsNil))
+
+			os.Setenv(envConfigPassphrase, "wrong")
+			_, perr = loadConfig()
+			c.Assert(perr, Not(IsNil))
+			os.Setenv(envConfigPassphrase, "passphrase")
+			configPassphrase = ""
+		}
+	}
+
+	console.IsExited = false
+	err := app.Run([]string{os.Args[0], "config", "secrets", "vault"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, true)
+	console.IsExited = false
+
+	c.Assert(maskSecret(secret), Equals, "****qJlF")
+	c.Assert(maskSecret("short"), Equals, "********")
+	c.Assert(maskSecret(""), Equals, "")
+}
+
+func (s *TestSuite) TestMigrateConfigV5ToV7(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "mc-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	setMcConfigDir(root)
+	defer setMcConfigDir(customConfigDir)
+
+	confV5 := newConfigV5()
+	confV5.Hosts["s3.amazonaws.com"] = hostConfig{AccessKeyID: "access", SecretAccessKey: "secret", API: "S3v2"}
+	confV5.Aliases["s3"] = "https://s3.amazonaws.com"
+	config, err := quick.New(confV5)
+	c.Assert(err, IsNil)
+	c.Assert(config.Save(mustGetMcConfigPath()), IsNil)
+
+	migrateConfigV5ToV6()
+	migrateConfigV6ToV7()
+	confV7, err := getMcConfig()
+	c.Assert(err, IsNil)
+	c.Assert(confV7.Version, Equals, "7")
+	c.Assert(confV7.Hosts["s3.amazonaws.com"], DeepEquals, confV5.Hosts["s3.amazonaws.com"])
+	c.Assert(confV7.Aliases["s3"], Equals, "https://s3.amazonaws.com")
+	c.Assert(confV7.Secrets.Store, Equals, "")
+	c.Assert(confV7.Parallel, Equals, "")
+	c.Assert(confV7.Bandwidth, IsNil)
+
+	// Unset bandwidth limits are left out of the saved file.
+	data, e := ioutil.ReadFile(mustGetMcConfigPath())
+	c.Assert(e, IsNil)
+	c.Assert(strings.Contains(string(data), "bandwidth"), Equals, false)
+}
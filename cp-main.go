@@ -17,20 +17,14 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
-	"io"
-	"math"
-	"net"
 	"os"
-	"runtime"
-	"strings"
-	"sync"
 
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/transfer"
 	"github.com/minio/minio-xl/pkg/probe"
 )
 
@@ -39,12 +33,16 @@ var cpCmd = cli.Command{
 	Name:   "cp",
 	Usage:  "Copy files and folders from many sources to a single destination.",
 	Action: mainCopy,
+	Flags:  []cli.Flag{retryFlag, parallelFlag, limitUploadFlag, limitDownloadFlag},
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
 USAGE:
-   mc {{.Name}} SOURCE [SOURCE...] TARGET
+   mc {{.Name}} [FLAGS] SOURCE [SOURCE...] TARGET
 
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
 EXAMPLES:
    1. Copy list of objects from local file system to Amazon S3 cloud storage.
       $ mc {{.Name}} Music/*.ogg https://s3.amazonaws.com/jukebox/
@@ -63,6 +61,12 @@ EXAMPLES:
 
    6. Copy local folder with space characters to Amazon S3 cloud storage.
       $ mc {{.Name}} 'workdir/documents/May 2014...' s3/miniocloud
+
+   7. Copy a folder of many small files to Amazon S3 cloud storage, adapting number of parallel transfers to throughput.
+      $ mc {{.Name}} --parallel auto backup/thumbnails... s3/miniocloud
+
+   8. Copy a folder to Amazon S3 cloud storage, limiting upload bandwidth to 20MiB/s.
+      $ mc {{.Name}} --limit-upload 20MiB/s backup/2015... s3/miniocloud
 `,
 }
 
@@ -86,234 +90,23 @@ func (c CopyMessage) JSON() string {
 	return string(copyMessageBytes)
 }
 
-// doCopy - Copy a singe file from source to destination
-func doCopy(cpURLs copyURLs, progressReader interface{}, cpQueue <-chan bool, wg *sync.WaitGroup, statusCh chan<- copyURLs) {
-	defer wg.Done() // Notify that this copy routine is done.
-	defer func() {
-		<-cpQueue
-	}()
-
-	if cpURLs.Error != nil {
-		cpURLs.Error.Trace()
-		statusCh <- cpURLs
-		return
-	}
-
-	if !globalQuietFlag && !globalJSONFlag {
-		progressReader.(*barSend).SetCaption(cpURLs.SourceContent.Name + ": ")
-	}
-
-	reader, length, err := getSource(cpURLs.SourceContent.Name)
-	if err != nil {
-		if !globalQuietFlag && !globalJSONFlag {
-			progressReader.(*barSend).ErrorGet(length)
-		}
-		cpURLs.Error = err.Trace()
-		statusCh <- cpURLs
-		return
-	}
-
-	var newReader io.ReadCloser
-	if globalQuietFlag || globalJSONFlag {
-		Prints("%s\n", CopyMessage{
-			Source: cpURLs.SourceContent.Name,
-			Target: cpURLs.TargetContent.Name,
-			Length: cpURLs.SourceContent.Size,
-		})
-		newReader = progressReader.(*accounter).NewProxyReader(reader)
-	} else {
-		// set up progress
-		newReader = progressReader.(*barSend).NewProxyReader(reader)
-	}
-	defer newReader.Close()
-
-	if err := putTarget(cpURLs.TargetContent.Name, length, newReader); err != nil {
-		if !globalQuietFlag && !globalJSONFlag {
-			progressReader.(*barSend).ErrorPut(length)
-		}
-		cpURLs.Error = err.Trace()
-		statusCh <- cpURLs
-		return
-	}
-
-	cpURLs.Error = nil // just for safety
-	statusCh <- cpURLs
-}
-
-// doCopyFake - Perform a fake copy to update the progress bar appropriately.
-func doCopyFake(cURLs copyURLs, progressReader interface{}) {
-	if !globalQuietFlag && !globalJSONFlag {
-		progressReader.(*barSend).Progress(cURLs.SourceContent.Size)
-	}
-}
-
-// doPrepareCopyURLs scans the source URL and prepares a list of objects for copying.
-func doPrepareCopyURLs(session *sessionV2, trapCh <-chan bool) {
+// doCopySession copies the sources of session to its target, resuming the session if it
+// was interrupted.
+func doCopySession(session *sessionV2) {
 	// Separate source and target. 'cp' can take only one target,
 	// but any number of sources, even the recursive URLs mixed in-between.
 	sourceURLs := session.Header.CommandArgs[:len(session.Header.CommandArgs)-1]
 	targetURL := session.Header.CommandArgs[len(session.Header.CommandArgs)-1] // Last one is target
 
-	var totalBytes int64
-	var totalObjects int
-
-	// Create a session data file to store the processed URLs.
-	dataFP := session.NewDataWriter()
-
-	var scanBar scanBarFunc
-	if !globalQuietFlag && !globalJSONFlag { // set up progress bar
-		scanBar = scanBarFactory()
-	}
-
-	URLsCh := prepareCopyURLs(sourceURLs, targetURL)
-	done := false
-
-	for done == false {
-		select {
-		case cpURLs, ok := <-URLsCh:
-			if !ok { // Done with URL prepration
-				done = true
-				break
-			}
-			if cpURLs.Error != nil {
-				// Print in new line and adjust to top so that we don't print over the ongoing scan bar
-				if !globalQuietFlag && !globalJSONFlag {
-					console.Eraseline()
-				}
-				if strings.Contains(cpURLs.Error.ToGoError().Error(), " is a folder.") {
-					errorIf(cpURLs.Error.Trace(), "Folder cannot be copied. Please use ‘...’ suffix.")
-				} else {
-					errorIf(cpURLs.Error.Trace(), "Unable to prepare URL for copying.")
-				}
-				break
-			}
-
-			jsonData, err := json.Marshal(cpURLs)
-			if err != nil {
-				session.Delete()
-				fatalIf(probe.NewError(err), "Unable to prepare URL for copying. Error in JSON marshaling.")
-			}
-			fmt.Fprintln(dataFP, string(jsonData))
-			if !globalQuietFlag && !globalJSONFlag {
-				scanBar(cpURLs.SourceContent.Name)
-			}
-
-			totalBytes += cpURLs.SourceContent.Size
-			totalObjects++
-		case <-trapCh:
-			// Print in new line and adjust to top so that we don't print over the ongoing scan bar
-			if !globalQuietFlag && !globalJSONFlag {
-				console.Eraseline()
-			}
-			session.Delete() // If we are interrupted during the URL scanning, we drop the session.
-			os.Exit(0)
+	doTransferSession(session, "copy", "Copy", func(event transfer.Event) Message {
+		return CopyMessage{
+			Source: event.Source,
+			Target: event.Targets[0],
+			Length: event.Size,
 		}
-	}
-	session.Header.TotalBytes = totalBytes
-	session.Header.TotalObjects = totalObjects
-	session.Save()
-}
-
-func doCopySession(session *sessionV2) {
-	trapCh := signalTrap(os.Interrupt, os.Kill)
-
-	if !session.HasData() {
-		doPrepareCopyURLs(session, trapCh)
-	}
-
-	var progressReader interface{}
-	if !globalQuietFlag && !globalJSONFlag { // set up progress bar
-		progressReader = newProgressBar(session.Header.TotalBytes)
-	} else {
-		progressReader = newAccounter(session.Header.TotalBytes)
-	}
-
-	// Prepare URL scanner from session data file.
-	scanner := bufio.NewScanner(session.NewDataReader())
-	// isCopied returns true if an object has been already copied
-	// or not. This is useful when we resume from a session.
-	isCopied := isCopiedFactory(session.Header.LastCopied)
-
-	wg := new(sync.WaitGroup)
-	// Limit number of copy routines based on available CPU resources.
-	cpQueue := make(chan bool, int(math.Max(float64(runtime.NumCPU())-1, 1)))
-	defer close(cpQueue)
-
-	// Status channel for receiveing copy return status.
-	statusCh := make(chan copyURLs)
-
-	// Go routine to monitor doCopy status and signal traps.
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		for {
-			select {
-			case cpURLs, ok := <-statusCh: // Receive status.
-				if !ok { // We are done here. Top level function has returned.
-					if !globalQuietFlag && !globalJSONFlag {
-						progressReader.(*barSend).Finish()
-					} else {
-						console.Println(console.Colorize("Copy", progressReader.(*accounter).Finish()))
-					}
-					return
-				}
-				if cpURLs.Error == nil {
-					session.Header.LastCopied = cpURLs.SourceContent.Name
-					session.Save()
-				} else {
-					// Print in new line and adjust to top so that we don't print over the ongoing progress bar
-					if !globalQuietFlag && !globalJSONFlag {
-						console.Eraseline()
-					}
-					errorIf(cpURLs.Error.Trace(), fmt.Sprintf("Failed to copy ‘%s’.", cpURLs.SourceContent.Name))
-					// all the cases which are handled where session should be saved are contained in the following
-					// switch case, we shouldn't be saving sessions for all errors since some errors might need to be
-					// reported to user properly.
-					//
-					// All other critical cases should be handled properly gracefully
-					// handle more errors and save the session.
-					switch cpURLs.Error.ToGoError().(type) {
-					case *net.OpError:
-						gracefulSessionSave(session)
-					case net.Error:
-						gracefulSessionSave(session)
-					}
-				}
-			case <-trapCh: // Receive interrupt notification.
-				if !globalQuietFlag && !globalJSONFlag {
-					console.Eraseline()
-				}
-				gracefulSessionSave(session)
-			}
-		}
-	}()
-
-	// Go routine to perform concurrently copying.
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		copyWg := new(sync.WaitGroup)
-		defer close(statusCh)
-
-		for scanner.Scan() {
-			var cpURLs copyURLs
-			json.Unmarshal([]byte(scanner.Text()), &cpURLs)
-			if isCopied(cpURLs.SourceContent.Name) {
-				doCopyFake(cpURLs, progressReader)
-			} else {
-				// Wait for other copy routines to
-				// complete. We only have limited CPU
-				// and network resources.
-				cpQueue <- true
-				// Account for each copy routines we start.
-				copyWg.Add(1)
-				// Do copying in background concurrently.
-				go doCopy(cpURLs, progressReader, cpQueue, copyWg, statusCh)
-			}
-		}
-		copyWg.Wait()
-	}()
-	wg.Wait()
+	}, func(opts transfer.Options, events chan<- transfer.Event) *probe.Error {
+		return transfer.Copy(globalContext, opts, session, sourceURLs, targetURL, events)
+	})
 }
 
 func setCopyPalette(style string) {
@@ -343,6 +136,8 @@ func mainCopy(ctx *cli.Context) {
 
 	var e error
 	session.Header.CommandType = "cp"
+	maxRetries := ctx.Int("retry")
+	session.Header.MaxRetries = &maxRetries
 	session.Header.RootPath, e = os.Getwd()
 	if e != nil {
 		session.Delete()
@@ -357,6 +152,14 @@ func mainCopy(ctx *cli.Context) {
 		fatalIf(err.Trace(), "One or more unknown URL types passed.")
 	}
 
+	session.Header.Parallel, session.Header.Adaptive, err = getParallel(ctx.String("parallel"))
+	if err != nil {
+		session.Delete()
+		fatalIf(err.Trace(), "Unable to determine number of parallel transfers.")
+	}
+	session.Header.LimitUpload = ctx.String("limit-upload")
+	session.Header.LimitDownload = ctx.String("limit-download")
+
 	doCopySession(session)
 	session.Delete()
 }
@@ -19,11 +19,18 @@ package main
 import (
 	"bytes"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync/atomic"
 
+	"github.com/minio/mc/pkg/client/mem"
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/s3fake"
+	"github.com/minio/mc/pkg/transfer"
 
 	. "gopkg.in/check.v1"
 )
@@ -31,31 +38,31 @@ import (
 func (s *TestSuite) TestCopyURLType(c *C) {
 	sourceURLs := []string{server.URL + "/bucket/object1"}
 	targetURL := server.URL + "/bucket/test"
-	c.Assert(guessCopyURLType(sourceURLs, targetURL), Equals, copyURLsTypeA)
+	c.Assert(transfer.GuessCopyURLType(globalContext, transferOptions(), sourceURLs, targetURL), Equals, transfer.CopyURLsTypeA)
 
 	sourceURLs = []string{server.URL + "/bucket/object1"}
 	targetURL = server.URL + "/bucket"
-	c.Assert(guessCopyURLType(sourceURLs, targetURL), Equals, copyURLsTypeB)
+	c.Assert(transfer.GuessCopyURLType(globalContext, transferOptions(), sourceURLs, targetURL), Equals, transfer.CopyURLsTypeB)
 
 	sourceURLs = []string{server.URL + "/bucket/..."}
 	targetURL = server.URL + "/bucket"
-	c.Assert(guessCopyURLType(sourceURLs, targetURL), Equals, copyURLsTypeC)
+	c.Assert(transfer.GuessCopyURLType(globalContext, transferOptions(), sourceURLs, targetURL), Equals, transfer.CopyURLsTypeC)
 
 	sourceURLs = []string{server.URL + "/bucket/...", server.URL + "/bucket/..."}
 	targetURL = server.URL + "/bucket/test"
-	c.Assert(guessCopyURLType(sourceURLs, targetURL), Equals, copyURLsTypeD)
+	c.Assert(transfer.GuessCopyURLType(globalContext, transferOptions(), sourceURLs, targetURL), Equals, transfer.CopyURLsTypeD)
 
 	sourceURLs = []string{}
 	targetURL = server.URL + "/bucket"
-	c.Assert(guessCopyURLType(sourceURLs, targetURL), Equals, copyURLsTypeInvalid)
+	c.Assert(transfer.GuessCopyURLType(globalContext, transferOptions(), sourceURLs, targetURL), Equals, transfer.CopyURLsTypeInvalid)
 
 	sourceURLs = nil
 	targetURL = server.URL + "/bucket"
-	c.Assert(guessCopyURLType(sourceURLs, targetURL), Equals, copyURLsTypeInvalid)
+	c.Assert(transfer.GuessCopyURLType(globalContext, transferOptions(), sourceURLs, targetURL), Equals, transfer.CopyURLsTypeInvalid)
 
 	sourceURLs = []string{server.URL + "/bucket/...", server.URL + "/bucket/..."}
 	targetURL = ""
-	c.Assert(guessCopyURLType(sourceURLs, targetURL), Equals, copyURLsTypeInvalid)
+	c.Assert(transfer.GuessCopyURLType(globalContext, transferOptions(), sourceURLs, targetURL), Equals, transfer.CopyURLsTypeInvalid)
 }
 
 func (s *TestSuite) TestMirror(c *C) {
@@ -134,3 +141,92 @@ func (s *TestSuite) TestCopy(c *C) {
 	console.IsError = false
 	console.IsExited = false
 }
+
+func (s *TestSuite) TestMemBackend(c *C) {
+	source, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(source)
+	defer mem.Reset()
+
+	for i := 0; i < 10; i++ {
+		objectPath := filepath.Join(source, "object"+strconv.Itoa(i))
+		perr := putTarget(objectPath, int64(len("hello")), bytes.NewReader([]byte("hello")))
+		c.Assert(perr, IsNil)
+	}
+
+	console.IsExited = false
+	for _, args := range [][]string{
+		{"mb", "mem://test/bucket"},
+		{"mb", "mem://test/mirror"},
+		{"cp", filepath.Join(source, "..."), "mem://test/bucket/"},
+		{"mirror", "mem://test/bucket...", "mem://test/mirror"},
+		{"diff", "mem://test/bucket", "mem://test/mirror"},
+		{"rm", "mem://test/mirror/object0"},
+	} {
+		err = app.Run(append([]string{os.Args[0]}, args...))
+		c.Assert(err, IsNil)
+		c.Assert(console.IsExited, Equals, false)
+	}
+
+	// Contents are visible from within the process, as long as it lives.
+	clnt, perr := url2Client("mem://test/mirror/")
+	c.Assert(perr, IsNil)
+	var names []string
+	for content := range clnt.List(globalContext, true, false) {
+		c.Assert(content.Err, IsNil)
+		names = append(names, content.Content.Name)
+	}
+	c.Assert(len(names), Equals, 9)
+	c.Assert(names[0], Equals, "object1")
+}
+
+// TestTransferRollback - bytes of a failed attempt are taken back, not accounted twice.
+func (s *TestSuite) TestTransferRollback(c *C) {
+	hostCfg, perr := getHostConfig("http://127.0.0.1:9000")
+	c.Assert(perr, IsNil)
+	fake := s3fake.New(s3fake.Config{AccessKeyID: hostCfg.AccessKeyID, SecretAccessKey: hostCfg.SecretAccessKey})
+	// The first upload of the object fails after its body is read.
+	var puts int32
+	s3 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" && strings.HasSuffix(r.URL.Path, "/object") && atomic.AddInt32(&puts, 1) == 1 {
+			ioutil.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("<Error><Code>InternalError</Code><Message>We encountered an internal error. Please try again.</Message></Error>"))
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	defer s3.Close()
+
+	clnt, perr := url2Client(s3.URL + "/bucket")
+	c.Assert(perr, IsNil)
+	c.Assert(clnt.MakeBucket(globalContext), IsNil)
+
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+	objectPath := filepath.Join(root, "object")
+	perr = putTarget(objectPath, int64(len("hello")), bytes.NewReader([]byte("hello")))
+	c.Assert(perr, IsNil)
+
+	display := newTransferDisplay("copy", func(event transfer.Event) Message {
+		return CopyMessage{Source: event.Source, Target: event.Targets[0], Length: event.Size}
+	})
+	events := make(chan transfer.Event)
+	shown := make(chan struct{})
+	go func() {
+		defer close(shown)
+		for event := range events {
+			display.show(event)
+		}
+	}()
+	opts := transferOptions()
+	opts.Retries = 1
+	perr = transfer.Copy(globalContext, opts, nil, []string{objectPath}, s3.URL+"/bucket/object", events)
+	<-shown
+	c.Assert(perr, IsNil)
+	defer display.acct.Finish()
+	c.Assert(atomic.LoadInt32(&puts), Equals, int32(2))
+	c.Assert(atomic.LoadInt64(&display.acct.current), Equals, int64(len("hello")))
+}
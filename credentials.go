@@ -0,0 +1,407 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Credentials are looked up in this order of precedence, the first source holding
+// access keys for a host wins:
+//
+//  1. ‘MC_HOST_<alias>=https://<access-key>:<secret-key>[:<session-token>]@<host>’ environment
+//     variables, which also define <alias>.
+//  2. Access keys of the host in config.json.
+//  3. ‘AWS_ACCESS_KEY_ID’, ‘AWS_SECRET_ACCESS_KEY’ and ‘AWS_SESSION_TOKEN’ environment variables.
+//  4. The ‘AWS_PROFILE’ profile, else ‘default’, in ‘~/.aws/credentials’ or ‘AWS_SHARED_CREDENTIALS_FILE’.
+//  5. The ‘credential_process’ command of that profile, in ‘~/.aws/config’ or ‘AWS_CONFIG_FILE’.
+//
+// Sources 3 to 5 are only used for hosts in config.json without access keys of their
+// own, which are hosts of Amazon Web Services or set ‘awsCredentials’, so that AWS keys
+// are never sent to other hosts, such as the anonymous defaults. Sources 4 and 5 are
+// looked up again while mc runs, to pick up rotated credentials.
+
+// envHostPrefix prefixes environment variables defining hosts and their credentials.
+const envHostPrefix = "MC_HOST_"
+
+// envHost is a host defined by an environment variable, along with its credentials.
+type envHost struct {
+	URL             string // scheme://host[/path], without credentials.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// parseEnvHost parses ‘https://<access-key>:<secret-key>[:<session-token>]@<host>’, credentials
+// are optional.
+// Secret keys may contain ‘/’, so the host starts after the last ‘@’.
+func parseEnvHost(value string) (envHost, bool) {
+	i := strings.Index(value, "://")
+	if i <= 0 {
+		return envHost{}, false
+	}
+	scheme, rest := value[:i], value[i+len("://"):]
+	var host envHost
+	if j := strings.LastIndex(rest, "@"); j >= 0 {
+		userInfo := strings.SplitN(rest[:j], ":", 3)
+		if len(userInfo) < 2 {
+			return envHost{}, false
+		}
+		for k, field := range []*string{&host.AccessKeyID, &host.SecretAccessKey, &host.SessionToken}[:len(userInfo)] {
+			var e error
+			if *field, e = url.PathUnescape(userInfo[k]); e != nil {
+				return envHost{}, false
+			}
+		}
+		rest = rest[j+1:]
+	}
+	host.URL = scheme + "://" + rest
+	if u := client.NewURL(host.URL); u.Type != client.Object {
+		return envHost{}, false
+	}
+	return host, true
+}
+
+// getEnvHosts returns hosts defined by ‘MC_HOST_<alias>’ environment variables, by alias.
+// Invalid ones are reported by checkEnvHosts.
+func getEnvHosts() map[string]envHost {
+	hosts := make(map[string]envHost)
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, envHostPrefix) {
+			continue
+		}
+		kv := strings.SplitN(strings.TrimPrefix(env, envHostPrefix), "=", 2)
+		if len(kv) != 2 || !isValidAliasName(kv[0]) {
+			continue
+		}
+		if host, ok := parseEnvHost(kv[1]); ok {
+			hosts[kv[0]] = host
+		}
+	}
+	return hosts
+}
+
+// checkEnvHosts fails on invalid ‘MC_HOST_<alias>’ environment variables, rather than ignore them.
+func checkEnvHosts() {
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, envHostPrefix) {
+			continue
+		}
+		kv := strings.SplitN(env, "=", 2)
+		if !isValidAliasName(strings.TrimPrefix(kv[0], envHostPrefix)) {
+			fatalIf(errInvalidEnvHost(kv[0]).Trace(), "Invalid alias in environment variable.")
+		}
+		if _, ok := parseEnvHost(kv[1]); !ok {
+			fatalIf(errInvalidEnvHost(kv[0]).Trace(), "Invalid host in environment variable.")
+		}
+	}
+}
+
+// withEnvAliases returns aliases, along with those defined by ‘MC_HOST_<alias>’ environment
+// variables which take precedence.
+func withEnvAliases(aliases map[string]string) map[string]string {
+	envHosts := getEnvHosts()
+	if len(envHosts) == 0 {
+		return aliases
+	}
+	merged := make(map[string]string, len(aliases)+len(envHosts))
+	for alias, url := range aliases {
+		merged[alias] = url
+	}
+	for alias, host := range envHosts {
+		merged[alias] = host.URL
+	}
+	return merged
+}
+
+// lookupEnvHost returns the ‘MC_HOST_<alias>’ host of url, and its alias.
+func lookupEnvHost(url *client.URL) (envHost, string, bool) {
+	var found envHost
+	foundAlias, foundPath := "", -1
+	for alias, host := range getEnvHosts() {
+		hostURL := client.NewURL(host.URL)
+		if hostURL.Scheme != url.Scheme || hostURL.Host != url.Host {
+			continue
+		}
+		// Hosts with a path, such as a bucket, only hold credentials within it.
+		path := strings.TrimSuffix(hostURL.Path, "/")
+		if path != "" && url.Path != path && !strings.HasPrefix(url.Path, path+"/") {
+			continue
+		}
+		// The longest path wins, then the first alias for determinism.
+		if len(path) > foundPath || len(path) == foundPath && alias < foundAlias {
+			found, foundAlias, foundPath = host, alias, len(path)
+		}
+	}
+	return found, foundAlias, foundPath >= 0
+}
+
+// hasAccessKeys returns true if hostCfg holds access keys, rather than none or the placeholders.
+func hasAccessKeys(hostCfg hostConfig) bool {
+	return hostCfg.AccessKeyID != "" && hostCfg.AccessKeyID != globalAccessKeyID &&
+		hostCfg.SecretAccessKey != "" && hostCfg.SecretAccessKey != globalSecretAccessKey
+}
+
+// isAmazonHost returns true for hosts of Amazon Web Services, with or without a port.
+func isAmazonHost(host string) bool {
+	if hostname, _, e := net.SplitHostPort(host); e == nil {
+		host = hostname
+	}
+	host = strings.ToLower(host)
+	for _, domain := range []string{"amazonaws.com", "amazonaws.com.cn"} {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// credentialsShown remembers hosts whose credential source was shown with ‘--debug’ already.
+var credentialsShown = struct {
+	sync.Mutex
+	hosts map[string]string
+}{hosts: make(map[string]string)}
+
+// showCredentialsSource shows where credentials for host came from, once per host and source.
+func showCredentialsSource(host, source string) {
+	credentialsShown.Lock()
+	defer credentialsShown.Unlock()
+	if credentialsShown.hosts[host] == source {
+		return
+	}
+	credentialsShown.hosts[host] = source
+	console.Debugln("Using credentials from " + source + " for host ‘" + host + "’.")
+}
+
+// resolveCredentials fills in access keys for url from the sources listed above. matchErr
+// tells that no host in config.json matches url.
+func resolveCredentials(url *client.URL, hostCfg hostConfig, matchErr *probe.Error) (hostConfig, *probe.Error) {
+	if host, alias, ok := lookupEnvHost(url); ok {
+		if matchErr != nil {
+			hostCfg = hostConfig{API: "S3v4"}
+		}
+		hostCfg.AccessKeyID = host.AccessKeyID
+		hostCfg.SecretAccessKey = host.SecretAccessKey
+		hostCfg.SessionToken = host.SessionToken
+		showCredentialsSource(url.Host, "environment variable ‘"+envHostPrefix+alias+"’")
+		return hostCfg, nil
+	}
+	if matchErr != nil {
+		return hostConfig{}, matchErr.Trace()
+	}
+	if hasAccessKeys(hostCfg) {
+		showCredentialsSource(url.Host, "configuration file")
+		return hostCfg, nil
+	}
+	if !hostCfg.AWSCredentials && !isAmazonHost(url.Host) {
+		showCredentialsSource(url.Host, "nowhere, anonymous access")
+		return hostCfg, nil
+	}
+	creds, source, rotating, err := getAWSCredentials()
+	if err != nil {
+		return hostConfig{}, err.Trace(url.String())
+	}
+	if source == "" {
+		showCredentialsSource(url.Host, "nowhere, anonymous access")
+		return hostCfg, nil
+	}
+	hostCfg.AccessKeyID = creds.AccessKeyID
+	hostCfg.SecretAccessKey = creds.SecretAccessKey
+	hostCfg.SessionToken = creds.SessionToken
+	hostCfg.expiration = creds.Expiration
+	hostCfg.rotating = rotating
+	showCredentialsSource(url.Host, source)
+	return hostCfg, nil
+}
+
+// getAWSCredentials returns credentials from AWS environment variables, shared credentials
+// file or credential_process, where they came from and whether they may be rotated while
+// mc runs. source is empty if none were found.
+func getAWSCredentials() (creds client.Credentials, source string, rotating bool, err *probe.Error) {
+	creds = client.Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.AccessKeyID != "" && creds.SecretAccessKey != "" {
+		return creds, "environment variables ‘AWS_ACCESS_KEY_ID’ and ‘AWS_SECRET_ACCESS_KEY’", false, nil
+	}
+
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+	credentialsFile := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsFile == "" {
+		credentialsFile = awsFilePath("credentials")
+	}
+	credentials, err := loadINIFile(credentialsFile)
+	if err != nil {
+		return client.Credentials{}, "", false, err.Trace(credentialsFile)
+	}
+	section := credentials[profile]
+	if section["aws_access_key_id"] != "" && section["aws_secret_access_key"] != "" {
+		creds = client.Credentials{
+			AccessKeyID:     section["aws_access_key_id"],
+			SecretAccessKey: section["aws_secret_access_key"],
+			SessionToken:    section["aws_session_token"],
+		}
+		return creds, "profile ‘" + profile + "’ in ‘" + credentialsFile + "’", true, nil
+	}
+
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = awsFilePath("config")
+	}
+	config, err := loadINIFile(configFile)
+	if err != nil {
+		return client.Credentials{}, "", false, err.Trace(configFile)
+	}
+	// Profiles other than default are named ‘profile <name>’ in the config file.
+	section = config["profile "+profile]
+	if profile == "default" && section == nil {
+		section = config[profile]
+	}
+	if command := section["credential_process"]; command != "" {
+		creds, err = runCredentialProcess(command)
+		if err != nil {
+			return client.Credentials{}, "", false, err.Trace(command)
+		}
+		return creds, "credential_process of profile ‘" + profile + "’ in ‘" + configFile + "’", true, nil
+	}
+	return client.Credentials{}, "", false, nil
+}
+
+// awsFilePath returns the path of an AWS CLI file in ~/.aws, or an empty path if there is no home.
+func awsFilePath(name string) string {
+	u, e := user.Current()
+	if e != nil {
+		return ""
+	}
+	return filepath.Join(u.HomeDir, ".aws", name)
+}
+
+// loadINIFile parses an AWS CLI style INI file into its sections, a missing file has none.
+func loadINIFile(path string) (map[string]map[string]string, *probe.Error) {
+	sections := make(map[string]map[string]string)
+	if path == "" {
+		return sections, nil
+	}
+	file, e := os.Open(path)
+	if os.IsNotExist(e) {
+		return sections, nil
+	}
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	defer file.Close()
+
+	var section map[string]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if sections[name] == nil {
+				sections[name] = make(map[string]string)
+			}
+			section = sections[name]
+		case section != nil && strings.Contains(line, "="):
+			kv := strings.SplitN(line, "=", 2)
+			section[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+	if e := scanner.Err(); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return sections, nil
+}
+
+// credentialProcessOutput is printed by credential_process commands.
+type credentialProcessOutput struct {
+	Version         int
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      time.Time
+}
+
+// credentialProcesses caches credentials by command, commands are run again once their
+// credentials are about to expire.
+var credentialProcesses = struct {
+	sync.Mutex
+	creds map[string]client.Credentials
+}{creds: make(map[string]client.Credentials)}
+
+// credentialProcessWindow - credentials printed by credential_process are renewed this long before they expire.
+const credentialProcessWindow = 5 * time.Minute
+
+// runCredentialProcess runs command through the shell, and returns the credentials it prints.
+func runCredentialProcess(command string) (client.Credentials, *probe.Error) {
+	credentialProcesses.Lock()
+	defer credentialProcesses.Unlock()
+	if creds, ok := credentialProcesses.creds[command]; ok {
+		if creds.Expiration.IsZero() || time.Now().Before(creds.Expiration.Add(-credentialProcessWindow)) {
+			return creds, nil
+		}
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stderr = os.Stderr
+	stdout, e := cmd.Output()
+	if e != nil {
+		return client.Credentials{}, probe.NewError(e)
+	}
+	var output credentialProcessOutput
+	if e = json.NewDecoder(bytes.NewReader(stdout)).Decode(&output); e != nil {
+		return client.Credentials{}, probe.NewError(e)
+	}
+	if output.Version != 1 || output.AccessKeyID == "" || output.SecretAccessKey == "" {
+		return client.Credentials{}, errInvalidCredentialProcess(command).Trace()
+	}
+	creds := client.Credentials{
+		AccessKeyID:     output.AccessKeyID,
+		SecretAccessKey: output.SecretAccessKey,
+		SessionToken:    output.SessionToken,
+		Expiration:      output.Expiration,
+	}
+	credentialProcesses.creds[command] = creds
+	return creds, nil
+}
@@ -17,6 +17,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"strings"
 
@@ -166,7 +167,10 @@ func doDiffObjects(firstURL, secondURL string, ch chan DiffMessage) {
 }
 
 func dodiff(firstClnt, secondClnt client.Client, ch chan DiffMessage) {
-	for contentCh := range firstClnt.List(false, false) {
+	// Stop listing if we bail out early on an error.
+	ctx, cancel := context.WithCancel(globalContext)
+	defer cancel()
+	for contentCh := range firstClnt.List(ctx, false, false) {
 		if contentCh.Err != nil {
 			ch <- DiffMessage{
 				Error: contentCh.Err.Trace(firstClnt.URL().String()),
@@ -231,8 +235,11 @@ func dodiffRecursive(firstClnt, secondClnt client.Client, ch chan DiffMessage) {
 		return
 	}
 
-	fch := firstClnt.List(true, false)
-	sch := secondClnt.List(true, false)
+	// Either listing is not read to its end once the other one is done.
+	ctx, cancel := context.WithCancel(globalContext)
+	defer cancel()
+	fch := firstClnt.List(ctx, true, false)
+	sch := secondClnt.List(ctx, true, false)
 	f, fok := <-fch
 	s, sok := <-sch
 	for {
@@ -0,0 +1,300 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/httptracer"
+	"github.com/minio/mc/pkg/s3fake"
+
+	. "gopkg.in/check.v1"
+)
+
+// TestFakeS3 runs commands end to end against a fake S3 server, signing with the credentials
+// configured for 127.0.0.1.
+func (s *TestSuite) TestFakeS3(c *C) {
+	hostCfg, perr := getHostConfig("http://127.0.0.1:9000")
+	c.Assert(perr, IsNil)
+	s3 := s3fake.NewServer(s3fake.Config{AccessKeyID: hostCfg.AccessKeyID, SecretAccessKey: hostCfg.SecretAccessKey})
+	defer s3.Close()
+
+	source, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(source)
+	for i := 0; i < 10; i++ {
+		objectPath := filepath.Join(source, "object"+strconv.Itoa(i))
+		perr := putTarget(objectPath, int64(len("hello")), bytes.NewReader([]byte("hello")))
+		c.Assert(perr, IsNil)
+	}
+
+	console.IsExited = false
+	for _, args := range [][]string{
+		{"mb", s3.URL + "/bucket"},
+		{"mb", s3.URL + "/mirror"},
+		{"cp", filepath.Join(source, "..."), s3.URL + "/bucket/"},
+		{"mirror", s3.URL + "/bucket...", s3.URL + "/mirror"},
+		{"diff", s3.URL + "/bucket", s3.URL + "/mirror"},
+		{"share", "download", s3.URL + "/mirror/object1", "1h"},
+		{"share", "upload", s3.URL + "/mirror/upload", "1h"},
+		{"rm", s3.URL + "/mirror/object0"},
+	} {
+		err = app.Run(append([]string{os.Args[0]}, args...))
+		c.Assert(err, IsNil)
+		c.Assert(console.IsExited, Equals, false, Commentf("%v", args))
+	}
+
+	clnt, perr := url2Client(s3.URL + "/mirror/")
+	c.Assert(perr, IsNil)
+	var names []string
+	for content := range clnt.List(globalContext, true, false) {
+		c.Assert(content.Err, IsNil)
+		names = append(names, content.Content.Name)
+	}
+	c.Assert(len(names), Equals, 9)
+	c.Assert(names[0], Equals, "object1")
+
+	reader, _, perr := getSource(s3.URL + "/mirror/object9")
+	c.Assert(perr, IsNil)
+	data, err := ioutil.ReadAll(reader)
+	reader.Close()
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "hello")
+
+	// Removing a missing object reports the error and carries on.
+	err = app.Run([]string{os.Args[0], "rm", s3.URL + "/mirror/object0"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	c.Assert(console.IsError, Equals, true)
+	console.IsError = false
+}
+
+// TestRecordReplay replays commands recorded against a fake S3 server once it is gone.
+func (s *TestSuite) TestRecordReplay(c *C) {
+	hostCfg, perr := getHostConfig("http://127.0.0.1:9000")
+	c.Assert(perr, IsNil)
+	s3 := s3fake.NewServer(s3fake.Config{AccessKeyID: hostCfg.AccessKeyID, SecretAccessKey: hostCfg.SecretAccessKey})
+	defer s3.Close()
+
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+	objectPath := filepath.Join(root, "object")
+	perr = putTarget(objectPath, int64(len("hello")), bytes.NewReader([]byte("hello")))
+	c.Assert(perr, IsNil)
+	defer func() {
+		globalRecorder = nil
+		globalReplayer = nil
+	}()
+
+	commands := [][]string{
+		{"mb", s3.URL + "/bucket"},
+		{"cp", objectPath, s3.URL + "/bucket/object"},
+		{"ls", s3.URL + "/bucket"},
+	}
+	console.IsExited = false
+	for i, args := range commands {
+		cassette := filepath.Join(root, "cassette"+strconv.Itoa(i))
+		file, err := os.Create(cassette)
+		c.Assert(err, IsNil)
+		globalRecorder = httptracer.NewRecorder(file, -1)
+		err = app.Run(append([]string{os.Args[0]}, args...))
+		file.Close()
+		c.Assert(err, IsNil)
+		c.Assert(console.IsExited, Equals, false, Commentf("%v", args))
+
+		data, err := ioutil.ReadFile(cassette)
+		c.Assert(err, IsNil)
+		c.Assert(bytes.Contains(data, []byte(s3.URL)), Equals, true)
+		c.Assert(bytes.Contains(data, []byte(hostCfg.SecretAccessKey)), Equals, false)
+		c.Assert(bytes.Contains(data, []byte(hostCfg.AccessKeyID)), Equals, false)
+	}
+	globalRecorder = nil
+
+	s3.Close()
+	for i, args := range commands {
+		file, err := os.Open(filepath.Join(root, "cassette"+strconv.Itoa(i)))
+		c.Assert(err, IsNil)
+		globalReplayer, err = httptracer.NewReplayer(file)
+		file.Close()
+		c.Assert(err, IsNil)
+		err = app.Run(append([]string{os.Args[0]}, args...))
+		c.Assert(err, IsNil)
+		c.Assert(console.IsExited, Equals, false, Commentf("%v", args))
+	}
+	c.Assert(console.IsError, Equals, false)
+}
+
+// TestBucketLookup runs commands against a fake S3 server addressing buckets by host name, which
+// is reached as the proxy of its hosts.
+func (s *TestSuite) TestBucketLookup(c *C) {
+	s3 := s3fake.NewServer(s3fake.Config{AccessKeyID: "access", SecretAccessKey: "secret", Domain: "s3.example.test"})
+	defer s3.Close()
+
+	config, perr := getMcConfig()
+	c.Assert(perr, IsNil)
+	defer func() {
+		delete(config.Hosts, "s3.example.test")
+		delete(config.Hosts, "*.s3.example.test")
+	}()
+
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+	objectPath := filepath.Join(root, "object")
+	perr = putTarget(objectPath, int64(len("hello")), bytes.NewReader([]byte("hello")))
+	c.Assert(perr, IsNil)
+
+	console.IsExited = false
+	for _, api := range []string{"S3v4", "S3v2"} {
+		config.Hosts["s3.example.test"] = hostConfig{AccessKeyID: "access", SecretAccessKey: "secret", API: api, Lookup: "path", Proxy: s3.URL}
+		config.Hosts["*.s3.example.test"] = hostConfig{AccessKeyID: "access", SecretAccessKey: "secret", API: api, Lookup: "dns", Proxy: s3.URL}
+		bucket := "http://" + strings.ToLower(api) + ".s3.example.test"
+		for _, args := range [][]string{
+			{"mb", "http://s3.example.test/" + strings.ToLower(api)},
+			{"cp", objectPath, bucket + "/"},
+			{"ls", bucket},
+			{"access", "set", "readonly", bucket},
+			{"share", "download", bucket + "/object", "1h"},
+			{"share", "upload", bucket + "/upload", "1h"},
+		} {
+			err = app.Run(append([]string{os.Args[0]}, args...))
+			c.Assert(err, IsNil)
+			c.Assert(console.IsExited, Equals, false, Commentf("%v", args))
+		}
+
+		reader, _, perr := getSource("http://s3.example.test/" + strings.ToLower(api) + "/object")
+		c.Assert(perr, IsNil)
+		data, err := ioutil.ReadAll(reader)
+		reader.Close()
+		c.Assert(err, IsNil)
+		c.Assert(string(data), Equals, "hello")
+	}
+	c.Assert(console.IsError, Equals, false)
+
+	// Buckets of other hosts are still addressed by path.
+	u := client.NewURL("http://s3.example.test/bucket/object")
+	lookup, perr := getBucketLookup(u.String())
+	c.Assert(perr, IsNil)
+	bucketName, objectName := u.BucketAndObject(lookup)
+	c.Assert(bucketName, Equals, "bucket")
+	c.Assert(objectName, Equals, "object")
+}
+
+// TestBucketRegion creates a bucket in a region of a fake S3 server, which rejects requests signed
+// for other regions, and copies to it once its region is discovered.
+func (s *TestSuite) TestBucketRegion(c *C) {
+	hostCfg, perr := getHostConfig("http://127.0.0.1:9000")
+	c.Assert(perr, IsNil)
+	s3 := s3fake.NewServer(s3fake.Config{AccessKeyID: hostCfg.AccessKeyID, SecretAccessKey: hostCfg.SecretAccessKey})
+	defer s3.Close()
+
+	root, err := ioutil.TempDir(os.TempDir(), "cmd-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+	objectPath := filepath.Join(root, "object")
+	perr = putTarget(objectPath, int64(len("hello")), bytes.NewReader([]byte("hello")))
+	c.Assert(perr, IsNil)
+
+	console.IsExited = false
+	for _, args := range [][]string{
+		{"mb", "--region", "eu-west-1", s3.URL + "/europe"},
+		{"cp", objectPath, s3.URL + "/europe/object"},
+		{"ls", s3.URL + "/europe"},
+	} {
+		err = app.Run(append([]string{os.Args[0]}, args...))
+		c.Assert(err, IsNil)
+		c.Assert(console.IsExited, Equals, false, Commentf("%v", args))
+	}
+	c.Assert(console.IsError, Equals, false)
+
+	// Regions configured for the host are used as they are.
+	hostCfg.Region = "us-west-2"
+	clnt, perr := getNewClient(s3.URL+"/europe/object", hostCfg)
+	c.Assert(perr, IsNil)
+	_, perr = clnt.Stat(globalContext)
+	c.Assert(perr, Not(IsNil))
+	hostCfg.Region = "eu-west-1"
+	clnt, perr = getNewClient(s3.URL+"/europe/object", hostCfg)
+	c.Assert(perr, IsNil)
+	content, perr := clnt.Stat(globalContext)
+	c.Assert(perr, IsNil)
+	c.Assert(content.Size, Equals, int64(len("hello")))
+
+}
+
+// TestSignatureFallback adds a fake S3 server accepting signature V2 only, whose API is probed,
+// and falls back to V2 for it once configured to.
+func (s *TestSuite) TestSignatureFallback(c *C) {
+	hostCfg, perr := getHostConfig("http://127.0.0.1:9000")
+	c.Assert(perr, IsNil)
+	s3 := s3fake.NewServer(s3fake.Config{AccessKeyID: hostCfg.AccessKeyID, SecretAccessKey: hostCfg.SecretAccessKey, SignatureV2Only: true})
+	defer s3.Close()
+
+	console.IsExited = false
+	err := app.Run([]string{os.Args[0], "config", "host", "add", s3.URL, hostCfg.AccessKeyID, hostCfg.SecretAccessKey})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	defer app.Run([]string{os.Args[0], "config", "host", "remove", s3.URL})
+	added, perr := getHostConfig(s3.URL)
+	c.Assert(perr, IsNil)
+	c.Assert(added.API, Equals, "S3v2")
+
+	// Clients of API S3v4 are told to switch.
+	hostCfg.API = "S3v4"
+	clnt, perr := getNewClient(s3.URL+"/bucket", hostCfg)
+	c.Assert(perr, IsNil)
+	perr = clnt.MakeBucket(globalContext)
+	c.Assert(perr, Not(IsNil))
+	c.Assert(perr.ToGoError(), FitsTypeOf, client.SignatureRejected{})
+	c.Assert(perr.ToGoError().Error(), Matches, ".*S3v2.*")
+
+	// Or fall back if configured to.
+	hostCfg.FallbackAPI = "S3v2"
+	clnt, perr = getNewClient(s3.URL+"/bucket", hostCfg)
+	c.Assert(perr, IsNil)
+	c.Assert(clnt.MakeBucket(globalContext), IsNil)
+	clnt, perr = getNewClient(s3.URL+"/bucket/object", hostCfg)
+	c.Assert(perr, IsNil)
+	c.Assert(clnt.Put(globalContext, int64(len("hello")), strings.NewReader("hello")), IsNil)
+	content, perr := clnt.Stat(globalContext)
+	c.Assert(perr, IsNil)
+	c.Assert(content.Size, Equals, int64(len("hello")))
+
+	// Hosts which accepted a signature never fall back, a wrong secret key is no reason to.
+	v4 := s3fake.NewServer(s3fake.Config{AccessKeyID: hostCfg.AccessKeyID, SecretAccessKey: hostCfg.SecretAccessKey})
+	defer v4.Close()
+	clnt, perr = getNewClient(v4.URL+"/bucket", hostCfg)
+	c.Assert(perr, IsNil)
+	c.Assert(clnt.MakeBucket(globalContext), IsNil)
+	wrongCfg := hostCfg
+	wrongCfg.SecretAccessKey = "wrong" + hostCfg.SecretAccessKey
+	clnt, perr = getNewClient(v4.URL+"/bucket", wrongCfg)
+	c.Assert(perr, IsNil)
+	// Only requests with a body tell a rejected signature, responses to HEAD have none.
+	perr = (<-clnt.List(globalContext, false, false)).Err
+	c.Assert(perr, Not(IsNil))
+	c.Assert(perr.ToGoError(), FitsTypeOf, client.SignatureRejected{})
+	c.Assert(perr.ToGoError().(client.SignatureRejected).API, Equals, "S3v4")
+}
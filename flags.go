@@ -16,7 +16,10 @@
 
 package main
 
-import "github.com/minio/cli"
+import (
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/transfer"
+)
 
 // Collection of mc commands currently supported
 var commands = []cli.Command{}
@@ -60,9 +63,76 @@ var (
 		Usage: "Choose type of console coloring. Available options are [‘dark’, ‘light’, ‘nocolor’]",
 	}
 
+	recordFlag = cli.StringFlag{
+		Name:  "record",
+		Usage: "Record HTTP requests and responses to a cassette file, for bug reports. Secrets are redacted.",
+	}
+
+	recordBodyLimitFlag = cli.StringFlag{
+		Name:  "record-body-limit",
+		Value: "64KiB",
+		Usage: "Truncate bodies recorded with ‘--record’ to this size, or ‘unlimited’. Truncated responses cannot be replayed.",
+	}
+
+	replayFlag = cli.StringFlag{
+		Name:  "replay",
+		Usage: "Replay HTTP responses from a cassette file recorded with ‘--record’, without network.",
+	}
+
+	traceFileFlag = cli.StringFlag{
+		Name:  "trace-file",
+		Usage: "Write HTTP requests and responses to a HAR file, viewable in browser developer tools. Secrets are redacted.",
+	}
+
+	faultsFlag = cli.StringFlag{
+		Name:   "faults",
+		Usage:  "Inject faults into HTTP requests, e.g. ‘error=0.1,reset=1MiB,latency=200ms,truncate=0.05,seed=1’.",
+		EnvVar: "MC_FAULTS",
+		Hide:   true,
+	}
+
 	// Add your new flags starting here
 )
 
+// Collection of flags accepted by cp and mirror
+var (
+	retryFlag = cli.IntFlag{
+		Name:  "retry",
+		Value: transfer.DefaultRetryLimit,
+		Usage: "Number of times to retry a transfer on transient network errors, before saving the session.",
+	}
+
+	parallelFlag = cli.StringFlag{
+		Name:  "parallel",
+		Usage: "Number of concurrent transfers, or ‘auto’ to adapt them to measured throughput. Defaults to ‘parallel’ in config file.",
+	}
+
+	limitUploadFlag = cli.StringFlag{
+		Name:  "limit-upload",
+		Usage: "Limit upload bandwidth shared by all transfers, e.g. ‘20MiB/s’. Defaults to ‘bandwidth’ in config file.",
+	}
+
+	limitDownloadFlag = cli.StringFlag{
+		Name:  "limit-download",
+		Usage: "Limit download bandwidth shared by all transfers, e.g. ‘20MiB/s’. Defaults to ‘bandwidth’ in config file.",
+	}
+
+	showSecretsFlag = cli.BoolFlag{
+		Name:  "show-secrets",
+		Usage: "Show secret access keys in full, instead of masked.",
+	}
+
+	noCheckFlag = cli.BoolFlag{
+		Name:  "no-check",
+		Usage: "Add hosts without checking their access keys against the host.",
+	}
+
+	regionFlag = cli.StringFlag{
+		Name:  "region",
+		Usage: "Region to create buckets in, e.g. ‘eu-west-1’. Defaults to ‘region’ of the host in config file.",
+	}
+)
+
 // registerCmd registers a cli command
 func registerCmd(cmd cli.Command) {
 	commands = append(commands, cmd)
@@ -17,16 +17,30 @@
 // This package contains all the global variables and constants. ONLY TO BE ACCESSED VIA GET/SET FUNCTIONS.
 package main
 
+import (
+	"context"
+
+	"github.com/minio/mc/pkg/httptracer"
+)
+
 var (
 	globalQuietFlag = false // Quiet flag set via command line
 	globalMimicFlag = false // Unix flag set via command line
 	globalJSONFlag  = false // Json flag set via command line
 	globalDebugFlag = false // Debug flag set via command line
+
+	globalRecorder *httptracer.Recorder // Set via --record
+	globalReplayer *httptracer.Replayer // Set via --replay
+	globalFaults   *httptracer.Faults   // Set via --faults or MC_FAULTS, for resilience tests
+	globalHAR      *httptracer.HAR      // Set via --trace-file
 )
 
+// globalContext is passed on to all client operations, cancelling it aborts requests in progress.
+var globalContext, globalCancel = context.WithCancel(context.Background())
+
 // mc configuration related constants.
 const (
-	globalMCConfigVersion = "5"
+	globalMCConfigVersion = "7"
 	globalMCVersion       = mcVersion
 
 	globalMCConfigDir        = ".mc/"
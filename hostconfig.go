@@ -17,45 +17,274 @@
 package main
 
 import (
+	"net"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/minio/mc/pkg/client"
 	"github.com/minio/minio-xl/pkg/probe"
 )
 
+// clockOffsetAuto dates requests to a host by its clock, see hostConfig.ClockOffset.
+const clockOffsetAuto = "auto"
+
 type hostConfig struct {
 	AccessKeyID     string `json:"accessKeyId"`
 	SecretAccessKey string `json:"secretAccessKey"`
 	API             string `json:"api"`
+	// FallbackAPI, such as ‘S3v2’, sends requests to hosts which rejected signatures of API.
+	FallbackAPI string `json:"fallbackApi,omitempty"`
+	// SessionToken of temporary credentials, such as those issued by STS.
+	SessionToken string `json:"sessionToken,omitempty"`
+	// Region requests are signed for, such as ‘eu-west-1’. If empty it is the location of each
+	// bucket, or else guessed from the host name.
+	Region string `json:"region,omitempty"`
+	// Lookup is ‘path’ if buckets are addressed by the first element of the path, ‘dns’ if by the
+	// first label of the host name, or ‘auto’ which picks ‘dns’ for Amazon S3 only.
+	Lookup string `json:"lookup,omitempty"`
+	// ClockOffset is added to the local clock when dating requests, such as ‘-90s’, or ‘auto’ to
+	// date them by the clock of the host.
+	ClockOffset string `json:"clockOffset,omitempty"`
+	// RequestsPerSecond limits requests sent to the host, 0 means unlimited.
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty"`
+	// Timeout bounds metadata operations and waiting for response headers, such as ‘30s’.
+	Timeout string `json:"timeout,omitempty"`
+
+	// CABundle is a PEM file of certificate authorities trusted besides those of the system,
+	// such as the private CA of an internal cluster.
+	CABundle string `json:"caBundle,omitempty"`
+	// ClientCert and ClientKey are PEM files of a client certificate and its key, for mutual TLS.
+	ClientCert string `json:"clientCert,omitempty"`
+	ClientKey  string `json:"clientKey,omitempty"`
+	// Insecure skips verification of the host's certificate, for lab setups only.
+	Insecure bool `json:"insecure,omitempty"`
+	// Proxy is the URL of a proxy for the host, else proxies come from the environment.
+	Proxy string `json:"proxy,omitempty"`
+	// ConnectTimeout bounds dialing the host and ReadTimeout waiting for data from it, such as ‘5s’.
+	ConnectTimeout string `json:"connectTimeout,omitempty"`
+	ReadTimeout    string `json:"readTimeout,omitempty"`
+	// MaxIdleConns is the number of idle keep-alive connections to the host kept open.
+	MaxIdleConns int `json:"maxIdleConns,omitempty"`
+	// AWSCredentials signs requests to a host without access keys of its own with AWS
+	// credentials from the environment, ‘~/.aws’ or ‘credential_process’. Hosts of Amazon Web
+	// Services always are, other hosts only if set, so that AWS keys are not sent elsewhere.
+	AWSCredentials bool `json:"awsCredentials,omitempty"`
+
+	// expiration of temporary credentials, zero if unknown.
+	expiration time.Time
+	// rotating credentials come from sources which may change while mc runs.
+	rotating bool
 }
 
-// getHostConfig retrieves host specific configuration such as access keys, certs.
+// getHostConfig retrieves host specific configuration such as access keys, certs. Access keys
+// are resolved from the sources listed in credentials.go. Secrets of hosts are loaded from their
+// store only for hosts which need keys.
 func getHostConfig(URL string) (hostConfig, *probe.Error) {
-	config, err := getMcConfig()
-	if err != nil {
-		return hostConfig{}, err.Trace()
+	if !isMcConfigExists() {
+		return hostConfig{}, errInvalidArgument().Trace()
 	}
 	url := client.NewURL(URL)
-	// No host matching or keys needed for filesystem requests
-	if url.Type == client.Filesystem {
+	// No host matching or keys needed for backends such as filesystem
+	if backend, ok := client.LookupBackend(url, ""); ok && !backend.HostConfig {
 		hostCfg := hostConfig{
 			AccessKeyID:     "",
 			SecretAccessKey: "",
-			API:             "fs",
+			API:             backend.API,
 		}
 		return hostCfg, nil
 	}
-	if _, ok := config.Hosts[url.Host]; ok {
-		return config.Hosts[url.Host], nil
+	config, err := getMcConfigSecrets()
+	if err != nil {
+		return hostConfig{}, err.Trace()
+	}
+	hostCfg, err := matchHostConfig(config.Hosts, URL)
+	return resolveCredentials(url, hostCfg, err)
+}
+
+// getBucketLookup returns the bucket lookup style of the host of URL, ‘auto’ for hosts which
+// set none or are not in config.json. Only the host is matched, no credentials are resolved.
+func getBucketLookup(URL string) (string, *probe.Error) {
+	config, err := getMcConfig()
+	if err != nil {
+		return "", err.Trace()
+	}
+	if backend, ok := client.LookupBackend(client.NewURL(URL), ""); ok && !backend.HostConfig {
+		return client.LookupAuto, nil
+	}
+	hostCfg, found, err := findHostConfig(config.Hosts, URL)
+	if err != nil {
+		return "", err.Trace(URL)
+	}
+	if !found || hostCfg.Lookup == "" {
+		return client.LookupAuto, nil
 	}
-	for globURL, hostCfg := range config.Hosts {
-		match, err := filepath.Match(globURL, url.Host)
-		if err != nil {
-			return hostConfig{}, errInvalidGlobURL(globURL, URL).Trace()
+	if !client.IsValidLookup(hostCfg.Lookup) {
+		return "", errInvalidLookup(hostCfg.Lookup).Trace(URL)
+	}
+	return hostCfg.Lookup, nil
+}
+
+// hostPattern - key of a host in config.json, ‘[scheme://]host-glob[:port-glob][/bucket-glob]’,
+// such as ‘*.amazonaws.com’, ‘localhost:*’ or ‘https://s3.amazonaws.com/finance-*’. Patterns
+// without scheme, port or bucket match any.
+type hostPattern struct {
+	key    string
+	scheme string
+	host   string
+	port   string
+	bucket string
+}
+
+// parseHostPattern splits a key of hosts in config.json into its parts.
+func parseHostPattern(key string) hostPattern {
+	p := hostPattern{key: key}
+	rest := key
+	if i := strings.Index(rest, "://"); i >= 0 {
+		p.scheme, rest = rest[:i], rest[i+len("://"):]
+	}
+	if i := strings.Index(rest, "/"); i >= 0 {
+		p.bucket, rest = strings.Trim(rest[i+1:], "/"), rest[:i]
+	}
+	p.host = rest
+	// Colons of IPv6 addresses are within brackets.
+	if i := strings.LastIndex(rest, ":"); i >= 0 && !strings.HasSuffix(rest, "]") {
+		p.host, p.port = rest[:i], rest[i+1:]
+	}
+	return p
+}
+
+// isValid returns true if all globs of the pattern are well formed.
+func (p hostPattern) isValid() bool {
+	for _, glob := range []string{p.host, p.port, p.bucket} {
+		if _, e := filepath.Match(glob, ""); e != nil {
+			return false
+		}
+	}
+	return p.host != ""
+}
+
+// match returns true if url falls within the pattern, its bucket addressed by lookup.
+func (p hostPattern) match(url *client.URL, lookup string) (bool, error) {
+	if p.scheme != "" && p.scheme != url.Scheme {
+		return false, nil
+	}
+	host, port := url.Host, ""
+	if h, pt, e := net.SplitHostPort(url.Host); e == nil {
+		host, port = h, pt
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
 		}
-		if match {
-			return hostCfg, nil
+	}
+	bucket, _ := url.BucketAndObject(lookup)
+	// Exact hosts match as they are, brackets of IPv6 addresses are no character class.
+	if p.host != host {
+		if match, e := filepath.Match(p.host, host); e != nil || !match {
+			return false, e
 		}
 	}
-	return hostConfig{}, errNoMatchingHost(URL).Trace()
+	if p.port != "" {
+		if match, e := filepath.Match(p.port, port); e != nil || !match {
+			return false, e
+		}
+	}
+	if p.bucket != "" {
+		if match, e := filepath.Match(p.bucket, bucket); e != nil || !match {
+			return false, e
+		}
+	}
+	return true, nil
+}
+
+// specificity ranks patterns matching the same URL, element by element: scoped to a bucket,
+// literal characters of the bucket, exact host, literal characters of the host and port,
+// scoped to a scheme.
+func (p hostPattern) specificity() [6]int {
+	boolInt := func(b bool) int {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	return [6]int{
+		boolInt(p.bucket != ""),
+		globLiterals(p.bucket),
+		boolInt(globLiterals(p.host) == len(p.host)),
+		globLiterals(p.host),
+		globLiterals(p.port),
+		boolInt(p.scheme != ""),
+	}
+}
+
+// moreSpecific returns true if p ranks above q.
+func (p hostPattern) moreSpecific(q hostPattern) bool {
+	ps, qs := p.specificity(), q.specificity()
+	for i := range ps {
+		if ps[i] != qs[i] {
+			return ps[i] > qs[i]
+		}
+	}
+	return false
+}
+
+// globLiterals counts characters of pattern matched literally, outside of wildcards and
+// character classes.
+func globLiterals(pattern string) int {
+	n := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*', '?':
+		case '[':
+			for i < len(pattern) && pattern[i] != ']' {
+				i++
+			}
+		case '\\':
+			i++
+			n++
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// matchHostConfig returns the host in hosts whose pattern matches URL most specifically.
+func matchHostConfig(hosts map[string]hostConfig, URL string) (hostConfig, *probe.Error) {
+	hostCfg, found, err := findHostConfig(hosts, URL)
+	if err != nil {
+		return hostConfig{}, err.Trace()
+	}
+	if !found {
+		return hostConfig{}, errNoMatchingHost(URL).Trace()
+	}
+	return hostCfg, nil
+}
+
+// findHostConfig is matchHostConfig telling whether any host matched. Ties go to the pattern
+// sorting first, so that the choice never depends on map order.
+func findHostConfig(hosts map[string]hostConfig, URL string) (hostConfig, bool, *probe.Error) {
+	url := client.NewURL(URL)
+	keys := make([]string, 0, len(hosts))
+	for key := range hosts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var best hostPattern
+	found := false
+	for _, key := range keys {
+		p := parseHostPattern(key)
+		match, e := p.match(url, hosts[key].Lookup)
+		if e != nil {
+			return hostConfig{}, false, errInvalidGlobURL(key, URL).Trace()
+		}
+		if match && (!found || p.moreSpecific(best)) {
+			best, found = p, true
+		}
+	}
+	if !found {
+		return hostConfig{}, false, nil
+	}
+	return hosts[best.key], true, nil
 }
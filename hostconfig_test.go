@@ -0,0 +1,103 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import . "gopkg.in/check.v1"
+
+func (s *TestSuite) TestMatchHostConfig(c *C) {
+	hosts := map[string]hostConfig{
+		"*.amazonaws.com":                  {AccessKeyID: "wildcard"},
+		"s3-eu-west-1.amazonaws.com":       {AccessKeyID: "regional"},
+		"s3*.amazonaws.com":                {AccessKeyID: "s3"},
+		"s3.amazonaws.com/finance-*":       {AccessKeyID: "finance"},
+		"s3.amazonaws.com/finance-reports": {AccessKeyID: "reports"},
+		"http://s3.amazonaws.com":          {AccessKeyID: "http"},
+		"localhost:*":                      {AccessKeyID: "localhost"},
+		"localhost:9000":                   {AccessKeyID: "localhost-9000"},
+		"[::1]":                            {AccessKeyID: "ipv6"},
+		"play.minio.io:9000/*":             {AccessKeyID: "play-a"},
+		"play.minio.io:9000/b*":            {AccessKeyID: "play-b"},
+		"https://play.minio.io:9000/b*":    {AccessKeyID: "play-https"},
+		"*.s3*.amazonaws.com/finance-*":    {AccessKeyID: "finance-dns"},
+		"*.storage.example.com/data-*":     {AccessKeyID: "storage-dns", Lookup: "dns"},
+	}
+	for URL, accessKeyID := range map[string]string{
+		"https://s3-eu-west-1.amazonaws.com/bucket":      "regional",
+		"https://s3.amazonaws.com/bucket/object":         "s3",
+		"https://s3.amazonaws.com:443/bucket":            "s3",
+		"https://ec2.amazonaws.com":                      "wildcard",
+		"https://s3.amazonaws.com/finance-2015/object":   "finance",
+		"https://s3.amazonaws.com/finance-reports/q1":    "reports",
+		"http://s3.amazonaws.com/bucket":                 "http",
+		"http://localhost:9000/bucket":                   "localhost-9000",
+		"http://localhost:9001/bucket":                   "localhost",
+		"http://[::1]/bucket":                            "ipv6",
+		"http://play.minio.io:9000/bucket":               "play-b",
+		"https://play.minio.io:9000/bucket":              "play-https",
+		"http://play.minio.io:9000/0bucket":              "play-a",
+		"https://play.minio.io:9000/bucket/dir/object/x": "play-https",
+		"https://finance-2015.s3.amazonaws.com/object":   "finance-dns",
+		"https://bucket.s3.amazonaws.com/finance-2015/x": "wildcard",
+		"https://data-1.storage.example.com/object":      "storage-dns",
+	} {
+		// Every run of the same lookup agrees.
+		for i := 0; i < 10; i++ {
+			hostCfg, err := matchHostConfig(hosts, URL)
+			c.Assert(err, IsNil, Commentf(URL))
+			c.Assert(hostCfg.AccessKeyID, Equals, accessKeyID, Commentf(URL))
+		}
+	}
+	_, err := matchHostConfig(hosts, "https://example.com")
+	c.Assert(err, Not(IsNil))
+	// Buckets of virtual host style URLs are not their first object prefix.
+	_, err = matchHostConfig(hosts, "https://bucket.storage.example.com/data-1")
+	c.Assert(err, Not(IsNil))
+
+	c.Assert(parseHostPattern("https://s3.amazonaws.com:443/finance-*"), DeepEquals, hostPattern{
+		key: "https://s3.amazonaws.com:443/finance-*", scheme: "https", host: "s3.amazonaws.com", port: "443", bucket: "finance-*",
+	})
+	c.Assert(parseHostPattern("[a-"+"/bucket").isValid(), Equals, false)
+	c.Assert(parseHostPattern("*.example.com:9000").isValid(), Equals, true)
+}
+
+func (s *TestSuite) TestGetBucketLookup(c *C) {
+	config, err := getMcConfig()
+	c.Assert(err, IsNil)
+	defer func() {
+		delete(config.Hosts, "dns.lookup.test")
+		delete(config.Hosts, "bad.lookup.test")
+	}()
+	// Keys are neither needed nor resolved to tell the lookup.
+	config.Hosts["dns.lookup.test"] = hostConfig{API: "S3v4", Lookup: "dns"}
+	config.Hosts["bad.lookup.test"] = hostConfig{API: "S3v4", Lookup: "virtual"}
+
+	lookup, err := getBucketLookup("https://dns.lookup.test/bucket")
+	c.Assert(err, IsNil)
+	c.Assert(lookup, Equals, "dns")
+	lookup, err = getBucketLookup("https://unknown.lookup.test/bucket")
+	c.Assert(err, IsNil)
+	c.Assert(lookup, Equals, "auto")
+	_, err = getBucketLookup("https://bad.lookup.test/bucket")
+	c.Assert(err, Not(IsNil))
+
+	present, err := isObjectKeyPresent("https://unknown.lookup.test/bucket?uploads")
+	c.Assert(err, IsNil)
+	c.Assert(present, Equals, false)
+	present, err = isObjectKeyPresent("https://unknown.lookup.test/bucket/object?uploads")
+	c.Assert(err, IsNil)
+	c.Assert(present, Equals, true)
+}
@@ -0,0 +1,68 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/httptracer"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// ‘--record-body-limit unlimited’ records bodies in full.
+const bodyLimitUnlimited = "unlimited"
+
+// parseBodyLimit parses sizes such as ‘64KiB’, -1 stands for unlimited.
+func parseBodyLimit(limit string) (int64, *probe.Error) {
+	limit = strings.TrimSpace(limit)
+	if limit == "" || limit == bodyLimitUnlimited {
+		return -1, nil
+	}
+	bytes, e := humanize.ParseBytes(limit)
+	if e != nil {
+		return 0, errInvalidBodyLimit(limit).Trace()
+	}
+	return int64(bytes), nil
+}
+
+// setHTTPTraces sets up recording to and replaying from the cassettes given with
+// ‘--record’ and ‘--replay’, and tracing to the HAR file given with ‘--trace-file’.
+func setHTTPTraces(ctx *cli.Context) {
+	if path := ctx.GlobalString("record"); path != "" {
+		maxBody, err := parseBodyLimit(ctx.GlobalString("record-body-limit"))
+		fatalIf(err.Trace(), "Unable to record to cassette.")
+		file, e := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		fatalIf(probe.NewError(e), "Unable to create cassette ‘"+path+"’.")
+		globalRecorder = httptracer.NewRecorder(file, maxBody)
+	}
+	if path := ctx.GlobalString("replay"); path != "" {
+		file, e := os.Open(path)
+		fatalIf(probe.NewError(e), "Unable to open cassette ‘"+path+"’.")
+		defer file.Close()
+		globalReplayer, e = httptracer.NewReplayer(file)
+		fatalIf(probe.NewError(e), "Unable to load cassette ‘"+path+"’.")
+	}
+	if path := ctx.GlobalString("trace-file"); path != "" {
+		file, e := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+		fatalIf(probe.NewError(e), "Unable to create trace file ‘"+path+"’.")
+		globalHAR, e = httptracer.NewHAR(file, "mc", mcVersion)
+		fatalIf(probe.NewError(e), "Unable to write trace file ‘"+path+"’.")
+	}
+}
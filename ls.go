@@ -17,6 +17,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -112,11 +113,14 @@ func doList(clnt client.Client, recursive, multipleArgs bool) *probe.Error {
 	if err != nil {
 		return err.Trace(clnt.URL().String())
 	}
-	parentContent, err = parentClnt.Stat()
+	parentContent, err = parentClnt.Stat(globalContext)
 	if err != nil {
 		return err.Trace(clnt.URL().String())
 	}
-	for contentCh := range clnt.List(recursive, false) {
+	// Stop listing if we bail out early on an error.
+	ctx, cancel := context.WithCancel(globalContext)
+	defer cancel()
+	for contentCh := range clnt.List(ctx, recursive, false) {
 		if contentCh.Err != nil {
 			switch contentCh.Err.ToGoError().(type) {
 			// handle this specifically for filesystem
@@ -159,11 +163,14 @@ func doList(clnt client.Client, recursive, multipleArgs bool) *probe.Error {
 func doListIncomplete(clnt client.Client, recursive, multipleArgs bool) *probe.Error {
 	var err *probe.Error
 	var parentContent *client.Content
-	parentContent, err = clnt.Stat()
+	parentContent, err = clnt.Stat(globalContext)
 	if err != nil {
 		return err.Trace(clnt.URL().String())
 	}
-	for contentCh := range clnt.List(recursive, true) {
+	// Stop listing if we bail out early on an error.
+	ctx, cancel := context.WithCancel(globalContext)
+	defer cancel()
+	for contentCh := range clnt.List(ctx, recursive, true) {
 		if contentCh.Err != nil {
 			switch contentCh.Err.ToGoError().(type) {
 			// handle this specifically for filesystem
@@ -25,6 +25,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/httptracer"
 	"github.com/minio/minio-xl/pkg/probe"
 	"github.com/minio/pb"
 	"github.com/olekukonko/ts"
@@ -80,6 +81,19 @@ func registerBefore(ctx *cli.Context) error {
 		console.NoDebugPrint = false
 	}
 
+	// Cancel requests in progress on interrupt.
+	trapSignals()
+
+	// Record, replay or trace HTTP requests.
+	setHTTPTraces(ctx)
+
+	// Inject faults for resilience tests.
+	if spec := ctx.GlobalString("faults"); spec != "" {
+		faults, e := httptracer.ParseFaults(spec)
+		fatalIf(probe.NewError(e), "Unable to inject faults.")
+		globalFaults = faults
+	}
+
 	// Set theme.
 	setMainPalette(ctx.GlobalString("colors"))
 
@@ -91,6 +105,9 @@ func registerBefore(ctx *cli.Context) error {
 
 	// Checkconfig if it can be read
 	checkConfig()
+
+	// Check hosts defined in environment variables.
+	checkEnvHosts()
 	return nil
 }
 
@@ -120,12 +137,17 @@ func registerApp() *cli.App {
 	registerCmd(versionCmd) // Print version.
 
 	// register all the flags
-	registerFlag(configFlag) // Path to configuration folder.
-	registerFlag(quietFlag)  // Suppress chatty console output.
-	registerFlag(mimicFlag)  // Behave like operating system tools. Use with shell aliases.
-	registerFlag(jsonFlag)   // Enable json formatted output.
-	registerFlag(debugFlag)  // Enable debugging output.
-	registerFlag(colorsFlag) // Choose different styles of console coloring.
+	registerFlag(configFlag)          // Path to configuration folder.
+	registerFlag(quietFlag)           // Suppress chatty console output.
+	registerFlag(mimicFlag)           // Behave like operating system tools. Use with shell aliases.
+	registerFlag(jsonFlag)            // Enable json formatted output.
+	registerFlag(debugFlag)           // Enable debugging output.
+	registerFlag(colorsFlag)          // Choose different styles of console coloring.
+	registerFlag(recordFlag)          // Record HTTP requests and responses to a cassette.
+	registerFlag(recordBodyLimitFlag) // Truncate recorded bodies.
+	registerFlag(replayFlag)          // Replay HTTP responses from a cassette.
+	registerFlag(traceFileFlag)       // Trace HTTP requests to a HAR file.
+	registerFlag(faultsFlag)          // Inject faults into HTTP requests, hidden.
 
 	app := cli.NewApp()
 	app.Usage = "Minio Client for cloud storage and filesystems."
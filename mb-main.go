@@ -31,20 +31,27 @@ var mbCmd = cli.Command{
 	Name:   "mb",
 	Usage:  "Make a bucket or folder.",
 	Action: mainMakeBucket,
+	Flags:  []cli.Flag{regionFlag},
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
 USAGE:
-   mc {{.Name}} TARGET [TARGET ...]
+   mc {{.Name}} [FLAGS] TARGET [TARGET ...]
 
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
 EXAMPLES:
    1. Create a bucket on Amazon S3 cloud storage.
       $ mc {{.Name}} https://s3.amazonaws.com/public-document-store
 
+   2. Create a bucket in the Frankfurt region of Amazon S3 cloud storage.
+      $ mc {{.Name}} --region eu-central-1 https://s3.amazonaws.com/archive-frankfurt
+
    3. Make a folder on local filesystem with space characters
       $ mc {{.Name}} 'My Documents'
 
-   3. Create a bucket on Minio cloud storage.
+   4. Create a bucket on Minio cloud storage.
       $ mc {{.Name}} https://play.minio.io:9000/mongodb-backup
 `,
 }
@@ -105,7 +112,7 @@ func mainMakeBucket(ctx *cli.Context) {
 	for _, arg := range ctx.Args() {
 		targetURL := getAliasURL(arg, config.Aliases)
 
-		fatalIf(doMakeBucket(targetURL).Trace(targetURL), "Unable to make bucket ‘"+targetURL+"’.")
+		fatalIf(doMakeBucket(targetURL, ctx.String("region")).Trace(targetURL), "Unable to make bucket ‘"+targetURL+"’.")
 		Prints("%s\n", MakeBucketMessage{
 			Status: "success",
 			Bucket: targetURL,
@@ -113,13 +120,20 @@ func mainMakeBucket(ctx *cli.Context) {
 	}
 }
 
-// doMakeBucket -
-func doMakeBucket(targetURL string) *probe.Error {
-	clnt, err := url2Client(targetURL)
+// doMakeBucket - make a bucket at targetURL, in region if set or else in the region of its host.
+func doMakeBucket(targetURL, region string) *probe.Error {
+	hostCfg, err := getHostConfig(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	if region != "" {
+		hostCfg.Region = region
+	}
+	clnt, err := getNewClient(targetURL, hostCfg)
 	if err != nil {
 		return err.Trace(targetURL)
 	}
-	err = clnt.MakeBucket()
+	err = clnt.MakeBucket(globalContext)
 	if err != nil {
 		return err.Trace(targetURL)
 	}
@@ -24,7 +24,7 @@ import (
 )
 
 func (s *TestSuite) TestMbAndAccess(c *C) {
-	perr := doMakeBucket(server.URL + "/bucket")
+	perr := doMakeBucket(server.URL+"/bucket", "")
 	c.Assert(perr, IsNil)
 
 	perr = doSetAccess(server.URL+"/bucket", "public-read-write")
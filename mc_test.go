@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -63,13 +64,22 @@ func (s *TestSuite) SetUpSuite(c *C) {
 	}
 	setMcConfigDir(customConfigDir)
 
+	// Credentials of whoever runs the tests must not leak in.
+	for _, env := range os.Environ() {
+		if name := strings.SplitN(env, "=", 2)[0]; strings.HasPrefix(name, envHostPrefix) || strings.HasPrefix(name, "AWS_") || name == envConfigPassphrase {
+			os.Unsetenv(name)
+		}
+	}
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(tmpDir, "aws-credentials"))
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join(tmpDir, "aws-config"))
+
 	perr := createMcConfigDir()
 	c.Assert(perr, IsNil)
 
 	config, perr := newConfig()
 	c.Assert(perr, IsNil)
 
-	config.Data().(*configV5).Hosts["127.0.0.1:*"] = hostConfig{
+	config.Data().(*configV7).Hosts["127.0.0.1:*"] = hostConfig{
 		AccessKeyID:     "WLGDGYAQYIGI833EV05A",
 		SecretAccessKey: "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF",
 		API:             "S3v4",
@@ -102,9 +112,19 @@ func (s *TestSuite) TestGetNewClient(c *C) {
 	c.Assert(err, IsNil)
 	_, err = getNewClient("pkg/client", hostConfig{})
 	c.Assert(err, IsNil)
+
+	// Transport settings of hosts are checked as clients are built.
+	_, err = getNewClient("https://example.com/bucket1", hostConfig{Proxy: "http://proxy.example.com:3128", ConnectTimeout: "5s", MaxIdleConns: 4})
+	c.Assert(err, IsNil)
+	_, err = getNewClient("https://example.com/bucket1", hostConfig{ReadTimeout: "soon"})
+	c.Assert(err, Not(IsNil))
+	_, err = getNewClient("https://example.com/bucket1", hostConfig{Proxy: "proxy.example.com"})
+	c.Assert(err, Not(IsNil))
+	_, err = getNewClient("https://example.com/bucket1", hostConfig{CABundle: "/nonexistent/ca.pem"})
+	c.Assert(err, Not(IsNil))
 }
 
-func (s *TestSuite) TestNewConfigV5(c *C) {
+func (s *TestSuite) TestNewConfigV7(c *C) {
 	root, err := ioutil.TempDir(os.TempDir(), "mc-")
 	c.Assert(err, IsNil)
 	defer os.RemoveAll(root)
@@ -115,12 +135,12 @@ func (s *TestSuite) TestNewConfigV5(c *C) {
 	perr = conf.Save(configFile)
 	c.Assert(perr, IsNil)
 
-	confNew := newConfigV5()
+	confNew := newConfigV7()
 	config, perr := quick.New(confNew)
 	c.Assert(perr, IsNil)
 	perr = config.Load(configFile)
 	c.Assert(perr, IsNil)
-	data := config.Data().(*configV5)
+	data := config.Data().(*configV7)
 
 	type aliases struct {
 		name string
@@ -321,6 +341,10 @@ func (s *TestSuite) TestVersions(c *C) {
 	v2 := newVersion("1.5.0")
 	c.Assert(v2.LessThan(v1), Equals, true)
 	c.Assert(v1.LessThan(v2), Equals, false)
+	v3 := newVersion("1.13")
+	v4 := newVersion("1.12.17")
+	c.Assert(v4.LessThan(v3), Equals, true)
+	c.Assert(v3.LessThan(v4), Equals, false)
 }
 
 func (s *TestSuite) TestApp(c *C) {
@@ -17,33 +17,32 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
-	"io"
-	"math"
-	"net"
 	"os"
-	"runtime"
-	"sync"
 
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/transfer"
 	"github.com/minio/minio-xl/pkg/probe"
 )
 
-//  Mirror folders recursively from a single source to many destinations
+// Mirror folders recursively from a single source to many destinations
 var mirrorCmd = cli.Command{
 	Name:   "mirror",
 	Usage:  "Mirror folders recursively from a single source to many destinations.",
 	Action: mainMirror,
+	Flags:  []cli.Flag{retryFlag, parallelFlag, limitUploadFlag, limitDownloadFlag},
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
 USAGE:
-   mc {{.Name}} SOURCE TARGET [TARGET...]
+   mc {{.Name}} [FLAGS] SOURCE TARGET [TARGET...]
 
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
 EXAMPLES:
    1. Mirror a bucket recursively from Minio cloud storage to multiple buckets on Amazon S3 cloud storage.
       $ mc {{.Name}} https://play.minio.io:9000/photos/2014 https://s3.amazonaws.com/backup-photos https://s3-west-1.amazonaws.com/local-photos
@@ -59,6 +58,12 @@ EXAMPLES:
 
    5. Mirror a local folder with space characters to Amazon s3 cloud storage
       $ mc {{.Name}} 'workdir/documents/Aug 2015' s3/miniocloud
+
+   6. Mirror a bucket to Minio cloud storage with 32 parallel transfers.
+      $ mc {{.Name}} --parallel 32 s3/documents play/backup
+
+   7. Mirror a bucket to a local folder, limiting download bandwidth to 5MiB/s.
+      $ mc {{.Name}} --limit-download 5MiB/s s3/documents backup/documents
 `,
 }
 
@@ -81,235 +86,20 @@ func (m MirrorMessage) JSON() string {
 	return string(mirrorMessageBytes)
 }
 
-// doMirror - Mirror an object to multiple destination. mirrorURLs status contains a copy of sURLs and error if any.
-func doMirror(sURLs mirrorURLs, progressReader interface{}, mirrorQueueCh <-chan bool, wg *sync.WaitGroup, statusCh chan<- mirrorURLs) {
-	defer wg.Done() // Notify that this copy routine is done.
-	defer func() {
-		<-mirrorQueueCh
-	}()
-
-	if sURLs.Error != nil { // Errorneous sURLs passed.
-		sURLs.Error = sURLs.Error.Trace()
-		statusCh <- sURLs
-		return
-	}
-
-	if !globalQuietFlag && !globalJSONFlag {
-		progressReader.(*barSend).SetCaption(sURLs.SourceContent.Name + ": ")
-	}
-
-	reader, length, err := getSource(sURLs.SourceContent.Name)
-	if err != nil {
-		if !globalQuietFlag && !globalJSONFlag {
-			progressReader.(*barSend).ErrorGet(int64(length))
-		}
-		sURLs.Error = err.Trace(sURLs.SourceContent.Name)
-		statusCh <- sURLs
-		return
-	}
-
-	var targetURLs []string
-	for _, targetContent := range sURLs.TargetContents {
-		targetURLs = append(targetURLs, targetContent.Name)
-	}
-
-	var newReader io.ReadCloser
-	if globalQuietFlag || globalJSONFlag {
-		Prints("%s\n", MirrorMessage{
-			Source:  sURLs.SourceContent.Name,
-			Targets: targetURLs,
-		})
-		newReader = progressReader.(*accounter).NewProxyReader(reader)
-	} else {
-		// set up progress
-		newReader = progressReader.(*barSend).NewProxyReader(reader)
-	}
-	defer newReader.Close()
-
-	err = putTargets(targetURLs, length, newReader)
-	if err != nil {
-		if !globalQuietFlag && !globalJSONFlag {
-			progressReader.(*barSend).ErrorPut(int64(length))
-		}
-		sURLs.Error = err.Trace(targetURLs...)
-		statusCh <- sURLs
-		return
-	}
-
-	sURLs.Error = nil // just for safety
-	statusCh <- sURLs
-}
-
-// doMirrorFake - Perform a fake mirror to update the progress bar appropriately.
-func doMirrorFake(sURLs mirrorURLs, progressReader interface{}) {
-	if !globalDebugFlag && !globalJSONFlag {
-		progressReader.(*barSend).Progress(sURLs.SourceContent.Size)
-	}
-}
-
-// doPrepareMirrorURLs scans the source URL and prepares a list of objects for mirroring.
-func doPrepareMirrorURLs(session *sessionV2, trapCh <-chan bool) {
+// doMirrorSession mirrors the source of session to its targets, resuming the session if it
+// was interrupted.
+func doMirrorSession(session *sessionV2) {
 	sourceURL := session.Header.CommandArgs[0] // first one is source.
 	targetURLs := session.Header.CommandArgs[1:]
-	var totalBytes int64
-	var totalObjects int
 
-	// Create a session data file to store the processed URLs.
-	dataFP := session.NewDataWriter()
-
-	var scanBar scanBarFunc
-	if !globalQuietFlag && !globalJSONFlag { // set up progress bar
-		scanBar = scanBarFactory()
-	}
-
-	URLsCh := prepareMirrorURLs(sourceURL, targetURLs)
-	done := false
-	for done == false {
-		select {
-		case sURLs, ok := <-URLsCh:
-			if !ok { // Done with URL prepration
-				done = true
-				break
-			}
-			if sURLs.Error != nil {
-				// Print in new line and adjust to top so that we don't print over the ongoing scan bar
-				if !globalQuietFlag && !globalJSONFlag {
-					console.Eraseline()
-				}
-				errorIf(sURLs.Error.Trace(), "Unable to prepare URLs for mirroring.")
-				break
-			}
-			if sURLs.isEmpty() {
-				break
-			}
-			jsonData, err := json.Marshal(sURLs)
-			if err != nil {
-				session.Delete()
-				fatalIf(probe.NewError(err), "Unable to marshal URLs into JSON.")
-			}
-			fmt.Fprintln(dataFP, string(jsonData))
-			if !globalQuietFlag && !globalJSONFlag {
-				scanBar(sURLs.SourceContent.Name)
-			}
-
-			totalBytes += sURLs.SourceContent.Size
-			totalObjects++
-		case <-trapCh:
-			// Print in new line and adjust to top so that we don't print over the ongoing scan bar
-			if !globalQuietFlag && !globalJSONFlag {
-				console.Eraseline()
-			}
-			session.Delete() // If we are interrupted during the URL scanning, we drop the session.
-			os.Exit(0)
+	doTransferSession(session, "mirror", "Mirror", func(event transfer.Event) Message {
+		return MirrorMessage{
+			Source:  event.Source,
+			Targets: event.Targets,
 		}
-	}
-	session.Header.TotalBytes = totalBytes
-	session.Header.TotalObjects = totalObjects
-	session.Save()
-}
-
-func doMirrorSession(session *sessionV2) {
-	trapCh := signalTrap(os.Interrupt, os.Kill)
-
-	if !session.HasData() {
-		doPrepareMirrorURLs(session, trapCh)
-	}
-
-	// Set up progress bar.
-	var progressReader interface{}
-	if !globalQuietFlag && !globalJSONFlag {
-		progressReader = newProgressBar(session.Header.TotalBytes)
-	} else {
-		progressReader = newAccounter(session.Header.TotalBytes)
-	}
-
-	// Prepare URL scanner from session data file.
-	scanner := bufio.NewScanner(session.NewDataReader())
-	// isCopied returns true if an object has been already copied
-	// or not. This is useful when we resume from a session.
-	isCopied := isCopiedFactory(session.Header.LastCopied)
-
-	wg := new(sync.WaitGroup)
-	// Limit numner of mirror routines based on available CPU resources.
-	mirrorQueue := make(chan bool, int(math.Max(float64(runtime.NumCPU())-1, 1)))
-	defer close(mirrorQueue)
-	// Status channel for receiveing mirror return status.
-	statusCh := make(chan mirrorURLs)
-
-	// Go routine to monitor doMirror status and signal traps.
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		for {
-			select {
-			case sURLs, ok := <-statusCh: // Receive status.
-				if !ok { // We are done here. Top level function has returned.
-					if !globalQuietFlag && !globalJSONFlag {
-						progressReader.(*barSend).Finish()
-					} else {
-						console.Println(console.Colorize("Mirror", progressReader.(*accounter).Finish()))
-					}
-					return
-				}
-				if sURLs.Error == nil {
-					session.Header.LastCopied = sURLs.SourceContent.Name
-					session.Save()
-				} else {
-					// Print in new line and adjust to top so that we don't print over the ongoing progress bar
-					if !globalQuietFlag && !globalJSONFlag {
-						console.Eraseline()
-					}
-					errorIf(sURLs.Error.Trace(), fmt.Sprintf("Failed to mirror ‘%s’.", sURLs.SourceContent.Name))
-					// all the cases which are handled where session should be saved are contained in the following
-					// switch case, we shouldn't be saving sessions for all errors since some errors might need to be
-					// reported to user properly.
-					//
-					// All other critical cases should be handled properly gracefully
-					// handle more errors and save the session.
-					switch sURLs.Error.ToGoError().(type) {
-					case *net.OpError:
-						gracefulSessionSave(session)
-					case net.Error:
-						gracefulSessionSave(session)
-					}
-				}
-			case <-trapCh: // Receive interrupt notification.
-				// Print in new line and adjust to top so that we don't print over the ongoing progress bar
-				if !globalQuietFlag && !globalJSONFlag {
-					console.Eraseline()
-				}
-				gracefulSessionSave(session)
-			}
-		}
-	}()
-
-	// Go routine to perform concurrently mirroring.
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		mirrorWg := new(sync.WaitGroup)
-		defer close(statusCh)
-
-		for scanner.Scan() {
-			var sURLs mirrorURLs
-			json.Unmarshal([]byte(scanner.Text()), &sURLs)
-			if isCopied(sURLs.SourceContent.Name) {
-				doMirrorFake(sURLs, progressReader)
-			} else {
-				// Wait for other mirror routines to
-				// complete. We only have limited CPU
-				// and network resources.
-				mirrorQueue <- true
-				// Account for each mirror routines we start.
-				mirrorWg.Add(1)
-				// Do mirroring in background concurrently.
-				go doMirror(sURLs, progressReader, mirrorQueue, mirrorWg, statusCh)
-			}
-		}
-		mirrorWg.Wait()
-	}()
-
-	wg.Wait()
+	}, func(opts transfer.Options, events chan<- transfer.Event) *probe.Error {
+		return transfer.Mirror(globalContext, opts, session, sourceURL, targetURLs, events)
+	})
 }
 
 func setMirrorPalette(style string) {
@@ -337,6 +127,8 @@ func mainMirror(ctx *cli.Context) {
 	var e error
 	session := newSessionV2()
 	session.Header.CommandType = "mirror"
+	maxRetries := ctx.Int("retry")
+	session.Header.MaxRetries = &maxRetries
 	session.Header.RootPath, e = os.Getwd()
 	if e != nil {
 		session.Delete()
@@ -351,6 +143,14 @@ func mainMirror(ctx *cli.Context) {
 		fatalIf(err.Trace(ctx.Args()...), fmt.Sprintf("One or more unknown argument types found in ‘%s’.", ctx.Args()))
 	}
 
+	session.Header.Parallel, session.Header.Adaptive, err = getParallel(ctx.String("parallel"))
+	if err != nil {
+		session.Delete()
+		fatalIf(err.Trace(), "Unable to determine number of parallel transfers.")
+	}
+	session.Header.LimitUpload = ctx.String("limit-upload")
+	session.Header.LimitDownload = ctx.String("limit-download")
+
 	doMirrorSession(session)
 	session.Delete()
 }
@@ -18,29 +18,11 @@ package main
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/client"
-	"github.com/minio/minio-xl/pkg/probe"
 )
 
-type mirrorURLs struct {
-	SourceContent  *client.Content
-	TargetContents []*client.Content
-	Error          *probe.Error `json:"-"`
-}
-
-func (m mirrorURLs) isEmpty() bool {
-	if m.SourceContent == nil && len(m.TargetContents) == 0 && m.Error == nil {
-		return true
-	}
-	if m.SourceContent.Size == 0 && len(m.TargetContents) == 0 && m.Error == nil {
-		return true
-	}
-	return false
-}
-
 //
 //   * MIRROR ARGS - VALID CASES
 //   =========================
@@ -48,9 +30,25 @@ func (m mirrorURLs) isEmpty() bool {
 
 // checkMirrorSyntax(URLs []string)
 func checkMirrorSyntax(ctx *cli.Context) {
+	// Arguments are stat'ed several times over while validating them.
+	startStatCache()
+	defer stopStatCache()
+
 	if len(ctx.Args()) < 2 || ctx.Args().First() == "help" {
 		cli.ShowCommandHelpAndExit(ctx, "mirror", 1) // last argument is exit code.
 	}
+	if ctx.Int("retry") < 0 {
+		fatalIf(errInvalidArgument().Trace(), "Number of retries cannot be negative.")
+	}
+	if _, _, err := getParallel(ctx.String("parallel")); err != nil {
+		fatalIf(err.Trace(), "Unable to determine number of parallel transfers.")
+	}
+	if _, err := parseRate(ctx.String("limit-upload")); err != nil {
+		fatalIf(err.Trace(), "Unable to parse upload limit.")
+	}
+	if _, err := parseRate(ctx.String("limit-download")); err != nil {
+		fatalIf(err.Trace(), "Unable to parse download limit.")
+	}
 
 	// extract URLs.
 	URLs, err := args2URLs(ctx.Args())
@@ -81,7 +79,9 @@ func checkMirrorSyntax(ctx *cli.Context) {
 
 		url := client.NewURL(tgtURL)
 		if url.Host != "" {
-			if url.Path == string(url.Separator) {
+			lookup, err := getBucketLookup(tgtURL)
+			fatalIf(err.Trace(tgtURL), fmt.Sprintf("Unable to read configuration of target ‘%s’.", tgtURL))
+			if bucketName, _ := url.BucketAndObject(lookup); bucketName == "" {
 				fatalIf(errInvalidArgument().Trace(), fmt.Sprintf("Target ‘%s’ does not contain bucket name.", tgtURL))
 			}
 		}
@@ -95,125 +95,3 @@ func checkMirrorSyntax(ctx *cli.Context) {
 		}
 	}
 }
-
-func getContent(ch <-chan client.ContentOnChannel) (c *client.Content) {
-	for rv := range ch {
-		if rv.Err != nil {
-			continue
-		}
-		if rv.Content.Type.IsDir() {
-			// ignore directories
-			continue
-		}
-
-		c = rv.Content
-		break
-	}
-
-	return
-}
-
-func getTargetContent(srcContent *client.Content, targetContent *client.Content, targetCh <-chan client.ContentOnChannel) (c *client.Content) {
-	if srcContent == nil {
-		// nothing to do for empty source content
-		return
-	}
-
-	if targetContent == nil {
-		c = getContent(targetCh)
-	} else {
-		c = targetContent
-	}
-
-	for ; c != nil; c = getContent(targetCh) {
-		if srcContent.Name <= c.Name {
-			break
-		}
-	}
-
-	return
-}
-
-func deltaSourceTargets(sourceURL string, targetURLs []string, mirrorURLsCh chan<- mirrorURLs) {
-	defer close(mirrorURLsCh)
-
-	newSourceURL := stripRecursiveURL(sourceURL)
-	if strings.HasSuffix(newSourceURL, "/") == false {
-		newSourceURL = newSourceURL + "/"
-	}
-	sourceClient, err := url2Client(newSourceURL)
-	if err != nil {
-		mirrorURLsCh <- mirrorURLs{Error: err.Trace(sourceURL)}
-		return
-	}
-
-	targetLen := len(targetURLs)
-	newTargetURLs := make([]string, targetLen)
-	targetClients := make([]client.Client, targetLen)
-	for i, targetURL := range targetURLs {
-		targetClient, targetContent, err := url2Stat(targetURL)
-		if err != nil {
-			mirrorURLsCh <- mirrorURLs{Error: err.Trace(targetURL)}
-			return
-		}
-		// targets have to be directory
-		if !targetContent.Type.IsDir() {
-			mirrorURLsCh <- mirrorURLs{Error: errInvalidTarget(targetURL).Trace()}
-			return
-		}
-		// special case, be extremely careful before changing this behavior - will lead to data loss
-		newTargetURL := strings.TrimSuffix(targetURL, string(targetClient.URL().Separator)) + string(targetClient.URL().Separator)
-		targetClient, err = url2Client(newTargetURL)
-		if err != nil {
-			mirrorURLsCh <- mirrorURLs{Error: err.Trace(newTargetURL)}
-			return
-		}
-		targetClients[i] = targetClient
-		newTargetURLs[i] = newTargetURL
-	}
-
-	targetChs := make([]<-chan client.ContentOnChannel, targetLen)
-	for i, targetClient := range targetClients {
-		targetChs[i] = targetClient.List(true, false)
-	}
-	targetContents := make([]*client.Content, targetLen)
-
-	srcCh := sourceClient.List(true, false)
-	for srcContent := getContent(srcCh); srcContent != nil; srcContent = getContent(srcCh) {
-		var mirrorTargets []*client.Content
-		for i := range targetChs {
-			targetContents[i] = getTargetContent(srcContent, targetContents[i], targetChs[i])
-
-			// either target reached EOF or target does not have source content
-			if targetContents[i] == nil || srcContent.Name != targetContents[i].Name {
-				mirrorTargets = append(mirrorTargets, &client.Content{Name: newTargetURLs[i] + srcContent.Name})
-				continue
-			}
-
-			// source and target have same content
-			if srcContent.Type.IsRegular() && targetContents[i].Type.IsRegular() {
-				// but size mismatches
-				if srcContent.Size != targetContents[i].Size {
-					mirrorTargets = append(mirrorTargets, &client.Content{Name: newTargetURLs[i] + srcContent.Name})
-				}
-				continue
-			}
-
-			// source and target have different content type
-			// TODO: add error
-		}
-		if len(mirrorTargets) > 0 {
-			srcContent.Name = newSourceURL + srcContent.Name
-			mirrorURLsCh <- mirrorURLs{
-				SourceContent:  srcContent,
-				TargetContents: mirrorTargets,
-			}
-		}
-	}
-}
-
-func prepareMirrorURLs(sourceURL string, targetURLs []string) <-chan mirrorURLs {
-	mirrorURLsCh := make(chan mirrorURLs)
-	go deltaSourceTargets(sourceURL, targetURLs, mirrorURLsCh)
-	return mirrorURLsCh
-}
@@ -0,0 +1,61 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/minio/mc/pkg/transfer"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// ‘--parallel auto’ adapts number of transfers to measured throughput.
+const parallelAuto = "auto"
+
+// parseParallel parses ‘--parallel’ and config file values, which are either a
+// positive number or ‘auto’. An empty value picks the default.
+func parseParallel(value string) (parallel int, adaptive bool, err *probe.Error) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "":
+		return transfer.DefaultParallel(), false, nil
+	case parallelAuto:
+		return transfer.DefaultParallel(), true, nil
+	}
+	parallel, e := strconv.Atoi(value)
+	if e != nil {
+		return 0, false, errInvalidParallel(value).Trace()
+	}
+	if parallel < 1 || parallel > transfer.MaxParallel {
+		return 0, false, errInvalidParallel(value).Trace()
+	}
+	return parallel, false, nil
+}
+
+// getParallel returns number of transfers and whether to adapt them, from
+// ‘--parallel’ or else from the config file.
+func getParallel(flagValue string) (parallel int, adaptive bool, err *probe.Error) {
+	if strings.TrimSpace(flagValue) == "" {
+		config, err := getMcConfig()
+		if err != nil {
+			return 0, false, err.Trace()
+		}
+		flagValue = config.Parallel
+	}
+	return parseParallel(flagValue)
+}
@@ -0,0 +1,46 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/minio/mc/pkg/transfer"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestParseParallel(c *C) {
+	parallel, adaptive, perr := parseParallel("")
+	c.Assert(perr, IsNil)
+	c.Assert(parallel, Equals, transfer.DefaultParallel())
+	c.Assert(adaptive, Equals, false)
+
+	parallel, adaptive, perr = parseParallel("16")
+	c.Assert(perr, IsNil)
+	c.Assert(parallel, Equals, 16)
+	c.Assert(adaptive, Equals, false)
+
+	_, adaptive, perr = parseParallel("auto")
+	c.Assert(perr, IsNil)
+	c.Assert(adaptive, Equals, true)
+
+	_, _, perr = parseParallel("0")
+	c.Assert(perr, Not(IsNil))
+	_, _, perr = parseParallel("-1")
+	c.Assert(perr, Not(IsNil))
+	_, _, perr = parseParallel("many")
+	c.Assert(perr, Not(IsNil))
+}
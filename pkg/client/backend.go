@@ -0,0 +1,114 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Backend - a storage backend, backends register themselves from the init function
+// of their package. Compiling one in only takes a blank import of its package.
+type Backend struct {
+	// API names the backend in ‘api’ field of host configuration, such as ‘S3v4’.
+	API string
+	// Schemes served by the backend when host configuration does not name an API,
+	// local filesystem paths have an empty scheme.
+	Schemes []string
+	// HostConfig is true if URLs need a matching host in configuration, for credentials.
+	HostConfig bool
+	// New instantiates a client for config.HostURL.
+	New func(config *Config) (Client, *probe.Error)
+}
+
+// backends holds registered backends by API name and by scheme. URLs of object
+// storage schemes are parsed as scheme://host/path, http and https always are.
+var backends = struct {
+	sync.RWMutex
+	byAPI         map[string]Backend
+	byScheme      map[string]Backend
+	objectSchemes map[string]bool
+}{
+	byAPI:         make(map[string]Backend),
+	byScheme:      make(map[string]Backend),
+	objectSchemes: map[string]bool{"http": true, "https": true},
+}
+
+// RegisterBackend - make backend available to URLs of its schemes and to hosts naming its API.
+// It panics if the API name or a scheme is taken, since that is a programming error.
+func RegisterBackend(backend Backend) {
+	backends.Lock()
+	defer backends.Unlock()
+	if backend.API == "" || backend.New == nil {
+		panic("client: backend needs an API name and a constructor")
+	}
+	if _, ok := backends.byAPI[backend.API]; ok {
+		panic("client: backend ‘" + backend.API + "’ registered twice")
+	}
+	for _, scheme := range backend.Schemes {
+		if _, ok := backends.byScheme[scheme]; ok {
+			panic("client: scheme ‘" + scheme + "’ registered twice")
+		}
+	}
+	backends.byAPI[backend.API] = backend
+	for _, scheme := range backend.Schemes {
+		backends.byScheme[scheme] = backend
+		if scheme != "" {
+			backends.objectSchemes[scheme] = true
+		}
+	}
+}
+
+// LookupBackend returns the backend for a URL, preferring api if registered over the URL's scheme.
+func LookupBackend(url *URL, api string) (Backend, bool) {
+	backends.RLock()
+	defer backends.RUnlock()
+	if backend, ok := backends.byAPI[api]; ok {
+		return backend, true
+	}
+	backend, ok := backends.byScheme[url.Scheme]
+	return backend, ok
+}
+
+// IsBackendAPI returns true if a backend is registered for api.
+func IsBackendAPI(api string) bool {
+	backends.RLock()
+	defer backends.RUnlock()
+	_, ok := backends.byAPI[api]
+	return ok
+}
+
+// BackendAPIs returns sorted API names of all registered backends.
+func BackendAPIs() []string {
+	backends.RLock()
+	defer backends.RUnlock()
+	var apis []string
+	for api := range backends.byAPI {
+		apis = append(apis, api)
+	}
+	sort.Strings(apis)
+	return apis
+}
+
+// isObjectScheme returns true if URLs of scheme are object storage URLs.
+func isObjectScheme(scheme string) bool {
+	backends.RLock()
+	defer backends.RUnlock()
+	return backends.objectSchemes[scheme]
+}
@@ -0,0 +1,57 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"github.com/minio/minio-xl/pkg/probe"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestRegisterBackend(c *C) {
+	c.Assert(NewURL("registry://bucket/object").Type, Equals, URLType(Filesystem))
+
+	RegisterBackend(Backend{
+		API:        "registry-test",
+		Schemes:    []string{"registry"},
+		HostConfig: true,
+		New: func(config *Config) (Client, *probe.Error) {
+			return nil, nil
+		},
+	})
+	u := NewURL("registry://bucket/object")
+	c.Assert(u.Type, Equals, URLType(Object))
+	c.Assert(u.Host, Equals, "bucket")
+	c.Assert(u.Path, Equals, "/object")
+
+	backend, ok := LookupBackend(u, "")
+	c.Assert(ok, Equals, true)
+	c.Assert(backend.API, Equals, "registry-test")
+	// API name in host config wins over scheme.
+	backend, ok = LookupBackend(NewURL("http://s3.example.com"), "registry-test")
+	c.Assert(ok, Equals, true)
+	c.Assert(backend.API, Equals, "registry-test")
+
+	_, ok = LookupBackend(NewURL("unknown://bucket"), "")
+	c.Assert(ok, Equals, false)
+
+	c.Assert(IsBackendAPI("registry-test"), Equals, true)
+	c.Assert(BackendAPIs(), DeepEquals, []string{"registry-test"})
+
+	c.Assert(func() {
+		RegisterBackend(Backend{API: "registry-test", New: backend.New})
+	}, PanicMatches, ".*registered twice")
+}
@@ -17,34 +17,36 @@
 package client
 
 import (
+	"context"
 	"io"
 	"os"
 	"time"
 
+	"github.com/minio/mc/pkg/httptracer"
 	"github.com/minio/minio-xl/pkg/probe"
 )
 
-// Client - client interface
+// Client - client interface, operations which talk to the backend are aborted once ctx is done.
 type Client interface {
 	// Common operations
-	Stat() (content *Content, err *probe.Error)
-	List(recursive, incomplete bool) <-chan ContentOnChannel
+	Stat(ctx context.Context) (content *Content, err *probe.Error)
+	List(ctx context.Context, recursive, incomplete bool) <-chan ContentOnChannel
 
 	// Bucket operations
-	MakeBucket() *probe.Error
-	GetBucketAccess() (access string, error *probe.Error)
-	SetBucketAccess(access string) *probe.Error
+	MakeBucket(ctx context.Context) *probe.Error
+	GetBucketAccess(ctx context.Context) (access string, error *probe.Error)
+	SetBucketAccess(ctx context.Context, access string) *probe.Error
 
 	// I/O operations
-	Get(offset, length int64) (body io.ReadCloser, size int64, err *probe.Error)
-	Put(size int64, data io.Reader) *probe.Error
+	Get(ctx context.Context, offset, length int64) (body io.ReadCloser, size int64, err *probe.Error)
+	Put(ctx context.Context, size int64, data io.Reader) *probe.Error
 
 	// I/O operations with expiration
 	ShareDownload(expires time.Duration) (string, *probe.Error)
 	ShareUpload(bool, time.Duration, string) (map[string]string, *probe.Error)
 
 	// Delete operations
-	Remove(incomplete bool) *probe.Error
+	Remove(ctx context.Context, incomplete bool) *probe.Error
 
 	// URL returns back internal url
 	URL() *URL
@@ -73,4 +75,30 @@ type Config struct {
 	AppVersion      string
 	AppComments     []string
 	Debug           bool
+	// SessionToken of temporary credentials, empty for long-term ones.
+	SessionToken string
+	// Refresh returns current credentials, for hosts whose credentials are rotated while mc runs.
+	Refresh RefreshFunc
+	// RequestsPerSecond limits requests sent to the host, 0 means unlimited.
+	RequestsPerSecond float64
+	// Timeout bounds metadata operations and waiting for response headers, 0 means no timeout.
+	Timeout time.Duration
+	// Transport holds TLS, proxy and connection settings of the host.
+	Transport TransportOptions
+	// Region requests are signed for, else it is guessed from the host or discovered per bucket.
+	Region string
+	// Lookup is the bucket lookup style of the host, one of LookupAuto, LookupPath or LookupDNS.
+	Lookup string
+	// ClockOffset is added to the local clock when dating requests, to make up for a skewed clock.
+	ClockOffset time.Duration
+	// AutoClockOffset takes the offset from the skew of the host's clock instead, see DetectClockSkew.
+	AutoClockOffset bool
+	// Record saves requests sent to the host and their responses to a cassette, if set.
+	Record *httptracer.Recorder
+	// Replay serves responses from a cassette instead of sending requests to the host, if set.
+	Replay *httptracer.Replayer
+	// Faults are injected into requests sent to the host, if set.
+	Faults *httptracer.Faults
+	// HAR traces requests sent to the host and their responses to a HAR file, if set.
+	HAR *httptracer.HAR
 }
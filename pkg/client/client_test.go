@@ -67,3 +67,25 @@ func (s *MySuite) TestPathParse(c *C) {
 	c.Assert(u.Path, Equals, "/path/test")
 	c.Assert(u.SchemeSeparator, Equals, "")
 }
+
+func (s *MySuite) TestBucketAndObject(c *C) {
+	for _, t := range []struct {
+		url, lookup    string
+		bucket, object string
+	}{
+		{"https://s3.amazonaws.com/bucket/dir/object", "", "bucket", "dir/object"},
+		{"https://bucket.s3.amazonaws.com/dir/object", "", "bucket", "dir/object"},
+		{"https://bucket.s3.amazonaws.com/dir/object", LookupPath, "dir", "object"},
+		{"https://bucket.storage.example.com/object", LookupAuto, "object", ""},
+		{"https://bucket.storage.example.com/object", LookupDNS, "bucket", "object"},
+		{"https://bucket.storage.example.com:9000/", LookupDNS, "bucket", ""},
+		{"https://storage.example.com/", LookupPath, "", ""},
+	} {
+		bucket, object := NewURL(t.url).BucketAndObject(t.lookup)
+		c.Assert(bucket, Equals, t.bucket, Commentf("%s %s", t.url, t.lookup))
+		c.Assert(object, Equals, t.object, Commentf("%s %s", t.url, t.lookup))
+	}
+	c.Assert(IsValidLookup(""), Equals, true)
+	c.Assert(IsValidLookup(LookupDNS), Equals, true)
+	c.Assert(IsValidLookup("virtual"), Equals, false)
+}
@@ -0,0 +1,101 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// clockSkews holds how far clocks of hosts are ahead of the local one, by host.
+var clockSkews = struct {
+	sync.Mutex
+	skews map[string]time.Duration
+}{skews: make(map[string]time.Duration)}
+
+// ClockSkew returns how far the clock of host is ahead of the local one, as told by the Date of
+// its last error reply, and whether one was seen.
+func ClockSkew(host string) (time.Duration, bool) {
+	clockSkews.Lock()
+	defer clockSkews.Unlock()
+	skew, ok := clockSkews.skews[host]
+	return skew, ok
+}
+
+// recordClockSkew records the skew of host from the Date of resp, whose request was sent at
+// sent. It returns false if resp is not dated.
+func recordClockSkew(host string, resp *http.Response, sent time.Time) bool {
+	date, e := http.ParseTime(resp.Header.Get("Date"))
+	if e != nil {
+		return false
+	}
+	// Dates are in seconds, compare them to the local time half way through the request.
+	local := sent.Add(time.Since(sent) / 2)
+	clockSkews.Lock()
+	clockSkews.skews[host] = date.Sub(local).Round(time.Second)
+	clockSkews.Unlock()
+	return true
+}
+
+// clockTransport records the clock skew of hosts from the Date of their error replies.
+type clockTransport struct {
+	transport http.RoundTripper
+}
+
+// RoundTrip sends the request, recording the clock skew of its host if it fails.
+func (t clockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	sent := time.Now()
+	resp, err := t.transport.RoundTrip(req)
+	if err == nil && resp.StatusCode >= http.StatusBadRequest {
+		recordClockSkew(req.URL.Host, resp, sent)
+	}
+	return resp, err
+}
+
+// NewClockTransport records the clock skew of hosts replying with errors through transport,
+// see ClockSkew.
+func NewClockTransport(transport http.RoundTripper) http.RoundTripper {
+	return clockTransport{transport: transport}
+}
+
+// DetectClockSkew returns how far the clock of the host of urlStr is ahead of the local one,
+// sending it an unsigned request through transport unless it replied with an error already.
+// Hosts which cannot be reached or do not date their replies are taken to have no skew.
+func DetectClockSkew(transport http.RoundTripper, urlStr string) time.Duration {
+	req, e := http.NewRequest("HEAD", urlStr, nil)
+	if e != nil {
+		return 0
+	}
+	if skew, ok := ClockSkew(req.URL.Host); ok {
+		return skew
+	}
+	sent := time.Now()
+	resp, e := transport.RoundTrip(req)
+	if e == nil {
+		resp.Body.Close()
+		if recordClockSkew(req.URL.Host, resp, sent) {
+			skew, _ := ClockSkew(req.URL.Host)
+			return skew
+		}
+	}
+	// Not probed again.
+	clockSkews.Lock()
+	clockSkews.skews[req.URL.Host] = 0
+	clockSkews.Unlock()
+	return 0
+}
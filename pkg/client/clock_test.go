@@ -0,0 +1,60 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestClockSkew(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		if r.URL.Path == "/denied" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	// Successful replies tell nothing.
+	transport := NewClockTransport(http.DefaultTransport)
+	resp, e := (&http.Client{Transport: transport}).Get(server.URL + "/")
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	_, ok := ClockSkew(host)
+	c.Assert(ok, Equals, false)
+
+	resp, e = (&http.Client{Transport: transport}).Get(server.URL + "/denied")
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	skew, ok := ClockSkew(host)
+	c.Assert(ok, Equals, true)
+	c.Assert(skew > 59*time.Minute && skew < 61*time.Minute, Equals, true)
+	c.Assert(DetectClockSkew(http.DefaultTransport, server.URL+"/"), Equals, skew)
+
+	// Hosts are probed once, with any reply.
+	other := httptest.NewServer(server.Config.Handler)
+	skew = DetectClockSkew(http.DefaultTransport, other.URL+"/")
+	c.Assert(skew > 59*time.Minute && skew < 61*time.Minute, Equals, true)
+	other.Close()
+	c.Assert(DetectClockSkew(http.DefaultTransport, other.URL+"/"), Equals, skew)
+}
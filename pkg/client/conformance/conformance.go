@@ -0,0 +1,316 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package conformance checks client.Client implementations for the behaviour mc relies
+// on: listing order, prefix semantics, range reads, typed errors and incomplete uploads.
+// Known differences of an implementation are declared as Quirks, so that they are
+// documented in one place rather than discovered by users. Register a suite per
+// implementation from its tests:
+//
+//	var _ = check.Suite(&conformance.Suite{Target: conformance.Target{...}})
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+
+	"gopkg.in/check.v1"
+)
+
+// Quirks - known differences from S3 semantics, which the suite is told to accept.
+type Quirks struct {
+	// UnsortedList - non-recursive listings are in no particular order.
+	UnsortedList bool
+	// WalkOrder - recursive listings visit contents of a folder before names sorting
+	// after it, as filepath.Walk does, rather than in lexical order.
+	WalkOrder bool
+	// RecursiveFolders - recursive listings include folders along with objects.
+	RecursiveFolders bool
+	// NoIncomplete - failed uploads are not kept as incomplete ones, partial objects
+	// may be left in their place.
+	NoIncomplete bool
+	// NoAccess - bucket access is not implemented.
+	NoAccess bool
+}
+
+// Target - a client.Client implementation under test.
+type Target struct {
+	// NewClient returns a client for a URL.
+	NewClient func(urlStr string) (client.Client, *probe.Error)
+	// NewRoot returns URL of a new empty folder, a bucket for object storage, along
+	// with a function removing it.
+	NewRoot func(c *check.C) (rootURL string, cleanup func())
+	// Separator of paths under root URL.
+	Separator string
+	Quirks    Quirks
+}
+
+// Suite - conformance checks of Target, one root is created for every check.
+type Suite struct {
+	Target  Target
+	root    string
+	cleanup func()
+}
+
+// SetUpTest creates a root for a check.
+func (s *Suite) SetUpTest(c *check.C) {
+	s.root, s.cleanup = s.Target.NewRoot(c)
+}
+
+// TearDownTest removes root of a check.
+func (s *Suite) TearDownTest(c *check.C) {
+	s.cleanup()
+}
+
+// url returns URL of path under root.
+func (s *Suite) url(path string) string {
+	return strings.TrimSuffix(s.root, s.Target.Separator) + s.Target.Separator + path
+}
+
+// client returns a client for path under root.
+func (s *Suite) client(c *check.C, path string) client.Client {
+	clnt, err := s.Target.NewClient(s.url(path))
+	c.Assert(err, check.IsNil)
+	return clnt
+}
+
+// put creates objects under root, named as their data.
+func (s *Suite) put(c *check.C, names ...string) {
+	for _, name := range names {
+		err := s.client(c, name).Put(context.Background(), int64(len(name)), bytes.NewReader([]byte(name)))
+		c.Assert(err, check.IsNil)
+	}
+}
+
+// list returns names listed at path, folders without their trailing separator and
+// tagged with ‘/’ in non-recursive listings.
+func (s *Suite) list(c *check.C, path string, recursive, incomplete bool) []string {
+	var names []string
+	for content := range s.client(c, path).List(context.Background(), recursive, incomplete) {
+		c.Assert(content.Err, check.IsNil)
+		name := strings.TrimSuffix(content.Content.Name, s.Target.Separator)
+		if content.Content.Type.IsDir() {
+			if recursive {
+				c.Assert(s.Target.Quirks.RecursiveFolders, check.Equals, true, check.Commentf("folder ‘%s’ in recursive listing", name))
+				continue
+			}
+			name += "/"
+		}
+		names = append(names, strings.Replace(name, s.Target.Separator, "/", -1))
+	}
+	return names
+}
+
+// isNotFound - true for the typed errors of missing objects and files.
+func isNotFound(err *probe.Error) bool {
+	if err == nil {
+		return false
+	}
+	switch err.ToGoError().(type) {
+	case client.ObjectNotFound, client.NotFound:
+		return true
+	}
+	return false
+}
+
+// TestPutGetStat - objects read back as written.
+func (s *Suite) TestPutGetStat(c *check.C) {
+	s.put(c, "object")
+	content, err := s.client(c, "object").Stat(context.Background())
+	c.Assert(err, check.IsNil)
+	c.Assert(content.Type.IsRegular(), check.Equals, true)
+	c.Assert(content.Size, check.Equals, int64(len("object")))
+
+	reader, size, err := s.client(c, "object").Get(context.Background(), 0, 0)
+	c.Assert(err, check.IsNil)
+	defer reader.Close()
+	c.Assert(size, check.Equals, int64(len("object")))
+	data, e := ioutil.ReadAll(reader)
+	c.Assert(e, check.IsNil)
+	c.Assert(string(data), check.Equals, "object")
+}
+
+// TestRangeRead - Get returns exactly the range asked for, length 0 reads till the end.
+func (s *Suite) TestRangeRead(c *check.C) {
+	s.put(c, "hello world")
+	for _, r := range []struct {
+		offset, length int64
+		data           string
+	}{
+		{0, 5, "hello"},
+		{6, 0, "world"},
+		{2, 3, "llo"},
+		{6, 100, "world"},
+	} {
+		reader, size, err := s.client(c, "hello world").Get(context.Background(), r.offset, r.length)
+		c.Assert(err, check.IsNil)
+		data, e := ioutil.ReadAll(reader)
+		reader.Close()
+		c.Assert(e, check.IsNil)
+		c.Assert(string(data), check.Equals, r.data, check.Commentf("offset %d, length %d", r.offset, r.length))
+		c.Assert(size, check.Equals, int64(len(r.data)))
+	}
+
+	// Ranges starting at the end or past it are invalid.
+	for _, offset := range []int64{int64(len("hello world")), 100} {
+		_, _, err := s.client(c, "hello world").Get(context.Background(), offset, 0)
+		c.Assert(err, check.Not(check.IsNil), check.Commentf("offset %d", offset))
+		c.Assert(err.ToGoError(), check.FitsTypeOf, client.InvalidRange{}, check.Commentf("offset %d", offset))
+	}
+}
+
+// TestNotFound - missing objects fail with typed errors, for callers to tell them apart.
+func (s *Suite) TestNotFound(c *check.C) {
+	_, err := s.client(c, "missing").Stat(context.Background())
+	c.Assert(isNotFound(err), check.Equals, true, check.Commentf("%v", err))
+	_, _, err = s.client(c, "missing").Get(context.Background(), 0, 0)
+	c.Assert(isNotFound(err), check.Equals, true, check.Commentf("%v", err))
+}
+
+// TestRemove - removed objects are gone.
+func (s *Suite) TestRemove(c *check.C) {
+	s.put(c, "object")
+	c.Assert(s.client(c, "object").Remove(context.Background(), false), check.IsNil)
+	_, err := s.client(c, "object").Stat(context.Background())
+	c.Assert(isNotFound(err), check.Equals, true, check.Commentf("%v", err))
+}
+
+// TestListOrder - listings are in lexical order, as mirror merges them on that assumption.
+func (s *Suite) TestListOrder(c *check.C) {
+	s.put(c, "b", "dir/sub/c", "dir/a", "dir-x", "a")
+
+	names := s.list(c, "", false, false)
+	if s.Target.Quirks.UnsortedList {
+		sort.Strings(names)
+	}
+	c.Assert(names, check.DeepEquals, []string{"a", "b", "dir-x", "dir/"})
+
+	names = s.list(c, "", true, false)
+	expected := []string{"a", "b", "dir-x", "dir/a", "dir/sub/c"}
+	if s.Target.Quirks.WalkOrder {
+		// ‘dir’ is visited before ‘dir-x’, although ‘-’ sorts before ‘/’.
+		expected = []string{"a", "b", "dir/a", "dir/sub/c", "dir-x"}
+	}
+	c.Assert(names, check.DeepEquals, expected)
+}
+
+// TestPrefix - names followed by a separator are folders, partial names are not.
+func (s *Suite) TestPrefix(c *check.C) {
+	s.put(c, "dir/a", "dir/sub/c", "dir-x")
+
+	content, err := s.client(c, "dir").Stat(context.Background())
+	c.Assert(err, check.IsNil)
+	c.Assert(content.Type.IsDir(), check.Equals, true)
+	_, err = s.client(c, "di").Stat(context.Background())
+	c.Assert(isNotFound(err), check.Equals, true, check.Commentf("%v", err))
+
+	names := s.list(c, "dir"+s.Target.Separator, false, false)
+	if s.Target.Quirks.UnsortedList {
+		sort.Strings(names)
+	}
+	c.Assert(names, check.DeepEquals, []string{"a", "sub/"})
+
+	// Recursive listings are relative to the folder of the URL, a partial name matches as a prefix.
+	c.Assert(s.list(c, "dir"+s.Target.Separator, true, false), check.DeepEquals, []string{"a", "sub/c"})
+	names = s.list(c, "dir", true, false)
+	sort.Strings(names)
+	c.Assert(names, check.DeepEquals, []string{"dir-x", "dir/a", "dir/sub/c"})
+}
+
+// shortReader fails with io.ErrUnexpectedEOF after data.
+type shortReader struct {
+	reader io.Reader
+}
+
+func (r shortReader) Read(p []byte) (int, error) {
+	n, e := r.reader.Read(p)
+	if e == io.EOF {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, e
+}
+
+// partSize - size from which uploads are multipart, as with S3 clients.
+const partSize = 5 * 1024 * 1024
+
+// TestIncompleteUpload - failed uploads fail Put and leave no object. Multipart uploads are
+// listed as incomplete and removed as such, smaller ones leave nothing behind.
+func (s *Suite) TestIncompleteUpload(c *check.C) {
+	err := s.client(c, "small").Put(context.Background(), 10, shortReader{bytes.NewReader([]byte("hello"))})
+	c.Assert(err, check.Not(check.IsNil))
+	if s.Target.Quirks.NoIncomplete {
+		return
+	}
+	_, err = s.client(c, "small").Stat(context.Background())
+	c.Assert(isNotFound(err), check.Equals, true, check.Commentf("%v", err))
+	c.Assert(s.list(c, "", false, true), check.IsNil)
+
+	// Failing before a part is read leaves the upload initiated.
+	err = s.client(c, "large").Put(context.Background(), partSize, shortReader{bytes.NewReader(nil)})
+	c.Assert(err, check.Not(check.IsNil))
+	_, err = s.client(c, "large").Stat(context.Background())
+	c.Assert(isNotFound(err), check.Equals, true, check.Commentf("%v", err))
+	c.Assert(s.list(c, "", false, true), check.DeepEquals, []string{"large"})
+	c.Assert(s.client(c, "large").Remove(context.Background(), true), check.IsNil)
+	c.Assert(s.list(c, "", false, true), check.IsNil)
+}
+
+// TestAccess - canned access policies of a bucket read back as set.
+func (s *Suite) TestAccess(c *check.C) {
+	root := s.client(c, "")
+	if s.Target.Quirks.NoAccess {
+		_, err := root.GetBucketAccess(context.Background())
+		c.Assert(err.ToGoError(), check.FitsTypeOf, client.APINotImplemented{})
+		return
+	}
+	c.Assert(root.SetBucketAccess(context.Background(), "public-read"), check.IsNil)
+	access, err := root.GetBucketAccess(context.Background())
+	c.Assert(err, check.IsNil)
+	c.Assert(access, check.Equals, "public-read")
+	err = root.SetBucketAccess(context.Background(), "everyone")
+	c.Assert(err, check.Not(check.IsNil))
+}
+
+// TestCancel - operations bound to a cancelled context fail, listings end.
+func (s *Suite) TestCancel(c *check.C) {
+	s.put(c, "a", "b")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for content := range s.client(c, "").List(ctx, true, false) {
+		_ = content
+	}
+	err := s.client(c, "c").Put(ctx, 1, bytes.NewReader([]byte("c")))
+	c.Assert(err, check.Not(check.IsNil))
+	c.Assert(err.ToGoError(), check.Equals, context.Canceled)
+	if !s.Target.Quirks.NoIncomplete {
+		_, err = s.client(c, "c").Stat(context.Background())
+		c.Assert(isNotFound(err), check.Equals, true, check.Commentf("%v", err))
+	}
+}
+
+// TestNames - names are preserved, including spaces and non ASCII characters.
+func (s *Suite) TestNames(c *check.C) {
+	names := []string{"with space", "本語"}
+	s.put(c, names...)
+	c.Assert(s.list(c, "", true, false), check.DeepEquals, names)
+}
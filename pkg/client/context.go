@@ -0,0 +1,91 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// ContextTransport - transport whose requests are tied to the context it is bound to, for
+// clients such as minio-go which take no context of their own. A client built on it serves
+// operations with different contexts one after the other, each binding it first.
+type ContextTransport struct {
+	transport http.RoundTripper
+
+	mutex sync.Mutex
+	ctx   context.Context
+}
+
+// NewContextTransport - requests sent through the returned transport are aborted once the
+// context it is bound to is done, including reading their response body.
+func NewContextTransport(transport http.RoundTripper) *ContextTransport {
+	return &ContextTransport{transport: transport, ctx: context.Background()}
+}
+
+// Bind ties requests sent from now on to ctx. Requests already sent stay tied to the context
+// they were sent with.
+func (t *ContextTransport) Bind(ctx context.Context) {
+	t.mutex.Lock()
+	t.ctx = ctx
+	t.mutex.Unlock()
+}
+
+// RoundTrip sends the request, aborting it once the bound context is done.
+func (t *ContextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mutex.Lock()
+	ctx := t.ctx
+	t.mutex.Unlock()
+	return t.transport.RoundTrip(req.WithContext(ctx))
+}
+
+// ContextError returns err as seen by the caller of an operation bound to ctx. Requests
+// aborted because ctx is done fail with transport errors, which must not be mistaken for
+// network errors worth retrying.
+func ContextError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return OperationTimeout{}
+	case context.Canceled:
+		return context.Canceled
+	}
+	return err
+}
+
+// ForwardContents forwards contents from listCh on to the returned channel until ctx is
+// done. listCh is drained from then on, so that the listing goroutine is never blocked on
+// a consumer which went away. Consumers are expected to stop reading once they cancel ctx.
+func ForwardContents(ctx context.Context, listCh <-chan ContentOnChannel) <-chan ContentOnChannel {
+	contentCh := make(chan ContentOnChannel)
+	go func() {
+		defer close(contentCh)
+		for content := range listCh {
+			select {
+			case contentCh <- content:
+			case <-ctx.Done():
+				for range listCh {
+				}
+				return
+			}
+		}
+	}()
+	return contentCh
+}
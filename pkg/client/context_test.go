@@ -0,0 +1,94 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestContextError(c *C) {
+	err := errors.New("connection reset by peer")
+	c.Assert(ContextError(context.Background(), nil), IsNil)
+	c.Assert(ContextError(context.Background(), err), Equals, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.Assert(ContextError(ctx, err), Equals, context.Canceled)
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	c.Assert(ContextError(ctx, err), Equals, OperationTimeout{})
+}
+
+func (s *MySuite) TestForwardContents(c *C) {
+	listCh := make(chan ContentOnChannel)
+	listDone := make(chan struct{})
+	go func() {
+		defer close(listDone)
+		defer close(listCh)
+		for i := 0; i < 100; i++ {
+			listCh <- ContentOnChannel{Content: &Content{}}
+		}
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	contentCh := ForwardContents(ctx, listCh)
+	<-contentCh
+	// Consumer gives up, listing must not be left blocked.
+	cancel()
+	select {
+	case <-listDone:
+	case <-time.After(5 * time.Second):
+		c.Fatal("listing was left blocked after its consumer went away")
+	}
+}
+
+func (s *MySuite) TestContextTransport(c *C) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("block") != "" {
+			<-block
+		}
+	}))
+	defer server.Close()
+	defer close(block)
+
+	transport := NewContextTransport(http.DefaultTransport)
+	httpClient := &http.Client{Transport: transport}
+	ctx, cancel := context.WithCancel(context.Background())
+	transport.Bind(ctx)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	start := time.Now()
+	_, err := httpClient.Get(server.URL + "?block=1")
+	c.Assert(err, Not(IsNil))
+	c.Assert(time.Since(start) < 5*time.Second, Equals, true)
+
+	// Bound to the next operation, requests go through again.
+	transport.Bind(context.Background())
+	resp, err := httpClient.Get(server.URL)
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+}
@@ -0,0 +1,89 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/console"
+)
+
+// Credentials - access keys, with a session token for temporary credentials.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Expiration of temporary credentials, zero if they do not expire.
+	Expiration time.Time
+}
+
+// RefreshFunc returns current credentials of a host, which may be rotated while mc runs.
+type RefreshFunc func() (Credentials, error)
+
+// credentials refresh related constants.
+const (
+	// Credentials without expiration are refreshed this often, to pick up rotated ones before
+	// retries of requests which failed with them run out.
+	refreshInterval = 10 * time.Second
+
+	// Temporary credentials are refreshed this long before they expire.
+	expiryWindow = time.Minute
+)
+
+// CredentialsCache holds credentials of a host, refreshed when due.
+type CredentialsCache struct {
+	mutex     sync.Mutex
+	refresh   RefreshFunc
+	creds     Credentials
+	refreshAt time.Time // zero until first refreshed.
+}
+
+// NewCredentialsCache returns a cache of credentials, starting with creds.
+func NewCredentialsCache(creds Credentials, refresh RefreshFunc) *CredentialsCache {
+	cache := &CredentialsCache{refresh: refresh, creds: creds}
+	cache.refreshAt = cache.nextRefresh(time.Now())
+	return cache
+}
+
+// nextRefresh returns when credentials are due for a refresh.
+func (c *CredentialsCache) nextRefresh(now time.Time) time.Time {
+	if !c.creds.Expiration.IsZero() {
+		return c.creds.Expiration.Add(-expiryWindow)
+	}
+	return now.Add(refreshInterval)
+}
+
+// Get returns current credentials, refreshed if they are due. Credentials which fail to
+// refresh are kept until the next attempt, requests then fail on their own.
+func (c *CredentialsCache) Get() Credentials {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := time.Now()
+	if now.Before(c.refreshAt) {
+		return c.creds
+	}
+	creds, err := c.refresh()
+	if err != nil {
+		console.Debugln("Unable to refresh credentials: " + err.Error())
+		c.refreshAt = now.Add(refreshInterval)
+		return c.creds
+	}
+	c.creds = creds
+	c.refreshAt = c.nextRefresh(now)
+	return c.creds
+}
@@ -0,0 +1,47 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"errors"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestCredentialsCache(c *C) {
+	refreshed := Credentials{AccessKeyID: "access", SecretAccessKey: "secret", SessionToken: "new"}
+	var refreshErr error
+	refresh := func() (Credentials, error) {
+		return refreshed, refreshErr
+	}
+
+	// Not due before the refresh interval.
+	cache := NewCredentialsCache(Credentials{AccessKeyID: "access", SessionToken: "old"}, refresh)
+	c.Assert(cache.Get().SessionToken, Equals, "old")
+
+	// Temporary credentials are refreshed ahead of their expiration.
+	cache = NewCredentialsCache(Credentials{SessionToken: "old", Expiration: time.Now().Add(expiryWindow / 2)}, refresh)
+	c.Assert(cache.Get(), DeepEquals, refreshed)
+
+	// Credentials which fail to refresh are kept.
+	refreshErr = errors.New("unavailable")
+	cache = NewCredentialsCache(Credentials{SessionToken: "old", Expiration: time.Now()}, refresh)
+	c.Assert(cache.Get().SessionToken, Equals, "old")
+	refreshErr = nil
+	c.Assert(cache.Get().SessionToken, Equals, "old")
+}
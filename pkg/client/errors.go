@@ -16,7 +16,10 @@
 
 package client
 
-import "strconv"
+import (
+	"strconv"
+	"time"
+)
 
 /// Collection of standard errors
 
@@ -83,6 +86,20 @@ func (e BucketExists) Error() string {
 	return "bucket " + e.Bucket + " exists"
 }
 
+// BucketNotFound - bucket does not exist
+type BucketNotFound GenericBucketError
+
+func (e BucketNotFound) Error() string {
+	return "bucket " + e.Bucket + " not found"
+}
+
+// BucketNotEmpty - bucket cannot be removed while it holds objects
+type BucketNotEmpty GenericBucketError
+
+func (e BucketNotEmpty) Error() string {
+	return "bucket " + e.Bucket + " is not empty"
+}
+
 // InvalidBucketName - bucket name invalid (http://goo.gl/wJlzDz)
 type InvalidBucketName GenericBucketError
 
@@ -170,3 +187,124 @@ type EmptyPath struct{}
 func (e EmptyPath) Error() string {
 	return "Invalid path, path cannot be empty"
 }
+
+// SlowDown - server asked us to reduce the request rate (SlowDown, 503 Service Unavailable)
+type SlowDown struct {
+	Code string
+}
+
+func (e SlowDown) Error() string {
+	return "Server is busy, please reduce your request rate: " + e.Code
+}
+
+// InternalError - server failed to process the request (InternalError, 500 Internal Server Error)
+type InternalError struct {
+	Code string
+}
+
+func (e InternalError) Error() string {
+	return "Server encountered an internal error, please try again: " + e.Code
+}
+
+// RequestTimeout - server gave up waiting on an idle connection
+type RequestTimeout struct{}
+
+func (e RequestTimeout) Error() string {
+	return "Request timed out, connection was idle for too long"
+}
+
+// OperationTimeout - operation did not complete within the host's timeout
+type OperationTimeout struct{}
+
+func (e OperationTimeout) Error() string {
+	return "Operation timed out, server did not respond in time"
+}
+
+// CredentialsExpired - temporary credentials expired, they may be refreshed before retrying
+type CredentialsExpired struct {
+	Code string
+}
+
+func (e CredentialsExpired) Error() string {
+	return "Credentials expired, server rejected the session token: " + e.Code
+}
+
+// InvalidProxy - proxy of a host is no URL
+type InvalidProxy struct {
+	Proxy string
+}
+
+func (e InvalidProxy) Error() string {
+	return "Invalid proxy ‘" + e.Proxy + "’, please use a URL such as ‘http://proxy.example.com:3128’"
+}
+
+// InvalidCAFile - CA bundle of a host holds no PEM certificates
+type InvalidCAFile struct {
+	Path string
+}
+
+func (e InvalidCAFile) Error() string {
+	return "No PEM certificates found in CA bundle ‘" + e.Path + "’"
+}
+
+// SignatureRejected - server rejected the signature of a request, for wrong keys or a signature
+// version it does not support
+type SignatureRejected struct {
+	API  string
+	Code string
+}
+
+func (e SignatureRejected) Error() string {
+	msg := "Server rejected the signature of the request: " + e.Code + ", please check the access keys of the host"
+	if e.API == "S3v4" {
+		msg += ", or set its API to ‘S3v2’ if it does not support signature V4"
+	}
+	return msg
+}
+
+// ClockSkewed - server rejected a request dated too far from its clock (RequestTimeTooSkewed)
+type ClockSkewed struct {
+	// Skew is how far the server's clock is ahead of the local one, 0 if unknown.
+	Skew time.Duration
+}
+
+func (e ClockSkewed) Error() string {
+	msg := "Local clock is too far off the server's time"
+	switch {
+	case e.Skew > 0:
+		msg = "Local clock is " + e.Skew.String() + " behind the server's time"
+	case e.Skew < 0:
+		msg = "Local clock is " + (-e.Skew).String() + " ahead of the server's time"
+	}
+	return msg + ", please correct it, or set the clock offset of the host to ‘auto’"
+}
+
+// AccessKeyRejected - server does not know the access key of a request (InvalidAccessKeyId)
+type AccessKeyRejected struct {
+	Code string
+}
+
+func (e AccessKeyRejected) Error() string {
+	return "Server does not know the access key: " + e.Code + ", please check the access keys of the host"
+}
+
+// AccessDenied - server denied a request, its access keys lack the permission
+type AccessDenied struct {
+	Message string
+}
+
+func (e AccessDenied) Error() string {
+	if e.Message == "" {
+		return "Access Denied."
+	}
+	return e.Message
+}
+
+// AnonymousAccessDenied - server denied a request sent without access keys
+type AnonymousAccessDenied struct {
+	Message string
+}
+
+func (e AnonymousAccessDenied) Error() string {
+	return AccessDenied{Message: e.Message}.Error() + " Request was sent without access keys, please set the access keys of the host with ‘mc config host add’"
+}
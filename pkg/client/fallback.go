@@ -0,0 +1,203 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// fallbackState of a host, by scheme and host.
+type fallbackState struct {
+	accepted   bool // host accepted a request of a primary client, it never falls back
+	fallenBack bool // host rejected the signature of a primary client before accepting any
+}
+
+// fallbackHosts holds the state of the hosts of all fallback clients.
+var fallbackHosts = struct {
+	sync.Mutex
+	hosts map[string]fallbackState
+}{hosts: make(map[string]fallbackState)}
+
+// fallbackClient sends requests with primary until its host rejects their signature, and with
+// fallback from then on. Hosts which accepted a request of primary never fall back, signatures
+// they reject come from wrong keys or objects rather than an unsupported signature version.
+type fallbackClient struct {
+	primary  Client
+	fallback Client
+	host     string
+}
+
+// NewFallbackClient returns a client sending requests with primary, or with fallback once the
+// host of primary rejected the signature of a request, such as a host without signature V4.
+// Rejected requests are sent again with fallback, except uploads which cannot be rewound.
+func NewFallbackClient(primary, fallback Client) Client {
+	url := primary.URL()
+	return &fallbackClient{primary: primary, fallback: fallback, host: url.Scheme + "://" + url.Host}
+}
+
+// current returns the client requests are sent with.
+func (c *fallbackClient) current() Client {
+	fallbackHosts.Lock()
+	defer fallbackHosts.Unlock()
+	if fallbackHosts.hosts[c.host].fallenBack {
+		return c.fallback
+	}
+	return c.primary
+}
+
+// rejected returns true if err of a request sent with primary is a rejected signature, and
+// the host never accepted a request of primary before. The host falls back from then on.
+func (c *fallbackClient) rejected(err *probe.Error) bool {
+	fallbackHosts.Lock()
+	defer fallbackHosts.Unlock()
+	state := fallbackHosts.hosts[c.host]
+	if err == nil {
+		if !state.accepted {
+			state.accepted = true
+			fallbackHosts.hosts[c.host] = state
+		}
+		return false
+	}
+	if _, ok := err.ToGoError().(SignatureRejected); !ok || state.accepted {
+		return false
+	}
+	state.fallenBack = true
+	fallbackHosts.hosts[c.host] = state
+	return true
+}
+
+// Stat - see Client.
+func (c *fallbackClient) Stat(ctx context.Context) (*Content, *probe.Error) {
+	clnt := c.current()
+	content, err := clnt.Stat(ctx)
+	if clnt == c.primary && c.rejected(err) {
+		return c.fallback.Stat(ctx)
+	}
+	return content, err
+}
+
+// List - see Client, listings are sent again if their first result is a rejected signature.
+func (c *fallbackClient) List(ctx context.Context, recursive, incomplete bool) <-chan ContentOnChannel {
+	clnt := c.current()
+	if clnt != c.primary {
+		return clnt.List(ctx, recursive, incomplete)
+	}
+	contentCh := make(chan ContentOnChannel)
+	go func() {
+		defer close(contentCh)
+		listCh := c.primary.List(ctx, recursive, incomplete)
+		first, ok := <-listCh
+		if !ok {
+			return
+		}
+		if c.rejected(first.Err) {
+			for range listCh {
+			}
+			listCh = c.fallback.List(ctx, recursive, incomplete)
+		} else {
+			contentCh <- first
+		}
+		for content := range listCh {
+			contentCh <- content
+		}
+	}()
+	return ForwardContents(ctx, contentCh)
+}
+
+// MakeBucket - see Client.
+func (c *fallbackClient) MakeBucket(ctx context.Context) *probe.Error {
+	clnt := c.current()
+	err := clnt.MakeBucket(ctx)
+	if clnt == c.primary && c.rejected(err) {
+		return c.fallback.MakeBucket(ctx)
+	}
+	return err
+}
+
+// GetBucketAccess - see Client.
+func (c *fallbackClient) GetBucketAccess(ctx context.Context) (string, *probe.Error) {
+	clnt := c.current()
+	access, err := clnt.GetBucketAccess(ctx)
+	if clnt == c.primary && c.rejected(err) {
+		return c.fallback.GetBucketAccess(ctx)
+	}
+	return access, err
+}
+
+// SetBucketAccess - see Client.
+func (c *fallbackClient) SetBucketAccess(ctx context.Context, access string) *probe.Error {
+	clnt := c.current()
+	err := clnt.SetBucketAccess(ctx, access)
+	if clnt == c.primary && c.rejected(err) {
+		return c.fallback.SetBucketAccess(ctx, access)
+	}
+	return err
+}
+
+// Get - see Client.
+func (c *fallbackClient) Get(ctx context.Context, offset, length int64) (io.ReadCloser, int64, *probe.Error) {
+	clnt := c.current()
+	body, size, err := clnt.Get(ctx, offset, length)
+	if clnt == c.primary && c.rejected(err) {
+		return c.fallback.Get(ctx, offset, length)
+	}
+	return body, size, err
+}
+
+// Put - see Client, rejected uploads are sent again only if data can be rewound.
+func (c *fallbackClient) Put(ctx context.Context, size int64, data io.Reader) *probe.Error {
+	clnt := c.current()
+	err := clnt.Put(ctx, size, data)
+	if clnt == c.primary && c.rejected(err) {
+		if seeker, ok := data.(io.Seeker); ok {
+			if _, e := seeker.Seek(0, 0); e == nil {
+				return c.fallback.Put(ctx, size, data)
+			}
+		}
+	}
+	return err
+}
+
+// ShareDownload - see Client, presigned with the client requests are sent with.
+func (c *fallbackClient) ShareDownload(expires time.Duration) (string, *probe.Error) {
+	return c.current().ShareDownload(expires)
+}
+
+// ShareUpload - see Client, presigned with the client requests are sent with.
+func (c *fallbackClient) ShareUpload(recursive bool, expires time.Duration, contentType string) (map[string]string, *probe.Error) {
+	return c.current().ShareUpload(recursive, expires, contentType)
+}
+
+// Remove - see Client.
+func (c *fallbackClient) Remove(ctx context.Context, incomplete bool) *probe.Error {
+	clnt := c.current()
+	err := clnt.Remove(ctx, incomplete)
+	if clnt == c.primary && c.rejected(err) {
+		return c.fallback.Remove(ctx, incomplete)
+	}
+	return err
+}
+
+// URL - see Client.
+func (c *fallbackClient) URL() *URL {
+	return c.primary.URL()
+}
@@ -17,6 +17,7 @@
 package fs
 
 import (
+	"context"
 	"io"
 	"os"
 	"path/filepath"
@@ -34,6 +35,16 @@ type fsClient struct {
 	Path string
 }
 
+func init() {
+	client.RegisterBackend(client.Backend{
+		API:     "fs",
+		Schemes: []string{""},
+		New: func(config *client.Config) (client.Client, *probe.Error) {
+			return New(config.HostURL)
+		},
+	})
+}
+
 // New - instantiate a new fs client
 func New(path string) (client.Client, *probe.Error) {
 	if strings.TrimSpace(path) == "" {
@@ -100,8 +111,22 @@ func (f *fsClient) fsStat() (os.FileInfo, *probe.Error) {
 	return st, nil
 }
 
+// contextReader stops reading once its context is done.
+type contextReader struct {
+	ctx context.Context
+	io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.Reader.Read(p)
+}
+
 // Put - create a new file
-func (f *fsClient) Put(size int64, data io.Reader) *probe.Error {
+func (f *fsClient) Put(ctx context.Context, size int64, data io.Reader) *probe.Error {
+	data = contextReader{ctx, data}
 	objectDir, _ := filepath.Split(f.Path)
 	objectPath := f.Path
 	if objectDir != "" {
@@ -156,7 +181,8 @@ func (f *fsClient) ShareUpload(recursive bool, expires time.Duration, contentTyp
 // Get download an full or part object from bucket
 // getobject returns a reader, length and nil for no errors
 // with errors getobject will return nil reader, length and typed errors
-func (f *fsClient) Get(offset, length int64) (io.ReadCloser, int64, *probe.Error) {
+// length 0 reads till the end, the length returned is the number of bytes read
+func (f *fsClient) Get(ctx context.Context, offset, length int64) (io.ReadCloser, int64, *probe.Error) {
 	if offset < 0 || length < 0 {
 		return nil, 0, probe.NewError(client.InvalidRange{Offset: offset})
 	}
@@ -171,7 +197,7 @@ func (f *fsClient) Get(offset, length int64) (io.ReadCloser, int64, *probe.Error
 	// Resolve symlinks
 	_, err := filepath.EvalSymlinks(tmppath)
 	if os.IsNotExist(err) {
-		return nil, length, probe.NewError(err)
+		return nil, length, probe.NewError(client.NotFound{Path: f.Path})
 	}
 	if err != nil {
 		return nil, length, probe.NewError(err)
@@ -185,13 +211,38 @@ func (f *fsClient) Get(offset, length int64) (io.ReadCloser, int64, *probe.Error
 
 	}
 	_, err = io.CopyN(ioutil.Discard, body, int64(offset))
+	if err == io.EOF {
+		body.Close()
+		return nil, length, probe.NewError(client.InvalidRange{Offset: offset})
+	}
 	if err != nil {
+		body.Close()
 		return nil, length, probe.NewError(err)
 	}
-	return body, length, nil
+	content, perr := f.getFSMetadata()
+	if perr != nil {
+		body.Close()
+		return nil, length, perr.Trace(f.Path)
+	}
+	// As with S3, ranges have to start before the end of the file.
+	if offset > 0 && offset >= content.Size {
+		body.Close()
+		return nil, length, probe.NewError(client.InvalidRange{Offset: offset})
+	}
+	// Length 0 reads till the end, otherwise no further than the end.
+	if length == 0 || offset+length > content.Size {
+		length = content.Size - offset
+	}
+	return limitedReadCloser{io.LimitReader(body, length), body}, length, nil
 }
 
-func (f *fsClient) Remove(incomplete bool) *probe.Error {
+// limitedReadCloser reads a range of a file, closing the file.
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func (f *fsClient) Remove(ctx context.Context, incomplete bool) *probe.Error {
 	if incomplete {
 		return nil
 	}
@@ -200,7 +251,7 @@ func (f *fsClient) Remove(incomplete bool) *probe.Error {
 }
 
 // List - list files and folders
-func (f *fsClient) List(recursive, incomplete bool) <-chan client.ContentOnChannel {
+func (f *fsClient) List(ctx context.Context, recursive, incomplete bool) <-chan client.ContentOnChannel {
 	contentCh := make(chan client.ContentOnChannel)
 	if incomplete {
 		go func() {
@@ -210,14 +261,14 @@ func (f *fsClient) List(recursive, incomplete bool) <-chan client.ContentOnChann
 	}
 	switch recursive {
 	case true:
-		go f.listRecursiveInRoutine(contentCh)
+		go f.listRecursiveInRoutine(ctx, contentCh)
 	default:
-		go f.listInRoutine(contentCh)
+		go f.listInRoutine(ctx, contentCh)
 	}
-	return contentCh
+	return client.ForwardContents(ctx, contentCh)
 }
 
-func (f *fsClient) listInRoutine(contentCh chan client.ContentOnChannel) {
+func (f *fsClient) listInRoutine(ctx context.Context, contentCh chan client.ContentOnChannel) {
 	defer close(contentCh)
 
 	fpath := f.Path
@@ -262,6 +313,9 @@ func (f *fsClient) listInRoutine(contentCh chan client.ContentOnChannel) {
 			return
 		}
 		for _, file := range files {
+			if ctx.Err() != nil {
+				return
+			}
 			fi := file
 			if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
 				fi, err = os.Stat(filepath.Join(dir.Name(), fi.Name()))
@@ -339,11 +393,15 @@ func (f *fsClient) listInRoutine(contentCh chan client.ContentOnChannel) {
 	}
 }
 
-func (f *fsClient) listRecursiveInRoutine(contentCh chan client.ContentOnChannel) {
+func (f *fsClient) listRecursiveInRoutine(ctx context.Context, contentCh chan client.ContentOnChannel) {
 	defer close(contentCh)
 	var dirName string
 	var filePrefix string
 	visitFS := func(fp string, fi os.FileInfo, err error) error {
+		// Stop walking once the consumer is gone.
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		// if file path ends with os.PathSeparator and equals to root path, skip it.
 		if strings.HasSuffix(fp, string(f.URL().Separator)) {
 			if fp == dirName {
@@ -502,7 +560,7 @@ func (f *fsClient) listRecursiveInRoutine(contentCh chan client.ContentOnChannel
 }
 
 // MakeBucket - create a new bucket
-func (f *fsClient) MakeBucket() *probe.Error {
+func (f *fsClient) MakeBucket(ctx context.Context) *probe.Error {
 	err := os.MkdirAll(f.Path, 0775)
 	if err != nil {
 		return probe.NewError(err)
@@ -511,12 +569,12 @@ func (f *fsClient) MakeBucket() *probe.Error {
 }
 
 // GetBucketACL - get bucket access
-func (f *fsClient) GetBucketAccess() (acl string, error *probe.Error) {
+func (f *fsClient) GetBucketAccess(ctx context.Context) (acl string, error *probe.Error) {
 	return "", probe.NewError(client.APINotImplemented{API: "GetBucketAccess", APIType: "filesystem"})
 }
 
 // SetBucketAccess - set bucket access
-func (f *fsClient) SetBucketAccess(acl string) *probe.Error {
+func (f *fsClient) SetBucketAccess(ctx context.Context, acl string) *probe.Error {
 	return probe.NewError(client.APINotImplemented{API: "SetBucketAccess", APIType: "filesystem"})
 }
 
@@ -535,6 +593,10 @@ func (f *fsClient) getFSMetadata() (content *client.Content, err *probe.Error) {
 }
 
 // Stat - get metadata from path
-func (f *fsClient) Stat() (content *client.Content, err *probe.Error) {
-	return f.getFSMetadata()
+func (f *fsClient) Stat(ctx context.Context) (content *client.Content, err *probe.Error) {
+	content, err = f.getFSMetadata()
+	if err != nil && os.IsNotExist(err.ToGoError()) {
+		return nil, probe.NewError(client.NotFound{Path: f.Path})
+	}
+	return content, err
 }
@@ -18,6 +18,7 @@ package fs_test
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"io/ioutil"
 	"os"
@@ -47,21 +48,21 @@ func (s *MySuite) TestList(c *C) {
 	data := "hello"
 	dataLen := len(data)
 
-	perr = fsc.Put(int64(dataLen), bytes.NewReader([]byte(data)))
+	perr = fsc.Put(context.Background(), int64(dataLen), bytes.NewReader([]byte(data)))
 	c.Assert(err, IsNil)
 
 	objectPath = filepath.Join(root, "object2")
 	fsc, perr = fs.New(objectPath)
 	c.Assert(err, IsNil)
 
-	perr = fsc.Put(int64(dataLen), bytes.NewReader([]byte(data)))
+	perr = fsc.Put(context.Background(), int64(dataLen), bytes.NewReader([]byte(data)))
 	c.Assert(err, IsNil)
 
 	fsc, perr = fs.New(root)
 	c.Assert(err, IsNil)
 
 	var contents []*client.Content
-	for contentCh := range fsc.List(false, false) {
+	for contentCh := range fsc.List(context.Background(), false, false) {
 		if contentCh.Err != nil {
 			perr = contentCh.Err
 			break
@@ -79,14 +80,14 @@ func (s *MySuite) TestList(c *C) {
 	fsc, perr = fs.New(objectPath)
 	c.Assert(err, IsNil)
 
-	perr = fsc.Put(int64(dataLen), bytes.NewReader([]byte(data)))
+	perr = fsc.Put(context.Background(), int64(dataLen), bytes.NewReader([]byte(data)))
 	c.Assert(err, IsNil)
 
 	fsc, perr = fs.New(root)
 	c.Assert(err, IsNil)
 
 	contents = nil
-	for contentCh := range fsc.List(false, false) {
+	for contentCh := range fsc.List(context.Background(), false, false) {
 		if contentCh.Err != nil {
 			perr = contentCh.Err
 			break
@@ -108,7 +109,7 @@ func (s *MySuite) TestList(c *C) {
 	c.Assert(err, IsNil)
 
 	contents = nil
-	for contentCh := range fsc.List(true, false) {
+	for contentCh := range fsc.List(context.Background(), true, false) {
 		if contentCh.Err != nil {
 			perr = contentCh.Err
 			break
@@ -142,7 +143,7 @@ func (s *MySuite) TestPutBucket(c *C) {
 	bucketPath := filepath.Join(root, "bucket")
 	fsc, perr := fs.New(bucketPath)
 	c.Assert(perr, IsNil)
-	perr = fsc.MakeBucket()
+	perr = fsc.MakeBucket(context.Background())
 	c.Assert(perr, IsNil)
 }
 
@@ -155,9 +156,9 @@ func (s *MySuite) TestStatBucket(c *C) {
 
 	fsc, perr := fs.New(bucketPath)
 	c.Assert(perr, IsNil)
-	perr = fsc.MakeBucket()
+	perr = fsc.MakeBucket(context.Background())
 	c.Assert(perr, IsNil)
-	_, perr = fsc.Stat()
+	_, perr = fsc.Stat(context.Background())
 	c.Assert(perr, IsNil)
 }
 
@@ -169,13 +170,13 @@ func (s *MySuite) TestBucketACLFails(c *C) {
 	bucketPath := filepath.Join(root, "bucket")
 	fsc, perr := fs.New(bucketPath)
 	c.Assert(perr, IsNil)
-	perr = fsc.MakeBucket()
+	perr = fsc.MakeBucket(context.Background())
 	c.Assert(perr, IsNil)
 
-	perr = fsc.SetBucketAccess("private")
+	perr = fsc.SetBucketAccess(context.Background(), "private")
 	c.Assert(perr, Not(IsNil))
 
-	_, perr = fsc.GetBucketAccess()
+	_, perr = fsc.GetBucketAccess(context.Background())
 	c.Assert(perr, Not(IsNil))
 }
 
@@ -191,7 +192,7 @@ func (s *MySuite) TestPut(c *C) {
 	data := "hello"
 	dataLen := len(data)
 
-	perr = fsc.Put(int64(dataLen), bytes.NewReader([]byte(data)))
+	perr = fsc.Put(context.Background(), int64(dataLen), bytes.NewReader([]byte(data)))
 	c.Assert(perr, IsNil)
 }
 
@@ -207,10 +208,10 @@ func (s *MySuite) TestGet(c *C) {
 	data := "hello"
 	dataLen := len(data)
 
-	perr = fsc.Put(int64(dataLen), bytes.NewReader([]byte(data)))
+	perr = fsc.Put(context.Background(), int64(dataLen), bytes.NewReader([]byte(data)))
 	c.Assert(perr, IsNil)
 
-	reader, size, perr := fsc.Get(0, 0)
+	reader, size, perr := fsc.Get(context.Background(), 0, 0)
 	c.Assert(perr, IsNil)
 	var results bytes.Buffer
 	_, err = io.CopyN(&results, reader, int64(size))
@@ -231,10 +232,10 @@ func (s *MySuite) TestGetRange(c *C) {
 	data := "hello world"
 	dataLen := len(data)
 
-	perr = fsc.Put(int64(dataLen), bytes.NewReader([]byte(data)))
+	perr = fsc.Put(context.Background(), int64(dataLen), bytes.NewReader([]byte(data)))
 	c.Assert(perr, IsNil)
 
-	reader, size, perr := fsc.Get(0, 5)
+	reader, size, perr := fsc.Get(context.Background(), 0, 5)
 	c.Assert(perr, IsNil)
 	var results bytes.Buffer
 	_, err = io.CopyN(&results, reader, int64(size))
@@ -242,6 +243,39 @@ func (s *MySuite) TestGetRange(c *C) {
 	c.Assert([]byte("hello"), DeepEquals, results.Bytes())
 }
 
+func (s *MySuite) TestGetErrors(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	objectPath := filepath.Join(root, "object")
+	fsc, perr := fs.New(objectPath)
+	c.Assert(perr, IsNil)
+
+	_, perr = fsc.Stat(context.Background())
+	c.Assert(perr.ToGoError(), FitsTypeOf, client.NotFound{})
+	_, _, perr = fsc.Get(context.Background(), 0, 0)
+	c.Assert(perr.ToGoError(), FitsTypeOf, client.NotFound{})
+
+	data := "hello world"
+	perr = fsc.Put(context.Background(), int64(len(data)), bytes.NewReader([]byte(data)))
+	c.Assert(perr, IsNil)
+
+	// Size is what is read, ranges end at the end of the file.
+	reader, size, perr := fsc.Get(context.Background(), 6, 100)
+	c.Assert(perr, IsNil)
+	results, err := ioutil.ReadAll(reader)
+	reader.Close()
+	c.Assert(err, IsNil)
+	c.Assert(string(results), Equals, "world")
+	c.Assert(size, Equals, int64(len("world")))
+
+	_, _, perr = fsc.Get(context.Background(), 100, 0)
+	c.Assert(perr.ToGoError(), FitsTypeOf, client.InvalidRange{})
+	_, _, perr = fsc.Get(context.Background(), int64(len(data)), 0)
+	c.Assert(perr.ToGoError(), FitsTypeOf, client.InvalidRange{})
+}
+
 func (s *MySuite) TestStatObject(c *C) {
 	root, err := ioutil.TempDir(os.TempDir(), "fs-")
 	c.Assert(err, IsNil)
@@ -254,10 +288,10 @@ func (s *MySuite) TestStatObject(c *C) {
 	data := "hello"
 	dataLen := len(data)
 
-	perr = fsc.Put(int64(dataLen), bytes.NewReader([]byte(data)))
+	perr = fsc.Put(context.Background(), int64(dataLen), bytes.NewReader([]byte(data)))
 	c.Assert(perr, IsNil)
 
-	content, perr := fsc.Stat()
+	content, perr := fsc.Stat(context.Background())
 	c.Assert(perr, IsNil)
 	c.Assert(content.Name, Equals, objectPath)
 	c.Assert(content.Size, Equals, int64(dataLen))
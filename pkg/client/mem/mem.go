@@ -0,0 +1,487 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package mem implements client.Client in memory, for mem://host/bucket/object URLs.
+// Contents live as long as the process and are shared by all its clients, which makes
+// the backend suitable for tests and scratch work. Listing follows S3 semantics.
+package mem
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Scheme - URL scheme served by this backend.
+const Scheme = "mem"
+
+// partSize - size from which S3 clients upload in parts.
+const partSize = 5 * 1024 * 1024
+
+// object - contents of an object, or of an incomplete upload.
+type object struct {
+	data    []byte
+	modTime time.Time
+}
+
+// bucket - objects by key, sorted on listing.
+type bucket struct {
+	created time.Time
+	access  string
+	objects map[string]object
+	uploads map[string]object
+}
+
+// store holds buckets by host, shared by all clients of the process.
+var store = struct {
+	sync.Mutex
+	hosts map[string]map[string]*bucket
+}{hosts: make(map[string]map[string]*bucket)}
+
+// canned bucket access policies, as accepted by S3.
+var accessTypes = map[string]bool{
+	"private":            true,
+	"public-read":        true,
+	"public-read-write":  true,
+	"authenticated-read": true,
+}
+
+type memClient struct {
+	hostURL *client.URL
+}
+
+func init() {
+	client.RegisterBackend(client.Backend{
+		API:     "mem",
+		Schemes: []string{Scheme},
+		New:     New,
+	})
+}
+
+// New - instantiate a new mem client
+func New(config *client.Config) (client.Client, *probe.Error) {
+	u := client.NewURL(config.HostURL)
+	if u.Type != client.Object || u.Scheme != Scheme {
+		return nil, probe.NewError(client.InvalidQueryURL{URL: config.HostURL})
+	}
+	return &memClient{hostURL: u}, nil
+}
+
+// Reset - remove all buckets of all hosts.
+func Reset() {
+	store.Lock()
+	defer store.Unlock()
+	store.hosts = make(map[string]map[string]*bucket)
+}
+
+// URL get url
+func (c *memClient) URL() *client.URL {
+	return c.hostURL
+}
+
+// url2BucketAndObject gives bucketName and objectName from URL path
+func (c *memClient) url2BucketAndObject() (bucketName, objectName string) {
+	splits := strings.SplitN(c.hostURL.Path, string(c.hostURL.Separator), 3)
+	switch len(splits) {
+	case 0, 1:
+		return "", ""
+	case 2:
+		return splits[1], ""
+	}
+	return splits[1], splits[2]
+}
+
+// getBucket returns bucket of this host, store has to be locked.
+func (c *memClient) getBucket(name string) (*bucket, *probe.Error) {
+	b, ok := store.hosts[c.hostURL.Host][name]
+	if !ok {
+		return nil, probe.NewError(client.BucketNotFound{Bucket: name})
+	}
+	return b, nil
+}
+
+// Stat - get metadata of bucket or object, prefixes followed by ‘/’ are folders
+func (c *memClient) Stat(ctx context.Context) (*client.Content, *probe.Error) {
+	if ctx.Err() != nil {
+		return nil, probe.NewError(client.ContextError(ctx, ctx.Err()))
+	}
+	store.Lock()
+	defer store.Unlock()
+
+	bucketName, objectName := c.url2BucketAndObject()
+	if bucketName == "" {
+		return &client.Content{Type: os.ModeDir}, nil
+	}
+	b, err := c.getBucket(bucketName)
+	if err != nil {
+		return nil, err.Trace(bucketName)
+	}
+	if objectName == "" {
+		return &client.Content{Name: bucketName, Time: b.created, Type: os.ModeDir}, nil
+	}
+	if o, ok := b.objects[objectName]; ok {
+		return &client.Content{Name: objectName, Time: o.modTime, Size: int64(len(o.data)), Type: os.FileMode(0664)}, nil
+	}
+	prefix := strings.TrimSuffix(objectName, "/") + "/"
+	for key := range b.objects {
+		if strings.HasPrefix(key, prefix) {
+			return &client.Content{Name: objectName, Time: b.created, Type: os.ModeDir}, nil
+		}
+	}
+	return nil, probe.NewError(client.ObjectNotFound{Bucket: bucketName, Object: objectName})
+}
+
+// List - list at delimited path, if not recursive
+func (c *memClient) List(ctx context.Context, recursive, incomplete bool) <-chan client.ContentOnChannel {
+	contents, err := c.list(recursive, incomplete)
+	contentCh := make(chan client.ContentOnChannel)
+	go func() {
+		defer close(contentCh)
+		if err != nil {
+			contentCh <- client.ContentOnChannel{Err: err}
+			return
+		}
+		for _, content := range contents {
+			contentCh <- client.ContentOnChannel{Content: content}
+		}
+	}()
+	return client.ForwardContents(ctx, contentCh)
+}
+
+// list returns a snapshot of contents in lexical order, as S3 does. Recursive listings
+// have no folders, names are relative to the folder of the URL like those of s3 clients.
+func (c *memClient) list(recursive, incomplete bool) ([]*client.Content, *probe.Error) {
+	store.Lock()
+	defer store.Unlock()
+
+	objectType := os.FileMode(0664)
+	objects := func(b *bucket) map[string]object { return b.objects }
+	if incomplete {
+		objectType = os.ModeTemporary
+		objects = func(b *bucket) map[string]object { return b.uploads }
+	}
+
+	var contents []*client.Content
+	bucketName, objectName := c.url2BucketAndObject()
+	if bucketName == "" {
+		for _, name := range sortedBuckets(store.hosts[c.hostURL.Host]) {
+			b := store.hosts[c.hostURL.Host][name]
+			if !recursive {
+				contents = append(contents, &client.Content{Name: name, Time: b.created, Type: os.ModeDir})
+				continue
+			}
+			for _, key := range sortedKeys(objects(b), "") {
+				o := objects(b)[key]
+				contents = append(contents, &client.Content{Name: name + "/" + key, Time: o.modTime, Size: int64(len(o.data)), Type: objectType})
+			}
+		}
+		return contents, nil
+	}
+
+	b, err := c.getBucket(bucketName)
+	if err != nil {
+		return nil, err.Trace(bucketName)
+	}
+	if recursive {
+		for _, key := range sortedKeys(objects(b), objectName) {
+			o := objects(b)[key]
+			name := key
+			switch {
+			case objectName == "":
+				// URL not delimited, bucket is part of the names.
+				if !strings.HasSuffix(c.hostURL.Path, "/") {
+					name = bucketName + "/" + key
+				}
+			case strings.HasSuffix(objectName, "/"):
+				name = strings.TrimPrefix(key, objectName)
+			}
+			contents = append(contents, &client.Content{Name: name, Time: o.modTime, Size: int64(len(o.data)), Type: objectType})
+		}
+		return contents, nil
+	}
+
+	if o, ok := objects(b)[objectName]; ok && objectName != "" {
+		return []*client.Content{{Name: objectName, Time: o.modTime, Size: int64(len(o.data)), Type: objectType}}, nil
+	}
+	normalizedPrefix := strings.TrimSuffix(objectName, "/") + "/"
+	normalize := func(key string) string {
+		if normalizedPrefix != key && strings.HasPrefix(key, normalizedPrefix) {
+			return strings.TrimPrefix(key, normalizedPrefix)
+		}
+		return key
+	}
+	lastPrefix := ""
+	for _, key := range sortedKeys(objects(b), objectName) {
+		// Keys with a ‘/’ past the prefix roll up into a common prefix, emitted once.
+		if i := strings.Index(key[len(objectName):], "/"); i >= 0 {
+			commonPrefix := key[:len(objectName)+i+1]
+			if commonPrefix != lastPrefix {
+				lastPrefix = commonPrefix
+				contents = append(contents, &client.Content{Name: normalize(commonPrefix), Time: b.created, Type: os.ModeDir})
+			}
+			continue
+		}
+		o := objects(b)[key]
+		contents = append(contents, &client.Content{Name: normalize(key), Time: o.modTime, Size: int64(len(o.data)), Type: objectType})
+	}
+	return contents, nil
+}
+
+// sortedBuckets returns bucket names in lexical order.
+func sortedBuckets(buckets map[string]*bucket) []string {
+	var names []string
+	for name := range buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedKeys returns keys starting with prefix in lexical order.
+func sortedKeys(objects map[string]object, prefix string) []string {
+	var keys []string
+	for key := range objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MakeBucket - make a new bucket
+func (c *memClient) MakeBucket(ctx context.Context) *probe.Error {
+	bucketName, objectName := c.url2BucketAndObject()
+	if objectName != "" {
+		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	if bucketName == "" {
+		return probe.NewError(client.InvalidBucketName{Bucket: bucketName})
+	}
+	if ctx.Err() != nil {
+		return probe.NewError(client.ContextError(ctx, ctx.Err()))
+	}
+	store.Lock()
+	defer store.Unlock()
+	buckets, ok := store.hosts[c.hostURL.Host]
+	if !ok {
+		buckets = make(map[string]*bucket)
+		store.hosts[c.hostURL.Host] = buckets
+	}
+	if _, ok := buckets[bucketName]; ok {
+		return probe.NewError(client.BucketExists{Bucket: bucketName})
+	}
+	buckets[bucketName] = &bucket{
+		created: time.Now().UTC(),
+		access:  "private",
+		objects: make(map[string]object),
+		uploads: make(map[string]object),
+	}
+	return nil
+}
+
+// GetBucketAccess get canned acl on a bucket
+func (c *memClient) GetBucketAccess(ctx context.Context) (string, *probe.Error) {
+	bucketName, objectName := c.url2BucketAndObject()
+	if objectName != "" {
+		return "", probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	store.Lock()
+	defer store.Unlock()
+	b, err := c.getBucket(bucketName)
+	if err != nil {
+		return "", err.Trace(bucketName)
+	}
+	return b.access, nil
+}
+
+// SetBucketAccess set canned acl on a bucket
+func (c *memClient) SetBucketAccess(ctx context.Context, access string) *probe.Error {
+	bucketName, objectName := c.url2BucketAndObject()
+	if objectName != "" {
+		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	if !accessTypes[access] {
+		return probe.NewError(client.InvalidACLType{ACL: access})
+	}
+	store.Lock()
+	defer store.Unlock()
+	b, err := c.getBucket(bucketName)
+	if err != nil {
+		return err.Trace(bucketName)
+	}
+	b.access = access
+	return nil
+}
+
+// Get - get object, length 0 reads from offset to the end
+func (c *memClient) Get(ctx context.Context, offset, length int64) (io.ReadCloser, int64, *probe.Error) {
+	bucketName, objectName := c.url2BucketAndObject()
+	if objectName == "" {
+		return nil, length, probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	if ctx.Err() != nil {
+		return nil, length, probe.NewError(client.ContextError(ctx, ctx.Err()))
+	}
+	store.Lock()
+	defer store.Unlock()
+	b, err := c.getBucket(bucketName)
+	if err != nil {
+		return nil, length, err.Trace(bucketName)
+	}
+	o, ok := b.objects[objectName]
+	if !ok {
+		return nil, length, probe.NewError(client.ObjectNotFound{Bucket: bucketName, Object: objectName})
+	}
+	size := int64(len(o.data))
+	// As with S3, ranges have to start before the end of the object.
+	if offset < 0 || (offset > 0 && offset >= size) || length < 0 {
+		return nil, length, probe.NewError(client.InvalidRange{Offset: offset})
+	}
+	end := size
+	if length > 0 && offset+length < size {
+		end = offset + length
+	}
+	// Objects are replaced rather than modified, readers may keep the slice.
+	return ioutil.NopCloser(bytes.NewReader(o.data[offset:end])), end - offset, nil
+}
+
+// Put - put object, if size=0 read until EOF. Failed uploads are kept as incomplete ones
+// if they would have been multipart, smaller ones leave nothing behind as with S3.
+func (c *memClient) Put(ctx context.Context, size int64, data io.Reader) *probe.Error {
+	bucketName, objectName := c.url2BucketAndObject()
+	if objectName == "" {
+		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	store.Lock()
+	_, err := c.getBucket(bucketName)
+	store.Unlock()
+	if err != nil {
+		return err.Trace(bucketName)
+	}
+
+	var buffer bytes.Buffer
+	e := readAll(ctx, &buffer, data, size)
+
+	store.Lock()
+	defer store.Unlock()
+	b, err := c.getBucket(bucketName)
+	if err != nil {
+		return err.Trace(bucketName)
+	}
+	if e != nil {
+		if size == 0 || size >= partSize {
+			b.uploads[objectName] = object{data: buffer.Bytes(), modTime: time.Now().UTC()}
+		}
+		return probe.NewError(e)
+	}
+	delete(b.uploads, objectName)
+	b.objects[objectName] = object{data: buffer.Bytes(), modTime: time.Now().UTC()}
+	return nil
+}
+
+// readAll copies size bytes, or until EOF if size=0, from reader until ctx is done.
+func readAll(ctx context.Context, writer io.Writer, reader io.Reader, size int64) error {
+	if size > 0 {
+		reader = io.LimitReader(reader, size)
+	}
+	buf := make([]byte, 32*1024)
+	var total int64
+	for {
+		if ctx.Err() != nil {
+			return client.ContextError(ctx, ctx.Err())
+		}
+		n, e := reader.Read(buf)
+		writer.Write(buf[:n])
+		total += int64(n)
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return e
+		}
+	}
+	if size > 0 && total != size {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// Remove - remove object or empty bucket, or an incomplete upload
+func (c *memClient) Remove(ctx context.Context, incomplete bool) *probe.Error {
+	if ctx.Err() != nil {
+		return probe.NewError(client.ContextError(ctx, ctx.Err()))
+	}
+	bucketName, objectName := c.url2BucketAndObject()
+	store.Lock()
+	defer store.Unlock()
+	b, err := c.getBucket(bucketName)
+	if err != nil {
+		return err.Trace(bucketName)
+	}
+	if incomplete {
+		delete(b.uploads, objectName)
+		return nil
+	}
+	if objectName == "" {
+		if len(b.objects) > 0 || len(b.uploads) > 0 {
+			return probe.NewError(client.BucketNotEmpty{Bucket: bucketName})
+		}
+		delete(store.hosts[c.hostURL.Host], bucketName)
+		return nil
+	}
+	if _, ok := b.objects[objectName]; !ok {
+		return probe.NewError(client.ObjectNotFound{Bucket: bucketName, Object: objectName})
+	}
+	delete(b.objects, objectName)
+	return nil
+}
+
+// ShareDownload - nothing is served over the network, returns the URL itself with its expiry.
+func (c *memClient) ShareDownload(expires time.Duration) (string, *probe.Error) {
+	_, objectName := c.url2BucketAndObject()
+	if objectName == "" {
+		return "", probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	return c.hostURL.String() + "?expires=" + strconv.FormatInt(time.Now().UTC().Add(expires).Unix(), 10), nil
+}
+
+// ShareUpload - nothing is served over the network, returns the would be form fields.
+func (c *memClient) ShareUpload(recursive bool, expires time.Duration, contentType string) (map[string]string, *probe.Error) {
+	bucketName, objectName := c.url2BucketAndObject()
+	if bucketName == "" {
+		return nil, probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	m := map[string]string{
+		"bucket":  bucketName,
+		"key":     objectName,
+		"expires": strconv.FormatInt(time.Now().UTC().Add(expires).Unix(), 10),
+	}
+	if strings.TrimSpace(contentType) != "" {
+		m["Content-Type"] = contentType
+	}
+	return m, nil
+}
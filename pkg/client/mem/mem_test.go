@@ -0,0 +1,193 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/minio/mc/pkg/client"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+func (s *MySuite) SetUpTest(c *C) {
+	Reset()
+}
+
+func newClient(c *C, urlStr string) client.Client {
+	clnt, err := New(&client.Config{HostURL: urlStr})
+	c.Assert(err, IsNil)
+	return clnt
+}
+
+func put(c *C, urlStr, data string) {
+	err := newClient(c, urlStr).Put(context.Background(), int64(len(data)), bytes.NewReader([]byte(data)))
+	c.Assert(err, IsNil)
+}
+
+func names(c *C, clnt client.Client, recursive, incomplete bool) []string {
+	var list []string
+	for content := range clnt.List(context.Background(), recursive, incomplete) {
+		c.Assert(content.Err, IsNil)
+		list = append(list, content.Content.Name)
+	}
+	return list
+}
+
+func (s *MySuite) TestBucketOperations(c *C) {
+	ctx := context.Background()
+	bucket := newClient(c, "mem://host/bucket")
+	c.Assert(bucket.MakeBucket(ctx), IsNil)
+	err := bucket.MakeBucket(ctx)
+	c.Assert(err, Not(IsNil))
+	c.Assert(err.ToGoError(), Equals, client.BucketExists{Bucket: "bucket"})
+
+	access, err := bucket.GetBucketAccess(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(access, Equals, "private")
+	c.Assert(bucket.SetBucketAccess(ctx, "public-read"), IsNil)
+	access, _ = bucket.GetBucketAccess(ctx)
+	c.Assert(access, Equals, "public-read")
+	c.Assert(bucket.SetBucketAccess(ctx, "everyone"), Not(IsNil))
+
+	// Hosts are separate namespaces.
+	c.Assert(newClient(c, "mem://other/zebra").MakeBucket(ctx), IsNil)
+	c.Assert(newClient(c, "mem://host/apple").MakeBucket(ctx), IsNil)
+	c.Assert(names(c, newClient(c, "mem://host"), false, false), DeepEquals, []string{"apple", "bucket"})
+
+	put(c, "mem://host/bucket/object", "hello")
+	err = bucket.Remove(ctx, false)
+	c.Assert(err.ToGoError(), Equals, client.BucketNotEmpty{Bucket: "bucket"})
+	c.Assert(newClient(c, "mem://host/bucket/object").Remove(ctx, false), IsNil)
+	c.Assert(bucket.Remove(ctx, false), IsNil)
+	_, err = bucket.Stat(ctx)
+	c.Assert(err.ToGoError(), Equals, client.BucketNotFound{Bucket: "bucket"})
+}
+
+func (s *MySuite) TestObjectOperations(c *C) {
+	ctx := context.Background()
+	c.Assert(newClient(c, "mem://host/bucket").MakeBucket(ctx), IsNil)
+	put(c, "mem://host/bucket/object", "hello world")
+
+	object := newClient(c, "mem://host/bucket/object")
+	content, err := object.Stat(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(content.Name, Equals, "object")
+	c.Assert(content.Size, Equals, int64(11))
+	c.Assert(content.Type.IsRegular(), Equals, true)
+
+	reader, size, err := object.Get(ctx, 6, 0)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(5))
+	data, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "world")
+
+	reader, size, err = object.Get(ctx, 0, 5)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(5))
+	data, _ = ioutil.ReadAll(reader)
+	c.Assert(string(data), Equals, "hello")
+
+	_, _, err = object.Get(ctx, 12, 0)
+	c.Assert(err.ToGoError(), Equals, client.InvalidRange{Offset: 12})
+	_, _, err = object.Get(ctx, 11, 0)
+	c.Assert(err.ToGoError(), Equals, client.InvalidRange{Offset: 11})
+	_, _, err = newClient(c, "mem://host/bucket/missing").Get(ctx, 0, 0)
+	c.Assert(err.ToGoError(), Equals, client.ObjectNotFound{Bucket: "bucket", Object: "missing"})
+	err = newClient(c, "mem://host/nobucket/object").Put(ctx, 0, bytes.NewReader(nil))
+	c.Assert(err.ToGoError(), Equals, client.BucketNotFound{Bucket: "nobucket"})
+}
+
+func (s *MySuite) TestList(c *C) {
+	c.Assert(newClient(c, "mem://host/bucket").MakeBucket(context.Background()), IsNil)
+	for _, key := range []string{"b", "dir/sub/c", "dir/a", "dir-x", "a"} {
+		put(c, "mem://host/bucket/"+key, key)
+	}
+
+	// Lexical order, common prefixes rolled up.
+	c.Assert(names(c, newClient(c, "mem://host/bucket/"), false, false), DeepEquals, []string{"a", "b", "dir-x", "dir/"})
+	c.Assert(names(c, newClient(c, "mem://host/bucket/dir/"), false, false), DeepEquals, []string{"a", "sub/"})
+	c.Assert(names(c, newClient(c, "mem://host/bucket/dir/a"), false, false), DeepEquals, []string{"dir/a"})
+
+	// Recursive listings are relative to the folder of the URL, without folders.
+	c.Assert(names(c, newClient(c, "mem://host/bucket/"), true, false), DeepEquals, []string{"a", "b", "dir-x", "dir/a", "dir/sub/c"})
+	c.Assert(names(c, newClient(c, "mem://host/bucket"), true, false), DeepEquals, []string{"bucket/a", "bucket/b", "bucket/dir-x", "bucket/dir/a", "bucket/dir/sub/c"})
+	c.Assert(names(c, newClient(c, "mem://host/bucket/dir/"), true, false), DeepEquals, []string{"a", "sub/c"})
+
+	// Prefixes followed by ‘/’ stat as folders.
+	content, err := newClient(c, "mem://host/bucket/dir").Stat(context.Background())
+	c.Assert(err, IsNil)
+	c.Assert(content.Type.IsDir(), Equals, true)
+	_, err = newClient(c, "mem://host/bucket/di").Stat(context.Background())
+	c.Assert(err, Not(IsNil))
+}
+
+func (s *MySuite) TestIncompleteUpload(c *C) {
+	ctx := context.Background()
+	c.Assert(newClient(c, "mem://host/bucket").MakeBucket(ctx), IsNil)
+
+	object := newClient(c, "mem://host/bucket/object")
+	// Short of the announced size, too small to be multipart.
+	err := object.Put(ctx, 10, bytes.NewReader([]byte("hello")))
+	c.Assert(err, Not(IsNil))
+	_, err = object.Stat(ctx)
+	c.Assert(err, Not(IsNil))
+	c.Assert(names(c, newClient(c, "mem://host/bucket/"), false, true), IsNil)
+
+	// Failed uploads of unknown size are multipart, kept as incomplete ones.
+	err = object.Put(ctx, 0, errorReader{})
+	c.Assert(err, Not(IsNil))
+	c.Assert(names(c, newClient(c, "mem://host/bucket/"), false, true), DeepEquals, []string{"object"})
+
+	c.Assert(object.Remove(ctx, true), IsNil)
+	c.Assert(names(c, newClient(c, "mem://host/bucket/"), false, true), IsNil)
+
+	// Cancelled uploads are kept as incomplete ones as well.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = object.Put(cancelled, 0, bytes.NewReader([]byte("hello")))
+	c.Assert(err.ToGoError(), Equals, context.Canceled)
+	c.Assert(names(c, newClient(c, "mem://host/bucket/"), true, true), DeepEquals, []string{"object"})
+}
+
+// errorReader fails every read.
+type errorReader struct{}
+
+func (errorReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func (s *MySuite) TestBackend(c *C) {
+	backend, ok := client.LookupBackend(client.NewURL("mem://host/bucket"), "")
+	c.Assert(ok, Equals, true)
+	c.Assert(backend.API, Equals, "mem")
+	c.Assert(client.NewURL("mem://host/bucket").Type, Equals, client.URLType(client.Object))
+
+	_, err := New(&client.Config{HostURL: "/tmp/bucket"})
+	c.Assert(err, Not(IsNil))
+}
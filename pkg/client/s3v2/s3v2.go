@@ -17,12 +17,16 @@
 package s3v2
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/minio/mc/pkg/client"
@@ -33,32 +37,174 @@ import (
 
 type s3Client struct {
 	api     minio.API
+	cached  *cachedAPI // api is lent from it to operations with contexts, see withContext.
 	hostURL *client.URL
+	timeout time.Duration
+	lookup  string // bucket lookup style of the host.
+}
+
+// cachedAPI - API client of a host along with the config it was instantiated from, the signer of
+// its requests and its clients idle between operations with contexts, see lend.
+type cachedAPI struct {
+	api    minio.API
+	config minio.Config
+	signer client.Signer
+
+	mutex sync.Mutex
+	idle  []lentAPI
+}
+
+// lentAPI - API client whose requests are tied to the context of the operation it is lent to.
+type lentAPI struct {
+	api       minio.API
+	transport *client.ContextTransport
+}
+
+// apiCache holds API clients by host and credentials, they are safe for concurrent use
+// and are reused across objects.
+var apiCache = struct {
+	sync.Mutex
+	apis map[string]*cachedAPI
+}{apis: make(map[string]*cachedAPI)}
+
+func init() {
+	client.RegisterBackend(client.Backend{
+		API:        "S3v2",
+		HostConfig: true,
+		New:        New,
+	})
 }
 
 // New returns an initialized s3Client structure. if debug use a internal trace transport
 func New(config *client.Config) (client.Client, *probe.Error) {
 	u := client.NewURL(config.HostURL)
-	transport := http.DefaultTransport
+	cached, err := getAPI(config, u)
+	if err != nil {
+		return nil, err.Trace()
+	}
+	return &s3Client{api: cached.api, cached: cached, hostURL: u, timeout: config.Timeout, lookup: config.Lookup}, nil
+}
+
+// getAPI returns a cached API client for the host of u, or initializes a new one
+func getAPI(config *client.Config, u *client.URL) (*cachedAPI, *probe.Error) {
+	endpoint := u.Scheme + u.SchemeSeparator + u.Host
+	key := strings.Join([]string{endpoint, config.AccessKeyID, config.SecretAccessKey, config.SessionToken, strconv.FormatBool(config.Refresh != nil), strconv.FormatBool(config.Debug),
+		strconv.FormatFloat(config.RequestsPerSecond, 'g', -1, 64), config.Timeout.String(), fmt.Sprintf("%+v", config.Transport), config.Lookup, config.Region,
+		config.ClockOffset.String(), strconv.FormatBool(config.AutoClockOffset), fmt.Sprintf("%p %p %p %p", config.Record, config.Replay, config.Faults, config.HAR)}, "\x00")
+
+	apiCache.Lock()
+	defer apiCache.Unlock()
+	if cached, ok := apiCache.apis[key]; ok {
+		return cached, nil
+	}
+
+	var transport http.RoundTripper
+	if config.Replay != nil {
+		transport = config.Replay
+	} else {
+		shared, err := client.SharedTransport(config.Timeout, config.Transport)
+		if err != nil {
+			return nil, err.Trace(endpoint)
+		}
+		transport = client.NewRateLimitedTransport(endpoint, config.RequestsPerSecond, shared)
+	}
+	transport = client.NewClockTransport(transport)
+	if config.Faults != nil {
+		transport = httptracer.NewFaultTransport(*config.Faults, transport)
+	}
+	if config.Record != nil {
+		transport = config.Record.Transport(transport)
+	}
+	if config.HAR != nil {
+		transport = httptracer.GetNewTraceTransport(config.HAR, transport)
+	}
 	if config.Debug == true {
-		transport = httptracer.GetNewTraceTransport(NewTrace(), http.DefaultTransport)
+		transport = httptracer.GetNewTraceTransport(NewTrace(), transport)
+	}
+
+	cached := &cachedAPI{}
+	creds := client.Credentials{AccessKeyID: config.AccessKeyID, SecretAccessKey: config.SecretAccessKey, SessionToken: config.SessionToken}
+	credentials := func() client.Credentials { return creds }
+	if config.Refresh != nil {
+		credentials = client.NewCredentialsCache(creds, config.Refresh).Get
 	}
+	offset := config.ClockOffset
+	clockOffset := func() time.Duration { return offset }
+	if config.AutoClockOffset {
+		clockOffset = func() time.Duration { return client.DetectClockSkew(transport, endpoint+"/") }
+	}
+	cached.signer = client.Signer{API: "S3v2", Lookup: config.Lookup, Credentials: credentials, ClockOffset: clockOffset}
+	region := config.Region
+	if region == "" {
+		region = client.GuessRegion(u, config.Lookup)
+	}
+	// minio-go addresses buckets by path once it is given a region, the signer moves them to the
+	// host name if need be.
 	s3Conf := minio.Config{
 		AccessKeyID:     config.AccessKeyID,
 		SecretAccessKey: config.SecretAccessKey,
-		Transport:       transport,
-		Endpoint:        u.Scheme + u.SchemeSeparator + u.Host,
+		Transport:       client.NewSigningTransport(cached.signer, transport),
+		Endpoint:        endpoint,
+		Region:          region,
 	}
-	s3Conf.AccessKeyID = config.AccessKeyID
-	s3Conf.SecretAccessKey = config.SecretAccessKey
-	s3Conf.Transport = transport
 	s3Conf.SetUserAgent(config.AppName, config.AppVersion, config.AppComments...)
-	s3Conf.Endpoint = u.Scheme + u.SchemeSeparator + u.Host
 	api, err := minio.New(s3Conf)
 	if err != nil {
 		return nil, probe.NewError(err)
 	}
-	return &s3Client{api: api, hostURL: u}, nil
+	cached.api, cached.config = api, s3Conf
+	apiCache.apis[key] = cached
+	return cached, nil
+}
+
+// lend returns an API client whose requests are aborted once ctx is done, and the function
+// giving it back once the operation and its requests are over. minio-go takes no contexts, so
+// a client serves one operation at a time; clients given back are reused by later operations,
+// there are no more of them than operations run at once.
+func (a *cachedAPI) lend(ctx context.Context) (minio.API, func()) {
+	if ctx.Done() == nil {
+		return a.api, func() {}
+	}
+	a.mutex.Lock()
+	var lent lentAPI
+	if n := len(a.idle); n > 0 {
+		lent, a.idle = a.idle[n-1], a.idle[:n-1]
+	}
+	a.mutex.Unlock()
+	if lent.api == nil {
+		config := a.config
+		lent.transport = client.NewContextTransport(config.Transport)
+		config.Transport = lent.transport
+		api, err := minio.New(config)
+		if err != nil {
+			// Not reached, config has been validated by getAPI already.
+			return a.api, func() {}
+		}
+		lent.api = api
+	}
+	lent.transport.Bind(ctx)
+	return lent.api, func() {
+		a.mutex.Lock()
+		a.idle = append(a.idle, lent)
+		a.mutex.Unlock()
+	}
+}
+
+// withContext returns a copy of the client whose requests are aborted once ctx is done, and
+// the function to call once the operation and its requests are over.
+func (c *s3Client) withContext(ctx context.Context) (*s3Client, func()) {
+	bound := *c
+	var done func()
+	bound.api, done = c.cached.lend(ctx)
+	return &bound, done
+}
+
+// operationContext bounds a metadata operation by the host's timeout.
+func (c *s3Client) operationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
 }
 
 // URL get url
@@ -66,30 +212,57 @@ func (c *s3Client) URL() *client.URL {
 	return c.hostURL
 }
 
-// Get - get object
-func (c *s3Client) Get(offset, length int64) (io.ReadCloser, int64, *probe.Error) {
+// Get - get object from offset, length 0 reads till the end. Missing objects and offsets
+// past the end fail with client.ObjectNotFound and client.InvalidRange.
+func (c *s3Client) Get(ctx context.Context, offset, length int64) (io.ReadCloser, int64, *probe.Error) {
 	bucket, object := c.url2BucketAndObject()
-	reader, metadata, err := c.api.GetPartialObject(bucket, object, offset, length)
+	bound, done := c.withContext(ctx)
+	defer done()
+	api := bound.api
+	if offset == 0 && length > 0 {
+		// GetPartialObject asks for the last length bytes in this case, read from the start instead.
+		reader, metadata, err := api.GetObject(bucket, object)
+		if err != nil {
+			return nil, length, probe.NewError(c.toObjectError(ctx, err, bucket, object, offset))
+		}
+		if metadata.Size > length {
+			return limitedReadCloser{io.LimitReader(reader, length), reader}, length, nil
+		}
+		return reader, metadata.Size, nil
+	}
+	reader, metadata, err := api.GetPartialObject(bucket, object, offset, length)
 	if err != nil {
-		return nil, length, probe.NewError(err)
+		return nil, length, probe.NewError(c.toObjectError(ctx, err, bucket, object, offset))
 	}
 	return reader, metadata.Size, nil
 }
 
+// limitedReadCloser - reads up to a limit from an object, closing the object.
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
 // Remove - remove object or bucket
-func (c *s3Client) Remove(incomplete bool) *probe.Error {
+func (c *s3Client) Remove(ctx context.Context, incomplete bool) *probe.Error {
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
+	bound, done := c.withContext(ctx)
+	defer done()
+	api := bound.api
+
 	bucket, object := c.url2BucketAndObject()
 	if incomplete {
-		errCh := c.api.RemoveIncompleteUpload(bucket, object)
-		return probe.NewError(<-errCh)
+		errCh := api.RemoveIncompleteUpload(bucket, object)
+		return probe.NewError(c.toClientError(ctx, <-errCh))
 	}
 	var err error
 	if object == "" {
-		err = c.api.RemoveBucket(bucket)
+		err = api.RemoveBucket(bucket)
 	} else {
-		err = c.api.RemoveObject(bucket, object)
+		err = api.RemoveObject(bucket, object)
 	}
-	return probe.NewError(err)
+	return probe.NewError(c.toClientError(ctx, err))
 }
 
 // Share - get a usable get object url to share
@@ -99,13 +272,17 @@ func (c *s3Client) ShareDownload(expires time.Duration) (string, *probe.Error) {
 	if err != nil {
 		return "", probe.NewError(err)
 	}
+	presignedURL, err = c.cached.signer.Presign(presignedURL, expires)
+	if err != nil {
+		return "", probe.NewError(err)
+	}
 	return presignedURL, nil
 }
 
 func (c *s3Client) ShareUpload(recursive bool, expires time.Duration, contentType string) (map[string]string, *probe.Error) {
 	bucket, object := c.url2BucketAndObject()
 	p := minio.NewPostPolicy()
-	if err := p.SetExpires(time.Now().UTC().Add(expires)); err != nil {
+	if err := p.SetExpires(time.Now().Add(c.cached.signer.ClockOffset()).UTC().Add(expires)); err != nil {
 		return nil, probe.NewError(err)
 	}
 	if strings.TrimSpace(contentType) != "" || contentType != "" {
@@ -125,16 +302,22 @@ func (c *s3Client) ShareUpload(recursive bool, expires time.Duration, contentTyp
 		}
 	}
 	m, err := c.api.PresignedPostPolicy(p)
+	if err != nil {
+		return nil, probe.NewError(err)
+	}
+	m, err = c.cached.signer.SignPostPolicy(m)
 	return m, probe.NewError(err)
 }
 
 // Put - put object
-func (c *s3Client) Put(size int64, data io.Reader) *probe.Error {
+func (c *s3Client) Put(ctx context.Context, size int64, data io.Reader) *probe.Error {
 	// md5 is purposefully ignored since AmazonS3 does not return proper md5sum
 	// for a multipart upload and there is no need to cross verify,
 	// invidual parts are properly verified
 	bucket, object := c.url2BucketAndObject()
-	err := c.api.PutObject(bucket, object, "application/octet-stream", size, data)
+	bound, done := c.withContext(ctx)
+	defer done()
+	err := bound.api.PutObject(bucket, object, "application/octet-stream", size, data)
 	if err != nil {
 		errResponse := minio.ToErrorResponse(err)
 		if errResponse != nil {
@@ -142,13 +325,13 @@ func (c *s3Client) Put(size int64, data io.Reader) *probe.Error {
 				return probe.NewError(client.ObjectAlreadyExists{Object: object})
 			}
 		}
-		return probe.NewError(err)
+		return probe.NewError(c.toClientError(ctx, err))
 	}
 	return nil
 }
 
 // MakeBucket - make a new bucket
-func (c *s3Client) MakeBucket() *probe.Error {
+func (c *s3Client) MakeBucket(ctx context.Context) *probe.Error {
 	bucket, object := c.url2BucketAndObject()
 	if object != "" {
 		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
@@ -157,60 +340,80 @@ func (c *s3Client) MakeBucket() *probe.Error {
 		return probe.NewError(errors.New("Bucket name is empty."))
 	}
 
-	err := c.api.MakeBucket(bucket, minio.BucketACL("private"))
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
+	bound, done := c.withContext(ctx)
+	defer done()
+	err := bound.api.MakeBucket(bucket, minio.BucketACL("private"))
 	if err != nil {
-		return probe.NewError(err)
+		return probe.NewError(c.toClientError(ctx, err))
 	}
 	return nil
 }
 
 // GetBucketAccess get canned acl on a bucket
-func (c *s3Client) GetBucketAccess() (acl string, error *probe.Error) {
+func (c *s3Client) GetBucketAccess(ctx context.Context) (acl string, error *probe.Error) {
 	bucket, object := c.url2BucketAndObject()
 	if object != "" {
 		return "", probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
 	}
-	bucketACL, err := c.api.GetBucketACL(bucket)
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
+	bound, done := c.withContext(ctx)
+	defer done()
+	bucketACL, err := bound.api.GetBucketACL(bucket)
 	if err != nil {
-		return "", probe.NewError(err)
+		return "", probe.NewError(c.toClientError(ctx, err))
 	}
 	return bucketACL.String(), nil
 }
 
 // SetBucketAccess set canned acl on a bucket
-func (c *s3Client) SetBucketAccess(acl string) *probe.Error {
+func (c *s3Client) SetBucketAccess(ctx context.Context, acl string) *probe.Error {
 	bucket, object := c.url2BucketAndObject()
 	if object != "" {
 		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
 	}
-	err := c.api.SetBucketACL(bucket, minio.BucketACL(acl))
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
+	bound, done := c.withContext(ctx)
+	defer done()
+	err := bound.api.SetBucketACL(bucket, minio.BucketACL(acl))
 	if err != nil {
-		return probe.NewError(err)
+		return probe.NewError(c.toClientError(ctx, err))
 	}
 	return nil
 }
 
 // Stat - send a 'HEAD' on a bucket or object to get its metadata
-func (c *s3Client) Stat() (*client.Content, *probe.Error) {
+func (c *s3Client) Stat(ctx context.Context) (*client.Content, *probe.Error) {
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
+	bound, done := c.withContext(ctx)
+	defer done()
+	api := bound.api
+
 	objectMetadata := new(client.Content)
 	bucket, object := c.url2BucketAndObject()
 	switch {
 	// valid case for s3/...
 	case bucket == "" && object == "":
-		for bucket := range c.api.ListBuckets() {
+		for bucket := range api.ListBuckets() {
 			if bucket.Err != nil {
-				return nil, probe.NewError(bucket.Err)
+				return nil, probe.NewError(c.toClientError(ctx, bucket.Err))
 			}
 		}
 		return &client.Content{Type: os.ModeDir}, nil
 	}
 	if object != "" {
-		metadata, err := c.api.StatObject(bucket, object)
+		metadata, err := api.StatObject(bucket, object)
 		if err != nil {
 			errResponse := minio.ToErrorResponse(err)
 			if errResponse != nil {
 				if errResponse.Code == "NoSuchKey" {
-					for content := range c.List(false, false) {
+					// A name is a folder if objects are stored under it, not if it only
+					// starts names of other objects.
+					for content := range c.folder().List(ctx, false, false) {
 						if content.Err != nil {
 							return nil, content.Err.Trace()
 						}
@@ -221,7 +424,7 @@ func (c *s3Client) Stat() (*client.Content, *probe.Error) {
 					}
 				}
 			}
-			return nil, probe.NewError(err)
+			return nil, probe.NewError(c.toObjectError(ctx, err, bucket, object, 0))
 		}
 		objectMetadata.Name = metadata.Key
 		objectMetadata.Time = metadata.LastModified
@@ -229,9 +432,9 @@ func (c *s3Client) Stat() (*client.Content, *probe.Error) {
 		objectMetadata.Type = os.FileMode(0664)
 		return objectMetadata, nil
 	}
-	err := c.api.BucketExists(bucket)
+	err := api.BucketExists(bucket)
 	if err != nil {
-		return nil, probe.NewError(err)
+		return nil, probe.NewError(c.toClientError(ctx, err))
 	}
 	bucketMetadata := new(client.Content)
 	bucketMetadata.Name = bucket
@@ -239,57 +442,93 @@ func (c *s3Client) Stat() (*client.Content, *probe.Error) {
 	return bucketMetadata, nil
 }
 
+// toClientError converts transient server errors and rejected requests to their typed equivalents,
+// so callers can retry on them or tell what to fix
+func (c *s3Client) toClientError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return client.ContextError(ctx, err)
+	}
+	errResponse := minio.ToErrorResponse(err)
+	if errResponse == nil {
+		return err
+	}
+	switch errResponse.Code {
+	case "SlowDown", "ServiceUnavailable", "503 Service Unavailable":
+		return client.SlowDown{Code: errResponse.Code}
+	case "InternalError", "500 Internal Server Error":
+		return client.InternalError{Code: errResponse.Code}
+	case "RequestTimeout":
+		return client.RequestTimeout{}
+	case "ExpiredToken", "TokenRefreshRequired":
+		return client.CredentialsExpired{Code: errResponse.Code}
+	case "RequestTimeTooSkewed":
+		skew, _ := client.ClockSkew(c.hostURL.Host)
+		return client.ClockSkewed{Skew: skew}
+	case "InvalidAccessKeyId":
+		return client.AccessKeyRejected{Code: errResponse.Code}
+	case "AccessDenied":
+		if c.cached.signer.Credentials().AccessKeyID == "" {
+			return client.AnonymousAccessDenied{Message: errResponse.Message}
+		}
+		return client.AccessDenied{Message: errResponse.Message}
+	case "SignatureDoesNotMatch":
+		return client.SignatureRejected{API: "S3v2", Code: errResponse.Code}
+	}
+	return err
+}
+
+// toObjectError is toClientError for object operations, it also tells missing objects and
+// ranges starting past the end of an object.
+func (c *s3Client) toObjectError(ctx context.Context, err error, bucket, object string, offset int64) error {
+	if errResponse := minio.ToErrorResponse(err); errResponse != nil && ctx.Err() == nil {
+		switch errResponse.Code {
+		case "NoSuchKey":
+			return client.ObjectNotFound{Bucket: bucket, Object: object}
+		case "InvalidRange":
+			return client.InvalidRange{Offset: offset}
+		}
+	}
+	return c.toClientError(ctx, err)
+}
+
+// folder returns a copy of the client with a separator appended to its URL path.
+func (c *s3Client) folder() *s3Client {
+	folder := *c
+	hostURL := *c.hostURL
+	hostURL.Path = strings.TrimSuffix(hostURL.Path, "/") + "/"
+	folder.hostURL = &hostURL
+	return &folder
+}
+
 // url2BucketAndObject gives bucketName and objectName from URL path
 func (c *s3Client) url2BucketAndObject() (bucketName, objectName string) {
-	path := c.hostURL.Path
-	// Convert any virtual host styled requests
-	//
-	// For the time being this check is introduced for S3,
-	// if you have custom virtual styled hosts please. list them below
-	match, _ := filepath.Match("*.s3*.amazonaws.com", c.hostURL.Host)
-	switch {
-	case match == true:
-		hostSplits := strings.SplitN(c.hostURL.Host, ".", 2)
-		path = string(c.hostURL.Separator) + hostSplits[0] + c.hostURL.Path
-	}
-
-	splits := strings.SplitN(path, string(c.hostURL.Separator), 3)
-	switch len(splits) {
-	case 0, 1:
-		bucketName = ""
-		objectName = ""
-	case 2:
-		bucketName = splits[1]
-		objectName = ""
-	case 3:
-		bucketName = splits[1]
-		objectName = splits[2]
-	}
-	return bucketName, objectName
+	return c.hostURL.BucketAndObject(c.lookup)
 }
 
 /// Bucket API operations
 
 // List - list at delimited path, if not recursive
-func (c *s3Client) List(recursive, incomplete bool) <-chan client.ContentOnChannel {
+func (c *s3Client) List(ctx context.Context, recursive, incomplete bool) <-chan client.ContentOnChannel {
 	contentCh := make(chan client.ContentOnChannel)
-	if incomplete {
-		if recursive {
-			go c.listIncompleteRecursiveInRoutine(contentCh)
-		} else {
-			go c.listIncompleteInRoutine(contentCh)
-		}
-	} else {
-		if recursive {
-			go c.listRecursiveInRoutine(contentCh)
-		} else {
-			go c.listInRoutine(contentCh)
-		}
+	// Listing stops on its own once ctx is done, since its requests fail.
+	c, done := c.withContext(ctx)
+	list := c.listInRoutine
+	switch {
+	case incomplete && recursive:
+		list = c.listIncompleteRecursiveInRoutine
+	case incomplete:
+		list = c.listIncompleteInRoutine
+	case recursive:
+		list = c.listRecursiveInRoutine
 	}
-	return contentCh
+	go func() {
+		defer done()
+		list(ctx, contentCh)
+	}()
+	return client.ForwardContents(ctx, contentCh)
 }
 
-func (c *s3Client) listIncompleteInRoutine(contentCh chan client.ContentOnChannel) {
+func (c *s3Client) listIncompleteInRoutine(ctx context.Context, contentCh chan client.ContentOnChannel) {
 	defer close(contentCh)
 	b, o := c.url2BucketAndObject()
 	switch {
@@ -298,7 +537,7 @@ func (c *s3Client) listIncompleteInRoutine(contentCh chan client.ContentOnChanne
 			if bucket.Err != nil {
 				contentCh <- client.ContentOnChannel{
 					Content: nil,
-					Err:     probe.NewError(bucket.Err),
+					Err:     probe.NewError(c.toClientError(ctx, bucket.Err)),
 				}
 				return
 			}
@@ -317,7 +556,7 @@ func (c *s3Client) listIncompleteInRoutine(contentCh chan client.ContentOnChanne
 			if object.Err != nil {
 				contentCh <- client.ContentOnChannel{
 					Content: nil,
-					Err:     probe.NewError(object.Err),
+					Err:     probe.NewError(c.toClientError(ctx, object.Err)),
 				}
 				return
 			}
@@ -345,7 +584,7 @@ func (c *s3Client) listIncompleteInRoutine(contentCh chan client.ContentOnChanne
 	}
 }
 
-func (c *s3Client) listIncompleteRecursiveInRoutine(contentCh chan client.ContentOnChannel) {
+func (c *s3Client) listIncompleteRecursiveInRoutine(ctx context.Context, contentCh chan client.ContentOnChannel) {
 	defer close(contentCh)
 	b, o := c.url2BucketAndObject()
 	switch {
@@ -354,7 +593,7 @@ func (c *s3Client) listIncompleteRecursiveInRoutine(contentCh chan client.Conten
 			if bucket.Err != nil {
 				contentCh <- client.ContentOnChannel{
 					Content: nil,
-					Err:     probe.NewError(bucket.Err),
+					Err:     probe.NewError(c.toClientError(ctx, bucket.Err)),
 				}
 				return
 			}
@@ -362,7 +601,7 @@ func (c *s3Client) listIncompleteRecursiveInRoutine(contentCh chan client.Conten
 				if object.Err != nil {
 					contentCh <- client.ContentOnChannel{
 						Content: nil,
-						Err:     probe.NewError(object.Err),
+						Err:     probe.NewError(c.toClientError(ctx, object.Err)),
 					}
 					return
 				}
@@ -382,7 +621,7 @@ func (c *s3Client) listIncompleteRecursiveInRoutine(contentCh chan client.Conten
 			if object.Err != nil {
 				contentCh <- client.ContentOnChannel{
 					Content: nil,
-					Err:     probe.NewError(object.Err),
+					Err:     probe.NewError(c.toClientError(ctx, object.Err)),
 				}
 				return
 			}
@@ -413,7 +652,7 @@ func (c *s3Client) listIncompleteRecursiveInRoutine(contentCh chan client.Conten
 	}
 }
 
-func (c *s3Client) listInRoutine(contentCh chan client.ContentOnChannel) {
+func (c *s3Client) listInRoutine(ctx context.Context, contentCh chan client.ContentOnChannel) {
 	defer close(contentCh)
 	b, o := c.url2BucketAndObject()
 	switch {
@@ -422,7 +661,7 @@ func (c *s3Client) listInRoutine(contentCh chan client.ContentOnChannel) {
 			if bucket.Err != nil {
 				contentCh <- client.ContentOnChannel{
 					Content: nil,
-					Err:     probe.NewError(bucket.Err),
+					Err:     probe.NewError(c.toClientError(ctx, bucket.Err)),
 				}
 				return
 			}
@@ -454,7 +693,7 @@ func (c *s3Client) listInRoutine(contentCh chan client.ContentOnChannel) {
 				if object.Err != nil {
 					contentCh <- client.ContentOnChannel{
 						Content: nil,
-						Err:     probe.NewError(object.Err),
+						Err:     probe.NewError(c.toClientError(ctx, object.Err)),
 					}
 					return
 				}
@@ -483,7 +722,7 @@ func (c *s3Client) listInRoutine(contentCh chan client.ContentOnChannel) {
 	}
 }
 
-func (c *s3Client) listRecursiveInRoutine(contentCh chan client.ContentOnChannel) {
+func (c *s3Client) listRecursiveInRoutine(ctx context.Context, contentCh chan client.ContentOnChannel) {
 	defer close(contentCh)
 	b, o := c.url2BucketAndObject()
 	switch {
@@ -492,7 +731,7 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan client.ContentOnChannel
 			if bucket.Err != nil {
 				contentCh <- client.ContentOnChannel{
 					Content: nil,
-					Err:     probe.NewError(bucket.Err),
+					Err:     probe.NewError(c.toClientError(ctx, bucket.Err)),
 				}
 				return
 			}
@@ -500,7 +739,7 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan client.ContentOnChannel
 				if object.Err != nil {
 					contentCh <- client.ContentOnChannel{
 						Content: nil,
-						Err:     probe.NewError(object.Err),
+						Err:     probe.NewError(c.toClientError(ctx, object.Err)),
 					}
 					return
 				}
@@ -520,7 +759,7 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan client.ContentOnChannel
 			if object.Err != nil {
 				contentCh <- client.ContentOnChannel{
 					Content: nil,
-					Err:     probe.NewError(object.Err),
+					Err:     probe.NewError(c.toClientError(ctx, object.Err)),
 				}
 				return
 			}
@@ -19,6 +19,7 @@ package s3v2
 // bucketHandler is an http.Handler that verifies bucket responses and validates incoming requests
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -108,7 +109,9 @@ func (h objectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	case r.Method == "GET":
 		if r.URL.Path != h.resource {
+			w.Header().Set("Content-Type", "application/xml")
 			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>"))
 			return
 		}
 		w.Header().Set("Content-Length", strconv.Itoa(len(h.data)))
@@ -137,17 +140,17 @@ func (s *MySuite) TestBucketOperations(c *C) {
 	s3c, err := New(conf)
 	c.Assert(err, IsNil)
 
-	err = s3c.MakeBucket()
+	err = s3c.MakeBucket(context.Background())
 	c.Assert(err, IsNil)
 
-	err = s3c.SetBucketAccess("public-read-write")
+	err = s3c.SetBucketAccess(context.Background(), "public-read-write")
 	c.Assert(err, IsNil)
 
 	conf.HostURL = server.URL + string(s3c.URL().Separator)
 	s3c, err = New(conf)
 	c.Assert(err, IsNil)
 
-	for content := range s3c.List(false, false) {
+	for content := range s3c.List(context.Background(), false, false) {
 		c.Assert(content.Err, IsNil)
 		c.Assert(content.Content.Name, Equals, "bucket")
 		c.Assert(content.Content.Type.IsDir(), Equals, true)
@@ -157,7 +160,7 @@ func (s *MySuite) TestBucketOperations(c *C) {
 	s3c, err = New(conf)
 	c.Assert(err, IsNil)
 
-	for content := range s3c.List(false, false) {
+	for content := range s3c.List(context.Background(), false, false) {
 		c.Assert(content.Err, IsNil)
 		c.Assert(content.Content.Name, Equals, "object")
 		c.Assert(content.Content.Type.IsRegular(), Equals, true)
@@ -177,16 +180,16 @@ func (s *MySuite) TestObjectOperations(c *C) {
 	s3c, err := New(conf)
 	c.Assert(err, IsNil)
 
-	err = s3c.Put(int64(len(object.data)), bytes.NewReader(object.data))
+	err = s3c.Put(context.Background(), int64(len(object.data)), bytes.NewReader(object.data))
 	c.Assert(err, IsNil)
 
-	content, err := s3c.Stat()
+	content, err := s3c.Stat(context.Background())
 	c.Assert(err, IsNil)
 	c.Assert(content.Name, Equals, "object")
 	c.Assert(content.Size, Equals, int64(len(object.data)))
 	c.Assert(content.Type.IsRegular(), Equals, true)
 
-	reader, size, err := s3c.Get(0, 0)
+	reader, size, err := s3c.Get(context.Background(), 0, 0)
 	c.Assert(size, Equals, int64(len(object.data)))
 
 	var buffer bytes.Buffer
@@ -196,3 +199,67 @@ func (s *MySuite) TestObjectOperations(c *C) {
 		c.Assert(buffer.Bytes(), DeepEquals, object.data)
 	}
 }
+
+func (s *MySuite) TestGetRange(c *C) {
+	object := objectHandler(objectHandler{
+		resource: "/bucket/object",
+		data:     []byte("Hello, World"),
+	})
+	server := httptest.NewServer(object)
+	defer server.Close()
+
+	conf := new(client.Config)
+	conf.HostURL = server.URL + object.resource
+	s3c, err := New(conf)
+	c.Assert(err, IsNil)
+
+	// A range from the start is read from the start, not from the end.
+	reader, size, err := s3c.Get(context.Background(), 0, 5)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(5))
+	var buffer bytes.Buffer
+	_, e := io.Copy(&buffer, reader)
+	reader.Close()
+	c.Assert(e, IsNil)
+	c.Assert(buffer.String(), Equals, "Hello")
+
+	conf.HostURL = server.URL + "/bucket/missing"
+	s3c, err = New(conf)
+	c.Assert(err, IsNil)
+	_, _, err = s3c.Get(context.Background(), 0, 0)
+	c.Assert(err.ToGoError(), FitsTypeOf, client.ObjectNotFound{})
+}
+
+func (s *MySuite) TestClientReuse(c *C) {
+	object := objectHandler(objectHandler{
+		resource: "/bucket/object",
+		data:     []byte("Hello, World"),
+	})
+	server := httptest.NewServer(object)
+	defer server.Close()
+
+	conf := new(client.Config)
+	conf.HostURL = server.URL + object.resource
+	s3c, err := New(conf)
+	c.Assert(err, IsNil)
+	cached := s3c.(*s3Client).cached
+
+	// Operations one after the other share a client, each with its own context.
+	for i := 0; i < 3; i++ {
+		_, err = s3c.Stat(context.Background())
+		c.Assert(err, IsNil)
+	}
+	c.Assert(len(cached.idle), Equals, 1)
+
+	// Operations at once get a client each.
+	api, _ := cached.lend(context.Background())
+	c.Assert(api, Equals, cached.api)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first, doneFirst := cached.lend(ctx)
+	second, doneSecond := cached.lend(ctx)
+	c.Assert(first == second, Equals, false)
+	doneFirst()
+	doneSecond()
+	c.Assert(len(cached.idle), Equals, 2)
+}
@@ -19,7 +19,6 @@ package s3v2
 import (
 	"net/http"
 	"net/http/httputil"
-	"strings"
 
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/mc/pkg/httptracer"
@@ -33,25 +32,11 @@ func NewTrace() httptracer.HTTPTracer {
 	return Trace{}
 }
 
-// Request - Trace HTTP Request
+// Request - Trace HTTP Request, with its secrets redacted
 func (t Trace) Request(req *http.Request) (err error) {
-	origAuth := req.Header.Get("Authorization")
-
-	if strings.TrimSpace(origAuth) != "" {
-		// Authorization (S3 v2 signature) Format:
-		// Authorization: AWS AKIAJVA5BMMU2RHO6IO1:Y10YHUZ0DTUterAUI6w3XKX7Iqk=
-
-		// Set a temporary redacted auth
-		req.Header.Set("Authorization", "AWS **REDACTED**:**REDACTED**")
-
-		var reqTrace []byte
-		reqTrace, err = httputil.DumpRequestOut(req, false) // Only display header
-		if err == nil {
-			console.Debug(string(reqTrace))
-		}
-
-		// Undo
-		req.Header.Set("Authorization", origAuth)
+	reqTrace, err := httputil.DumpRequestOut(httptracer.RedactRequest(req), false) // Only display header
+	if err == nil {
+		console.Debug(string(reqTrace))
 	}
 	return err
 }
@@ -0,0 +1,446 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// signature related constants.
+const (
+	signV4Algorithm = "AWS4-HMAC-SHA256"
+	iso8601Format   = "20060102T150405Z"
+	yyyymmdd        = "20060102"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// regions - regions of Amazon S3 endpoints, by host name.
+var regions = map[string]string{
+	"s3-fips-us-gov-west-1.amazonaws.com": "us-gov-west-1",
+	"s3.amazonaws.com":                    "us-east-1",
+	"s3-external-1.amazonaws.com":         "us-east-1",
+	"s3-us-west-1.amazonaws.com":          "us-west-1",
+	"s3-us-west-2.amazonaws.com":          "us-west-2",
+	"s3-eu-west-1.amazonaws.com":          "eu-west-1",
+	"s3-eu-central-1.amazonaws.com":       "eu-central-1",
+	"s3-ap-southeast-1.amazonaws.com":     "ap-southeast-1",
+	"s3-ap-southeast-2.amazonaws.com":     "ap-southeast-2",
+	"s3-ap-northeast-1.amazonaws.com":     "ap-northeast-1",
+	"s3-sa-east-1.amazonaws.com":          "sa-east-1",
+	"s3.cn-north-1.amazonaws.com.cn":      "cn-north-1",
+}
+
+// GuessRegion returns the region of the host of URL from its name, as minio-go does. Hosts other
+// than those of Amazon S3 are taken to accept any region.
+func GuessRegion(u *URL, lookup string) string {
+	host := u.Host
+	if hostSplits := strings.SplitN(host, ".", 2); u.IsVirtualHostStyle(lookup) && len(hostSplits) == 2 {
+		host = hostSplits[1]
+	}
+	if region, ok := regions[host]; ok {
+		return region
+	}
+	return "milkyway"
+}
+
+// subResources - query parameters which are part of the resource signed with signature V2, sorted.
+var subResources = []string{
+	"acl", "location", "logging", "notification", "partNumber", "policy", "requestPayment",
+	"response-cache-control", "response-content-disposition", "response-content-encoding",
+	"response-content-language", "response-content-type", "response-expires",
+	"torrent", "uploadId", "uploads", "versionId", "versioning", "versions", "website",
+}
+
+// unsignedHeaders - headers left out of signatures V4, as they may be changed on the way.
+var unsignedHeaders = map[string]bool{
+	"Authorization":  true,
+	"Content-Type":   true,
+	"Content-Length": true,
+	"User-Agent":     true,
+}
+
+// Signer addresses and signs requests to an S3 host on behalf of minio-go, which sends them path
+// styled and signed for the time, credentials and region it was instantiated with. Session tokens,
+// the bucket lookup style of the host, regions of buckets and clock offsets apply to every request
+// sent through NewSigningTransport, presigned URL and post policy this way.
+type Signer struct {
+	// API is the signature of requests, "S3v4" or "S3v2".
+	API string
+	// Lookup is the bucket lookup style of the host, buckets move from the path to the host name
+	// of requests for hosts addressed virtual host style.
+	Lookup string
+	// Credentials returns the credentials to sign with, requests are sent unsigned without an
+	// access key.
+	Credentials func() Credentials
+	// Region returns the region requests on bucket are signed for with signature V4, bucket is
+	// empty for requests on the service and for bucket locations. ctx is that of the request.
+	Region func(ctx context.Context, bucket string) string
+	// ClockOffset returns the offset added to the local clock when dating requests.
+	ClockOffset func() time.Duration
+}
+
+// now - local time corrected by the clock offset, in UTC.
+func (s Signer) now() time.Time {
+	return time.Now().Add(s.ClockOffset()).UTC()
+}
+
+// address moves the bucket of a path styled u to its host name if the host is addressed virtual
+// host style, see URL.IsVirtualHostStyle. It returns the bucket and whether it was moved.
+func (s Signer) address(u *url.URL) (bucket string, virtual bool) {
+	path := strings.TrimPrefix(u.Path, "/")
+	bucket = path
+	if i := strings.Index(path, "/"); i >= 0 {
+		bucket = path[:i]
+	}
+	if bucket == "" || !(&URL{Host: u.Host}).IsVirtualHostStyle(s.Lookup) {
+		return bucket, false
+	}
+	// The host name holds the bucket already, bucket names need no escaping.
+	u.Path = strings.TrimPrefix(u.Path, "/"+bucket)
+	u.RawPath = strings.TrimPrefix(u.RawPath, "/"+bucket)
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	if u.RawPath == "/" {
+		u.RawPath = ""
+	}
+	return bucket, true
+}
+
+// region returns the region a request on bucket with query is signed for.
+func (s Signer) region(ctx context.Context, bucket string, query url.Values) string {
+	if _, ok := query["location"]; ok {
+		// Locations of buckets are what regions are discovered from.
+		bucket = ""
+	}
+	return s.Region(ctx, bucket)
+}
+
+// signingTransport signs requests sent through it, see Signer.
+type signingTransport struct {
+	signer    Signer
+	transport http.RoundTripper
+}
+
+// RoundTrip sends a copy of the request addressed and signed by the signer.
+func (t signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	bucket, virtual := t.signer.address(req.URL)
+	req.Header.Del("Authorization")
+	creds := t.signer.Credentials()
+	if creds.AccessKeyID == "" {
+		return t.transport.RoundTrip(req)
+	}
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	} else {
+		req.Header.Del("X-Amz-Security-Token")
+	}
+	now := t.signer.now()
+	if t.signer.API == "S3v2" {
+		req.Header.Set("Date", now.Format(http.TimeFormat))
+		signature := signV2(creds, req.Method, req.Header, req.Header.Get("Date"), canonicalResource(req.URL, bucket, virtual))
+		req.Header.Set("Authorization", "AWS "+creds.AccessKeyID+":"+signature)
+		return t.transport.RoundTrip(req)
+	}
+	req.Header.Set("X-Amz-Date", now.Format(iso8601Format))
+	hashedPayload := req.Header.Get("X-Amz-Content-Sha256")
+	if hashedPayload == "" {
+		// Only bodies read by minio-go to sign them are hashed already.
+		hashedPayload = unsignedPayload
+		if req.Body == nil || req.Body == http.NoBody {
+			hashedPayload = hex.EncodeToString(sum256(nil))
+		}
+		req.Header.Set("X-Amz-Content-Sha256", hashedPayload)
+	}
+	var headers []string
+	for name := range req.Header {
+		if !unsignedHeaders[http.CanonicalHeaderKey(name)] {
+			headers = append(headers, strings.ToLower(name))
+		}
+	}
+	headers = append(headers, "host")
+	sort.Strings(headers)
+	signedHeaders := strings.Join(headers, ";")
+	scope := signingScope(t.signer.region(req.Context(), bucket, req.URL.Query()), now)
+	req.URL.RawQuery = canonicalQuery(req.URL.Query())
+	signature := signV4(creds, scope, now, canonicalRequestV4(req.Method, req.URL, req.Header, signedHeaders, hashedPayload))
+	req.Header.Set("Authorization", signV4Algorithm+" Credential="+creds.AccessKeyID+"/"+scope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+	return t.transport.RoundTrip(req)
+}
+
+// NewSigningTransport - requests sent through transport are addressed and signed by signer.
+func NewSigningTransport(signer Signer, transport http.RoundTripper) http.RoundTripper {
+	return signingTransport{signer: signer, transport: transport}
+}
+
+// Presign addresses and signs urlStr presigned by minio-go, so that it expires after expires.
+func (s Signer) Presign(urlStr string, expires time.Duration) (string, error) {
+	u, e := url.Parse(urlStr)
+	if e != nil {
+		return "", e
+	}
+	bucket, virtual := s.address(u)
+	creds := s.Credentials()
+	if creds.AccessKeyID == "" {
+		return "", errors.New("presign requires accesskey and secretkey")
+	}
+	now := s.now()
+	query := u.Query()
+	if s.API == "S3v2" {
+		expiry := strconv.FormatInt(now.Add(expires).Unix(), 10)
+		header := make(http.Header)
+		if creds.SessionToken != "" {
+			// Signed as if it were a header, though sent in the query.
+			header.Set("X-Amz-Security-Token", creds.SessionToken)
+			query.Set("x-amz-security-token", creds.SessionToken)
+		}
+		query.Set("AWSAccessKeyId", creds.AccessKeyID)
+		query.Set("Expires", expiry)
+		query.Set("Signature", signV2(creds, "GET", header, expiry, canonicalResource(u, bucket, virtual)))
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	}
+	scope := signingScope(s.region(context.Background(), bucket, query), now)
+	query.Del("X-Amz-Signature")
+	query.Set("X-Amz-Algorithm", signV4Algorithm)
+	query.Set("X-Amz-Credential", creds.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", now.Format(iso8601Format))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if creds.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", creds.SessionToken)
+	} else {
+		query.Del("X-Amz-Security-Token")
+	}
+	u.RawQuery = canonicalQuery(query)
+	signature := signV4(creds, scope, now, canonicalRequestV4("GET", u, nil, "host", unsignedPayload))
+	u.RawQuery += "&X-Amz-Signature=" + signature
+	return u.String(), nil
+}
+
+// postPolicy - policy document of a browser upload.
+type postPolicy struct {
+	Expiration string        `json:"expiration"`
+	Conditions []interface{} `json:"conditions"`
+}
+
+// SignPostPolicy signs the form data of a browser upload presigned by minio-go again, dated and
+// with credentials as requests are.
+func (s Signer) SignPostPolicy(formData map[string]string) (map[string]string, error) {
+	document, e := base64.StdEncoding.DecodeString(formData["policy"])
+	if e != nil {
+		return nil, e
+	}
+	var policy postPolicy
+	if e := json.Unmarshal(document, &policy); e != nil {
+		return nil, e
+	}
+	creds := s.Credentials()
+	if creds.AccessKeyID == "" {
+		return nil, errors.New("presign requires accesskey and secretkey")
+	}
+	now := s.now()
+	fields := make(map[string]string)
+	if s.API != "S3v2" {
+		fields["x-amz-algorithm"] = signV4Algorithm
+		fields["x-amz-date"] = now.Format(iso8601Format)
+		fields["x-amz-credential"] = creds.AccessKeyID + "/" + signingScope(s.Region(context.Background(), formData["bucket"]), now)
+	}
+	if creds.SessionToken != "" {
+		fields["x-amz-security-token"] = creds.SessionToken
+	}
+	// Drop conditions on the fields set here, they are added back with their values.
+	conditions := policy.Conditions[:0]
+	for _, condition := range policy.Conditions {
+		if condition, ok := condition.([]interface{}); ok && len(condition) == 3 {
+			if field, ok := condition[1].(string); ok {
+				if _, ok := fields[strings.TrimPrefix(strings.ToLower(field), "$")]; ok {
+					continue
+				}
+			}
+		}
+		conditions = append(conditions, condition)
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		conditions = append(conditions, []interface{}{"eq", "$" + name, fields[name]})
+		formData[name] = fields[name]
+	}
+	policy.Conditions = conditions
+	document, e = json.Marshal(policy)
+	if e != nil {
+		return nil, e
+	}
+	policyBase64 := base64.StdEncoding.EncodeToString(document)
+	formData["policy"] = policyBase64
+	if s.API == "S3v2" {
+		hm := hmac.New(sha1.New, []byte(creds.SecretAccessKey))
+		hm.Write([]byte(policyBase64))
+		formData["AWSAccessKeyId"] = creds.AccessKeyID
+		formData["signature"] = base64.StdEncoding.EncodeToString(hm.Sum(nil))
+		return formData, nil
+	}
+	scope := strings.SplitN(fields["x-amz-credential"], "/", 2)[1]
+	formData["x-amz-signature"] = hex.EncodeToString(sumHMAC(signingKey(creds, scope), []byte(policyBase64)))
+	return formData, nil
+}
+
+// signingScope - scope of signatures V4 made at t for region.
+func signingScope(region string, t time.Time) string {
+	return strings.Join([]string{t.Format(yyyymmdd), region, "s3", "aws4_request"}, "/")
+}
+
+// signingKey - key deriving signatures V4 within scope.
+func signingKey(creds Credentials, scope string) []byte {
+	key := []byte("AWS4" + creds.SecretAccessKey)
+	for _, part := range strings.Split(scope, "/") {
+		key = sumHMAC(key, []byte(part))
+	}
+	return key
+}
+
+// signV4 - signature V4 of canonicalRequest, made at t within scope.
+func signV4(creds Credentials, scope string, t time.Time, canonicalRequest string) string {
+	stringToSign := signV4Algorithm + "\n" + t.Format(iso8601Format) + "\n" + scope + "\n" + hex.EncodeToString(sum256([]byte(canonicalRequest)))
+	return hex.EncodeToString(sumHMAC(signingKey(creds, scope), []byte(stringToSign)))
+}
+
+// canonicalQuery - query as signed with signature V4, spaces escaped as %20.
+func canonicalQuery(query url.Values) string {
+	return strings.Replace(query.Encode(), "+", "%20", -1)
+}
+
+// canonicalRequestV4 - canonical form of a request signed with signature V4.
+func canonicalRequestV4(method string, u *url.URL, header http.Header, signedHeaders, hashedPayload string) string {
+	var headers bytes.Buffer
+	for _, name := range strings.Split(signedHeaders, ";") {
+		headers.WriteString(name + ":")
+		if name == "host" {
+			headers.WriteString(u.Host)
+		} else {
+			headers.WriteString(strings.Join(header[http.CanonicalHeaderKey(name)], ","))
+		}
+		headers.WriteByte('\n')
+	}
+	return strings.Join([]string{
+		method,
+		encodePath(u.Path),
+		u.RawQuery,
+		headers.String(),
+		signedHeaders,
+		hashedPayload,
+	}, "\n")
+}
+
+// canonicalResource - resource of a request on bucket signed with signature V2, which carries
+// the bucket in its host name if virtual.
+func canonicalResource(u *url.URL, bucket string, virtual bool) string {
+	path := u.Path
+	if virtual {
+		path = "/" + bucket + path
+	}
+	var buf bytes.Buffer
+	buf.WriteString(encodePath(path))
+	query := u.Query()
+	separator := "?"
+	for _, resource := range subResources {
+		values, ok := query[resource]
+		if !ok {
+			continue
+		}
+		buf.WriteString(separator + resource)
+		if len(values) > 0 && values[0] != "" {
+			buf.WriteString("=" + url.QueryEscape(values[0]))
+		}
+		separator = "&"
+	}
+	return buf.String()
+}
+
+// signV2 - signature V2 of a request with header, dated date, on resource.
+func signV2(creds Credentials, method string, header http.Header, date, resource string) string {
+	var buf bytes.Buffer
+	buf.WriteString(method + "\n")
+	buf.WriteString(header.Get("Content-MD5") + "\n")
+	buf.WriteString(header.Get("Content-Type") + "\n")
+	buf.WriteString(date + "\n")
+	var amzHeaders []string
+	for name := range header {
+		if lname := strings.ToLower(name); strings.HasPrefix(lname, "x-amz") {
+			amzHeaders = append(amzHeaders, lname)
+		}
+	}
+	sort.Strings(amzHeaders)
+	for _, name := range amzHeaders {
+		buf.WriteString(name + ":" + strings.Join(header[http.CanonicalHeaderKey(name)], ",") + "\n")
+	}
+	buf.WriteString(resource)
+	hm := hmac.New(sha1.New, []byte(creds.SecretAccessKey))
+	hm.Write(buf.Bytes())
+	return base64.StdEncoding.EncodeToString(hm.Sum(nil))
+}
+
+func sum256(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+func sumHMAC(key, data []byte) []byte {
+	hm := hmac.New(sha256.New, key)
+	hm.Write(data)
+	return hm.Sum(nil)
+}
+
+// encodePath - URI encoding of path as signed by S3 clients, leaving only unreserved characters as they are.
+func encodePath(path string) string {
+	var buf bytes.Buffer
+	for _, r := range path {
+		switch {
+		case 'A' <= r && r <= 'Z', 'a' <= r && r <= 'z', '0' <= r && r <= '9':
+			buf.WriteRune(r)
+		case r == '-', r == '_', r == '.', r == '~', r == '/':
+			buf.WriteRune(r)
+		default:
+			b := make([]byte, utf8.RuneLen(r))
+			utf8.EncodeRune(b, r)
+			for _, c := range b {
+				buf.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+			}
+		}
+	}
+	return buf.String()
+}
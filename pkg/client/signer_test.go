@@ -0,0 +1,142 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// captureTransport keeps the last request sent through it, replying with no content.
+type captureTransport struct {
+	req *http.Request
+}
+
+func (t *captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.req = req
+	return &http.Response{StatusCode: http.StatusNoContent, Body: ioutil.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+func (s *MySuite) TestSigningTransport(c *C) {
+	var regionBuckets []string
+	signer := Signer{
+		API:    "S3v4",
+		Lookup: LookupDNS,
+		Credentials: func() Credentials {
+			return Credentials{AccessKeyID: "access", SecretAccessKey: "secret", SessionToken: "token"}
+		},
+		Region: func(ctx context.Context, bucket string) string {
+			regionBuckets = append(regionBuckets, bucket)
+			return "eu-west-1"
+		},
+		ClockOffset: func() time.Duration { return 24 * time.Hour },
+	}
+	capture := new(captureTransport)
+	transport := NewSigningTransport(signer, capture)
+
+	// Buckets move from the path to the host name, requests are signed for their region and dated
+	// by the offset clock.
+	req, e := http.NewRequest("GET", "http://bucket.example.test/bucket/object", nil)
+	c.Assert(e, IsNil)
+	req.Header.Set("Authorization", "signed by minio-go")
+	_, e = transport.RoundTrip(req)
+	c.Assert(e, IsNil)
+	c.Assert(req.Header.Get("Authorization"), Equals, "signed by minio-go")
+	c.Assert(capture.req.URL.Path, Equals, "/object")
+	c.Assert(capture.req.Header.Get("X-Amz-Security-Token"), Equals, "token")
+	c.Assert(capture.req.Header.Get("Authorization"), Matches, "AWS4-HMAC-SHA256 Credential=access/[0-9]{8}/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token, Signature=[0-9a-f]{64}")
+	date, e := time.Parse(iso8601Format, capture.req.Header.Get("X-Amz-Date"))
+	c.Assert(e, IsNil)
+	c.Assert(date.After(time.Now().Add(23*time.Hour)), Equals, true)
+	c.Assert(regionBuckets, DeepEquals, []string{"bucket"})
+
+	// Locations of buckets are asked for signed for the region of the host.
+	req, e = http.NewRequest("GET", "http://bucket.example.test/bucket?location", nil)
+	c.Assert(e, IsNil)
+	_, e = transport.RoundTrip(req)
+	c.Assert(e, IsNil)
+	c.Assert(capture.req.URL.Path, Equals, "/")
+	c.Assert(regionBuckets, DeepEquals, []string{"bucket", ""})
+
+	// Signature V2 signs the bucket along with the path.
+	signer.API = "S3v2"
+	req, e = http.NewRequest("PUT", "http://bucket.example.test/bucket/object", nil)
+	c.Assert(e, IsNil)
+	_, e = NewSigningTransport(signer, capture).RoundTrip(req)
+	c.Assert(e, IsNil)
+	c.Assert(capture.req.URL.Path, Equals, "/object")
+	c.Assert(capture.req.Header.Get("Authorization"), Equals,
+		"AWS access:"+signV2(signer.Credentials(), "PUT", capture.req.Header, capture.req.Header.Get("Date"), "/bucket/object"))
+
+	// Anonymous requests are sent unsigned.
+	signer.Credentials = func() Credentials { return Credentials{} }
+	signer.Lookup = LookupAuto
+	req, e = http.NewRequest("GET", "http://example.test/bucket/object", nil)
+	c.Assert(e, IsNil)
+	_, e = NewSigningTransport(signer, capture).RoundTrip(req)
+	c.Assert(e, IsNil)
+	c.Assert(capture.req.URL.Path, Equals, "/bucket/object")
+	c.Assert(capture.req.Header.Get("Authorization"), Equals, "")
+}
+
+func (s *MySuite) TestSignerPresign(c *C) {
+	signer := Signer{
+		API:    "S3v4",
+		Lookup: LookupPath,
+		Credentials: func() Credentials {
+			return Credentials{AccessKeyID: "access", SecretAccessKey: "secret", SessionToken: "token"}
+		},
+		Region:      func(ctx context.Context, bucket string) string { return "eu-west-1" },
+		ClockOffset: func() time.Duration { return 0 },
+	}
+	presignedURL, e := signer.Presign("http://example.test/bucket/object?X-Amz-Expires=60&X-Amz-Signature=stale", time.Hour)
+	c.Assert(e, IsNil)
+	u, e := url.Parse(presignedURL)
+	c.Assert(e, IsNil)
+	query := u.Query()
+	c.Assert(query.Get("X-Amz-Credential"), Matches, "access/[0-9]{8}/eu-west-1/s3/aws4_request")
+	c.Assert(query.Get("X-Amz-Expires"), Equals, "3600")
+	c.Assert(query.Get("X-Amz-Security-Token"), Equals, "token")
+	c.Assert(query["X-Amz-Signature"], HasLen, 1)
+	c.Assert(query.Get("X-Amz-Signature"), Not(Equals), "stale")
+
+	// Browser uploads are conditioned on the fields signed again.
+	policy := base64.StdEncoding.EncodeToString([]byte(`{"expiration":"2099-01-01T00:00:00.000Z","conditions":[["eq","$bucket","bucket"],["eq","$x-amz-date","19700101T000000Z"]]}`))
+	formData, e := signer.SignPostPolicy(map[string]string{"bucket": "bucket", "policy": policy})
+	c.Assert(e, IsNil)
+	document, e := base64.StdEncoding.DecodeString(formData["policy"])
+	c.Assert(e, IsNil)
+	c.Assert(strings.Contains(string(document), "19700101T000000Z"), Equals, false)
+	c.Assert(strings.Contains(string(document), `["eq","$x-amz-date","`+formData["x-amz-date"]+`"]`), Equals, true)
+	c.Assert(strings.Contains(string(document), `["eq","$x-amz-security-token","token"]`), Equals, true)
+	c.Assert(formData["x-amz-credential"], Matches, "access/[0-9]{8}/eu-west-1/s3/aws4_request")
+	c.Assert(formData["x-amz-signature"], Matches, "[0-9a-f]{64}")
+}
+
+func (s *MySuite) TestGuessRegion(c *C) {
+	c.Assert(GuessRegion(NewURL("https://s3-eu-west-1.amazonaws.com/bucket"), LookupAuto), Equals, "eu-west-1")
+	c.Assert(GuessRegion(NewURL("https://bucket.s3-us-west-2.amazonaws.com/object"), LookupAuto), Equals, "us-west-2")
+	c.Assert(GuessRegion(NewURL("https://s3.amazonaws.com/bucket"), LookupPath), Equals, "us-east-1")
+	c.Assert(GuessRegion(NewURL("http://localhost:9000/bucket"), LookupAuto), Equals, "milkyway")
+}
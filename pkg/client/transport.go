@@ -0,0 +1,244 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// MaxIdleConnsPerHost - idle keep-alive connections kept open per host, enough
+// for parallel transfers to reuse them instead of dialing and handshaking again.
+const MaxIdleConnsPerHost = 256
+
+// TransportOptions - settings of connections to a host, zero values keep the defaults.
+type TransportOptions struct {
+	// CAFile is a PEM bundle of certificate authorities trusted besides those of the system.
+	CAFile string
+	// CertFile and KeyFile hold a PEM client certificate and its key, for mutual TLS.
+	CertFile string
+	KeyFile  string
+	// Insecure skips verification of server certificates, for test setups only.
+	Insecure bool
+	// Proxy is the URL of the proxy for all requests, else proxies come from the environment.
+	Proxy string
+	// ConnectTimeout bounds dialing a connection.
+	ConnectTimeout time.Duration
+	// ReadTimeout bounds waiting for data on a connection, idle ones are closed once it passes.
+	ReadTimeout time.Duration
+	// MaxIdleConns is the number of idle keep-alive connections kept open.
+	MaxIdleConns int
+}
+
+// transportKey - transports are shared by their settings.
+type transportKey struct {
+	responseTimeout time.Duration
+	options         TransportOptions
+}
+
+// sharedTransports are shared by all object storage clients, by their settings.
+var sharedTransports = struct {
+	sync.Mutex
+	transports map[transportKey]*http.Transport
+}{transports: make(map[transportKey]*http.Transport)}
+
+// SharedTransport returns the keep-alive transport shared by all object storage clients with
+// the same settings, connections and TLS sessions are thus reused across objects. Requests
+// fail if response headers do not arrive within responseTimeout after the request was sent,
+// 0 means no timeout.
+func SharedTransport(responseTimeout time.Duration, options TransportOptions) (http.RoundTripper, *probe.Error) {
+	sharedTransports.Lock()
+	defer sharedTransports.Unlock()
+	key := transportKey{responseTimeout: responseTimeout, options: options}
+	if transport, ok := sharedTransports.transports[key]; ok {
+		return transport, nil
+	}
+	tlsConfig, err := newTLSConfig(options)
+	if err != nil {
+		return nil, err.Trace()
+	}
+	proxy := http.ProxyFromEnvironment
+	if options.Proxy != "" {
+		proxyURL, e := url.Parse(options.Proxy)
+		if e != nil || proxyURL.Host == "" {
+			return nil, probe.NewError(InvalidProxy{Proxy: options.Proxy})
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if options.ConnectTimeout > 0 {
+		dialer.Timeout = options.ConnectTimeout
+	}
+	dial := dialer.DialContext
+	if options.ReadTimeout > 0 {
+		dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+			conn, e := dialer.DialContext(ctx, network, address)
+			if e != nil {
+				return nil, e
+			}
+			return readTimeoutConn{Conn: conn, timeout: options.ReadTimeout}, nil
+		}
+	}
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dial,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   10 * time.Second,
+		MaxIdleConnsPerHost:   MaxIdleConnsPerHost,
+		ResponseHeaderTimeout: responseTimeout,
+	}
+	if options.MaxIdleConns > 0 {
+		transport.MaxIdleConnsPerHost = options.MaxIdleConns
+	}
+	sharedTransports.transports[key] = transport
+	return transport, nil
+}
+
+// newTLSConfig returns TLS settings of options, nil for the defaults.
+func newTLSConfig(options TransportOptions) (*tls.Config, *probe.Error) {
+	if options.CAFile == "" && options.CertFile == "" && options.KeyFile == "" && !options.Insecure {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: options.Insecure}
+	if options.CAFile != "" {
+		pem, e := ioutil.ReadFile(options.CAFile)
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		pool, e := x509.SystemCertPool()
+		if e != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, probe.NewError(InvalidCAFile{Path: options.CAFile})
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if options.CertFile != "" || options.KeyFile != "" {
+		cert, e := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// readTimeoutConn fails reads which wait longer than timeout for data.
+type readTimeoutConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+// Read reads from the connection, within the timeout.
+func (c readTimeoutConn) Read(b []byte) (int, error) {
+	if e := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); e != nil {
+		return 0, e
+	}
+	return c.Conn.Read(b)
+}
+
+// requestLimiter spaces out requests to a host evenly, to stay within a requests-per-second limit.
+type requestLimiter struct {
+	mutex    sync.Mutex
+	interval time.Duration
+	next     time.Time // earliest time the next request may be sent.
+}
+
+// reserve reserves a slot for a request, and returns how long to wait for it.
+func (l *requestLimiter) reserve() time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	return delay
+}
+
+// requestLimiters holds limiters by host, shared by all clients of a host regardless of their credentials.
+var requestLimiters = struct {
+	sync.Mutex
+	limiters map[string]*requestLimiter
+}{limiters: make(map[string]*requestLimiter)}
+
+// getRequestLimiter returns the limiter for host, updated to requestsPerSecond.
+func getRequestLimiter(host string, requestsPerSecond float64) *requestLimiter {
+	interval := time.Duration(float64(time.Second) / requestsPerSecond)
+
+	requestLimiters.Lock()
+	defer requestLimiters.Unlock()
+	l, ok := requestLimiters.limiters[host]
+	if !ok {
+		l = &requestLimiter{}
+		requestLimiters.limiters[host] = l
+	}
+	l.mutex.Lock()
+	l.interval = interval
+	l.mutex.Unlock()
+	return l
+}
+
+// rateLimitedTransport delays requests to stay within a per host requests-per-second limit.
+type rateLimitedTransport struct {
+	host      string
+	limiter   *requestLimiter
+	transport http.RoundTripper
+}
+
+// RoundTrip waits for a request slot, then sends the request.
+func (t rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if delay := t.limiter.reserve(); delay > 0 {
+		console.Debugln("Request to " + t.host + " delayed by " + delay.String() + " to stay within its requests per second limit.")
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// NewRateLimitedTransport limits requests sent to host through transport to requestsPerSecond,
+// shared by all transports of the same host. A limit of 0 or less returns transport as is.
+func NewRateLimitedTransport(host string, requestsPerSecond float64, transport http.RoundTripper) http.RoundTripper {
+	if requestsPerSecond <= 0 {
+		return transport
+	}
+	return rateLimitedTransport{
+		host:      host,
+		limiter:   getRequestLimiter(host, requestsPerSecond),
+		transport: transport,
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestRequestLimiter(c *C) {
+	l := getRequestLimiter("http://limited.example.com", 10)
+	c.Assert(l.reserve(), Equals, time.Duration(0))
+	delay := l.reserve()
+	c.Assert(delay > 90*time.Millisecond && delay <= 100*time.Millisecond, Equals, true)
+
+	// Limiters are shared by host.
+	c.Assert(getRequestLimiter("http://limited.example.com", 10), Equals, l)
+}
+
+func (s *MySuite) TestRateLimitedTransport(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	c.Assert(NewRateLimitedTransport(server.URL, 0, http.DefaultTransport), Equals, http.DefaultTransport)
+
+	httpClient := &http.Client{Transport: NewRateLimitedTransport(server.URL, 20, http.DefaultTransport)}
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		res, err := httpClient.Get(server.URL)
+		c.Assert(err, IsNil)
+		res.Body.Close()
+	}
+	// First request goes out right away, the remaining four are 50ms apart.
+	c.Assert(time.Since(start) >= 200*time.Millisecond, Equals, true)
+}
+
+func (s *MySuite) TestTransportTLS(c *C) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	get := func(options TransportOptions) error {
+		transport, err := SharedTransport(0, options)
+		c.Assert(err, IsNil)
+		res, e := (&http.Client{Transport: transport}).Get(server.URL)
+		if e == nil {
+			res.Body.Close()
+		}
+		return e
+	}
+	c.Assert(get(TransportOptions{}), Not(IsNil))
+	c.Assert(get(TransportOptions{Insecure: true}), IsNil)
+
+	root, e := ioutil.TempDir(os.TempDir(), "transport-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	caFile := filepath.Join(root, "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	c.Assert(ioutil.WriteFile(caFile, certPEM, 0600), IsNil)
+	c.Assert(get(TransportOptions{CAFile: caFile}), IsNil)
+
+	// Transports are shared by their settings.
+	t1, err := SharedTransport(0, TransportOptions{CAFile: caFile})
+	c.Assert(err, IsNil)
+	t2, err := SharedTransport(0, TransportOptions{CAFile: caFile})
+	c.Assert(err, IsNil)
+	c.Assert(t1, Equals, t2)
+
+	c.Assert(ioutil.WriteFile(caFile, []byte("no certificates"), 0600), IsNil)
+	_, err = SharedTransport(time.Second, TransportOptions{CAFile: caFile})
+	c.Assert(err, Not(IsNil))
+	c.Assert(err.ToGoError(), FitsTypeOf, InvalidCAFile{})
+}
+
+func (s *MySuite) TestTransportProxy(c *C) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("proxied " + r.URL.String()))
+	}))
+	defer proxy.Close()
+
+	transport, err := SharedTransport(0, TransportOptions{Proxy: proxy.URL})
+	c.Assert(err, IsNil)
+	res, e := (&http.Client{Transport: transport}).Get("http://s3.example.invalid/bucket")
+	c.Assert(e, IsNil)
+	body, e := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	c.Assert(e, IsNil)
+	c.Assert(string(body), Equals, "proxied http://s3.example.invalid/bucket")
+
+	_, err = SharedTransport(0, TransportOptions{Proxy: "proxy.example.com"})
+	c.Assert(err, Not(IsNil))
+	c.Assert(err.ToGoError(), FitsTypeOf, InvalidProxy{})
+}
+
+func (s *MySuite) TestTransportReadTimeout(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	transport, err := SharedTransport(0, TransportOptions{ReadTimeout: 100 * time.Millisecond})
+	c.Assert(err, IsNil)
+	res, e := (&http.Client{Transport: transport}).Get(server.URL)
+	c.Assert(e, IsNil)
+	defer res.Body.Close()
+	_, e = ioutil.ReadAll(res.Body)
+	c.Assert(e, Not(IsNil))
+}
@@ -43,6 +43,22 @@ const (
 	Filesystem        // POSIX compatible file systems
 )
 
+// Bucket lookup styles of object storage hosts
+const (
+	LookupAuto = "auto" // virtual host style for Amazon S3, path style otherwise
+	LookupPath = "path" // bucket is the first element of the path
+	LookupDNS  = "dns"  // bucket is the first label of the host name
+)
+
+// IsValidLookup reports whether lookup is a bucket lookup style, empty meaning LookupAuto.
+func IsValidLookup(lookup string) bool {
+	switch lookup {
+	case "", LookupAuto, LookupPath, LookupDNS:
+		return true
+	}
+	return false
+}
+
 // Maybe rawurl is of the form scheme:path. (Scheme must be [a-zA-Z][a-zA-Z0-9+-.]*)
 // If so, return scheme, path; else return "", rawurl.
 func getScheme(rawurl string) (scheme, path string) {
@@ -99,7 +115,7 @@ func NewURL(urlStr string) *URL {
 			rest = "/"
 		}
 		host := getHost(authority)
-		if host != "" && (scheme == "http" || scheme == "https") {
+		if host != "" && isObjectScheme(scheme) {
 			return &URL{
 				Scheme:          scheme,
 				Type:            Object,
@@ -147,3 +163,37 @@ func (u *URL) String() string {
 	}
 	return buf.String()
 }
+
+// IsVirtualHostStyle reports whether the bucket of URL is addressed by its host name.
+func (u *URL) IsVirtualHostStyle(lookup string) bool {
+	switch lookup {
+	case LookupDNS:
+		return true
+	case LookupPath:
+		return false
+	}
+	match, _ := filepath.Match("*.s3*.amazonaws.com", u.Host)
+	return match
+}
+
+// BucketAndObject gives bucketName and objectName of URL, its bucket addressed as per lookup.
+func (u *URL) BucketAndObject(lookup string) (bucketName, objectName string) {
+	path := u.Path
+	if u.IsVirtualHostStyle(lookup) {
+		hostSplits := strings.SplitN(u.Host, ".", 2)
+		path = string(u.Separator) + hostSplits[0] + u.Path
+	}
+	splits := strings.SplitN(path, string(u.Separator), 3)
+	switch len(splits) {
+	case 0, 1:
+		bucketName = ""
+		objectName = ""
+	case 2:
+		bucketName = splits[1]
+		objectName = ""
+	case 3:
+		bucketName = splits[1]
+		objectName = splits[2]
+	}
+	return bucketName, objectName
+}
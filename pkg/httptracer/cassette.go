@@ -0,0 +1,347 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httptracer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces secrets in recorded requests.
+const Redacted = "**REDACTED**"
+
+// Headers and query parameters carrying secrets, never recorded.
+var (
+	secretHeaders = []string{"Authorization", "X-Amz-Security-Token", "Cookie", "Set-Cookie"}
+	secretParams  = []string{"X-Amz-Signature", "X-Amz-Credential", "X-Amz-Security-Token", "Signature", "AWSAccessKeyId"}
+)
+
+// Interaction is a request and its response, as saved in a cassette.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request with its secrets redacted.
+type RecordedRequest struct {
+	Method    string      `json:"method"`
+	URL       string      `json:"url"`
+	Header    http.Header `json:"header"`
+	Body      []byte      `json:"body,omitempty"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
+
+// RecordedResponse is a response, or the transport error which occurred instead.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode,omitempty"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+	Truncated  bool        `json:"truncated,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// Recorder saves every request sent through its transports and their responses to
+// a cassette, one JSON encoded Interaction per line.
+type Recorder struct {
+	mutex   sync.Mutex
+	writer  io.Writer
+	maxBody int64
+}
+
+// NewRecorder returns a recorder writing to w. Bodies are truncated to maxBody
+// bytes, a negative maxBody records them in full. Response bodies are recorded as
+// they are read, without reading ahead.
+func NewRecorder(w io.Writer, maxBody int64) *Recorder {
+	return &Recorder{writer: w, maxBody: maxBody}
+}
+
+// Transport returns transport, with its requests and responses recorded.
+func (r *Recorder) Transport(transport http.RoundTripper) http.RoundTripper {
+	return recordingTransport{recorder: r, transport: transport}
+}
+
+// record appends an interaction to the cassette.
+func (r *Recorder) record(interaction Interaction) error {
+	data, e := json.Marshal(interaction)
+	if e != nil {
+		return e
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	_, e = r.writer.Write(append(data, '\n'))
+	return e
+}
+
+// bodyRecorder keeps up to limit bytes read through it.
+type bodyRecorder struct {
+	io.ReadCloser
+	limit     int64
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (b *bodyRecorder) Read(p []byte) (int, error) {
+	n, e := b.ReadCloser.Read(p)
+	b.keep(p[:n])
+	return n, e
+}
+
+// keep appends data to the recorded body, as far as the limit allows.
+func (b *bodyRecorder) keep(data []byte) {
+	if b.limit >= 0 && int64(b.body.Len()+len(data)) > b.limit {
+		data = data[:b.limit-int64(b.body.Len())]
+		b.truncated = true
+	}
+	b.body.Write(data)
+}
+
+// closeDrainLimit - bytes read from a response body closed before its end, to
+// tell bodies left with only trailing bytes from truncated ones.
+const closeDrainLimit = 4 << 10
+
+// responseRecorder records its interaction once the response body is read to
+// its end or closed. A body closed before its end is recorded as truncated.
+type responseRecorder struct {
+	bodyRecorder
+	once   sync.Once
+	record func(body []byte, truncated bool) error
+}
+
+func (b *responseRecorder) Read(p []byte) (int, error) {
+	n, e := b.bodyRecorder.Read(p)
+	if e == io.EOF {
+		if re := b.finish(false); re != nil {
+			return n, re
+		}
+	}
+	return n, e
+}
+
+func (b *responseRecorder) Close() error {
+	_, de := io.CopyN(ioutil.Discard, &b.bodyRecorder, closeDrainLimit)
+	e := b.ReadCloser.Close()
+	if re := b.finish(de != io.EOF); re != nil {
+		return re
+	}
+	return e
+}
+
+// finish records the interaction, the first time it is called.
+func (b *responseRecorder) finish(incomplete bool) (e error) {
+	b.once.Do(func() {
+		e = b.record(b.body.Bytes(), b.truncated || incomplete)
+	})
+	return e
+}
+
+// recordingTransport records requests sent through it and their responses.
+type recordingTransport struct {
+	recorder  *Recorder
+	transport http.RoundTripper
+}
+
+// RoundTrip sends the request and records it. The response body is recorded up to the
+// body limit while the caller reads it, the interaction is saved once it is done.
+func (t recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody *bodyRecorder
+	if req.Body != nil {
+		reqBody = &bodyRecorder{ReadCloser: req.Body, limit: t.recorder.maxBody}
+		outReq := new(http.Request)
+		*outReq = *req
+		outReq.Body = reqBody
+		req = outReq
+	}
+	res, err := t.transport.RoundTrip(req)
+
+	interaction := Interaction{Request: RecordedRequest{
+		Method: req.Method,
+		URL:    redactURL(req.URL),
+		Header: redactHeader(req.Header),
+	}}
+	if reqBody != nil {
+		interaction.Request.Body = reqBody.body.Bytes()
+		interaction.Request.Truncated = reqBody.truncated
+	}
+	if err != nil {
+		interaction.Response.Error = err.Error()
+		if e := t.recorder.record(interaction); e != nil {
+			return nil, e
+		}
+		return nil, err
+	}
+
+	interaction.Response.StatusCode = res.StatusCode
+	interaction.Response.Header = redactHeader(res.Header)
+	if res.Body == nil || res.Body == http.NoBody || req.Method == "HEAD" {
+		if e := t.recorder.record(interaction); e != nil {
+			return nil, e
+		}
+		return res, nil
+	}
+	res.Body = &responseRecorder{
+		bodyRecorder: bodyRecorder{ReadCloser: res.Body, limit: t.recorder.maxBody},
+		record: func(body []byte, truncated bool) error {
+			interaction.Response.Body = body
+			interaction.Response.Truncated = truncated
+			return t.recorder.record(interaction)
+		},
+	}
+	return res, nil
+}
+
+// RedactRequest returns a copy of req with the secrets of its header and URL redacted,
+// for display. Its body is left alone.
+func RedactRequest(req *http.Request) *http.Request {
+	redacted := new(http.Request)
+	*redacted = *req
+	redacted.Header = redactHeader(req.Header)
+	if u, e := url.Parse(redactURL(req.URL)); e == nil {
+		redacted.URL = u
+	}
+	return redacted
+}
+
+// redactHeader returns a copy of header without secrets.
+func redactHeader(header http.Header) http.Header {
+	redacted := make(http.Header, len(header))
+	for key, values := range header {
+		redacted[key] = append([]string(nil), values...)
+	}
+	for _, key := range secretHeaders {
+		if _, ok := redacted[key]; ok {
+			redacted.Set(key, Redacted)
+		}
+	}
+	return redacted
+}
+
+// redactURL returns u without secrets, its query parameters sorted.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	query := redacted.Query()
+	for _, param := range secretParams {
+		if _, ok := query[param]; ok {
+			query.Set(param, Redacted)
+		}
+	}
+	redacted.RawQuery = strings.Replace(query.Encode(), "%2A%2AREDACTED%2A%2A", Redacted, -1)
+	redacted.User = nil
+	return redacted.String()
+}
+
+// NotRecorded - no response recorded for a request in the cassette being replayed.
+type NotRecorded struct {
+	Method string
+	URL    string
+}
+
+func (e NotRecorded) Error() string {
+	return "No recorded response for " + e.Method + " " + e.URL + "."
+}
+
+// TruncatedResponse - response body in the cassette being replayed was not recorded in full.
+type TruncatedResponse struct {
+	Method string
+	URL    string
+}
+
+func (e TruncatedResponse) Error() string {
+	return "Response to " + e.Method + " " + e.URL + " was truncated when recorded, please record again with a larger body limit."
+}
+
+// Replayer serves responses from a cassette saved by a Recorder, without network.
+type Replayer struct {
+	mutex        sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+// NewReplayer loads the cassette read from r.
+func NewReplayer(r io.Reader) (*Replayer, error) {
+	replayer := &Replayer{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<30)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var interaction Interaction
+		if e := json.Unmarshal(scanner.Bytes(), &interaction); e != nil {
+			return nil, fmt.Errorf("Invalid interaction on line %d of cassette. %s", line, e)
+		}
+		replayer.interactions = append(replayer.interactions, interaction)
+	}
+	if e := scanner.Err(); e != nil {
+		return nil, e
+	}
+	replayer.replayed = make([]bool, len(replayer.interactions))
+	return replayer, nil
+}
+
+// RoundTrip serves the first response not replayed yet, recorded for the same method
+// and URL. Request bodies are read and discarded.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		io.Copy(ioutil.Discard, req.Body)
+		req.Body.Close()
+	}
+	method, u := req.Method, redactURL(req.URL)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for i, interaction := range r.interactions {
+		if r.replayed[i] || interaction.Request.Method != method || interaction.Request.URL != u {
+			continue
+		}
+		r.replayed[i] = true
+		recorded := interaction.Response
+		if recorded.Error != "" {
+			return nil, fmt.Errorf("%s", recorded.Error)
+		}
+		if recorded.Truncated {
+			return nil, TruncatedResponse{Method: method, URL: u}
+		}
+		res := &http.Response{
+			Status:        strconv.Itoa(recorded.StatusCode) + " " + http.StatusText(recorded.StatusCode),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        recorded.Header,
+			Body:          ioutil.NopCloser(bytes.NewReader(recorded.Body)),
+			ContentLength: -1,
+			Request:       req,
+		}
+		if res.Header == nil {
+			res.Header = make(http.Header)
+		}
+		if length, e := strconv.ParseInt(res.Header.Get("Content-Length"), 10, 64); e == nil {
+			res.ContentLength = length
+		}
+		return res, nil
+	}
+	return nil, NotRecorded{Method: method, URL: u}
+}
@@ -0,0 +1,143 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httptracer
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestRecordReplay(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(r.Method + " " + r.URL.Path + " " + string(body)))
+	}))
+	defer server.Close()
+
+	cassette := new(bytes.Buffer)
+	client := &http.Client{Transport: NewRecorder(cassette, 10).Transport(http.DefaultTransport)}
+
+	req, err := http.NewRequest("PUT", server.URL+"/bucket/object?X-Amz-Signature=secret&partNumber=1", strings.NewReader("hello world"))
+	c.Assert(err, IsNil)
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential=ACCESSKEY/20160101/us-east-1/s3/aws4_request")
+	res, err := client.Do(req)
+	c.Assert(err, IsNil)
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	c.Assert(err, IsNil)
+	// Truncating the recorded body leaves the response intact.
+	c.Assert(string(body), Equals, "PUT /bucket/object hello world")
+
+	res, err = client.Get(server.URL + "/b")
+	c.Assert(err, IsNil)
+	res.Body.Close()
+
+	c.Assert(strings.Contains(cassette.String(), "secret"), Equals, false)
+	c.Assert(strings.Contains(cassette.String(), "ACCESSKEY"), Equals, false)
+
+	replayer, err := NewReplayer(bytes.NewReader(cassette.Bytes()))
+	c.Assert(err, IsNil)
+	server.Close()
+	client = &http.Client{Transport: replayer}
+
+	// Requests are matched regardless of their signature, truncated responses are not replayed.
+	req, err = http.NewRequest("PUT", server.URL+"/bucket/object?partNumber=1&X-Amz-Signature=other", strings.NewReader("hello world"))
+	c.Assert(err, IsNil)
+	_, err = client.Do(req)
+	c.Assert(err, NotNil)
+	c.Assert(strings.Contains(err.Error(), "truncated"), Equals, true)
+
+	res, err = client.Get(server.URL + "/b")
+	c.Assert(err, IsNil)
+	body, err = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	c.Assert(err, IsNil)
+	c.Assert(string(body), Equals, "GET /b ")
+
+	// Every response is replayed once.
+	_, err = client.Get(server.URL + "/b")
+	c.Assert(err, NotNil)
+	c.Assert(strings.Contains(err.Error(), "No recorded response"), Equals, true)
+}
+
+func (s *MySuite) TestRecordPartialRead(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/partial" {
+			w.Write(bytes.Repeat([]byte("hello world"), 1<<10))
+			return
+		}
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	cassette := new(bytes.Buffer)
+	client := &http.Client{Transport: NewRecorder(cassette, -1).Transport(http.DefaultTransport)}
+	res, err := client.Get(server.URL + "/full")
+	c.Assert(err, IsNil)
+	body, err := ioutil.ReadAll(res.Body)
+	c.Assert(err, IsNil)
+	c.Assert(string(body), Equals, "hello world")
+	// Recorded as soon as the body is read to its end.
+	c.Assert(strings.Contains(cassette.String(), "/full"), Equals, true)
+	res.Body.Close()
+
+	res, err = client.Get(server.URL + "/partial")
+	c.Assert(err, IsNil)
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(res.Body, buf)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(cassette.String(), "/partial"), Equals, false)
+	res.Body.Close()
+
+	replayer, err := NewReplayer(bytes.NewReader(cassette.Bytes()))
+	c.Assert(err, IsNil)
+	client = &http.Client{Transport: replayer}
+	res, err = client.Get(server.URL + "/full")
+	c.Assert(err, IsNil)
+	body, err = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	c.Assert(err, IsNil)
+	c.Assert(string(body), Equals, "hello world")
+	// A body closed before its end was not recorded in full.
+	_, err = client.Get(server.URL + "/partial")
+	c.Assert(err, NotNil)
+	c.Assert(strings.Contains(err.Error(), "truncated"), Equals, true)
+}
+
+func (s *MySuite) TestRedactRequest(c *C) {
+	req, err := http.NewRequest("GET", "https://s3.amazonaws.com/bucket/object?X-Amz-Signature=secret&X-Amz-Security-Token=token&prefix=a", nil)
+	c.Assert(err, IsNil)
+	req.Header.Set("Authorization", "AWS ACCESS:secret")
+	req.Header.Set("X-Amz-Security-Token", "token")
+
+	dump, err := httputil.DumpRequestOut(RedactRequest(req), false)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(dump), "secret"), Equals, false)
+	c.Assert(strings.Contains(string(dump), "token"), Equals, false)
+	c.Assert(strings.Contains(string(dump), "prefix=a"), Equals, true)
+	// The request itself is left alone.
+	c.Assert(req.Header.Get("Authorization"), Equals, "AWS ACCESS:secret")
+	c.Assert(req.URL.Query().Get("X-Amz-Signature"), Equals, "secret")
+}
@@ -0,0 +1,249 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httptracer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/console"
+)
+
+// Faults - faults injected into requests, to test how failures are handled without a
+// faulty service. Rates are fractions of requests, between 0 and 1.
+type Faults struct {
+	ErrorRate    float64       // Requests answered with 500 InternalError, 503 ServiceUnavailable or 503 SlowDown.
+	ResetAfter   int64         // A connection is reset every ResetAfter bytes sent or received, 0 never.
+	Latency      time.Duration // Delay before every request.
+	TruncateRate float64       // Response bodies ending early.
+	Seed         int64         // Seeds the choice of faults, for reproducible runs.
+}
+
+// ParseFaults parses faults such as ‘error=0.1,reset=1MiB,latency=200ms,truncate=0.05,seed=1’.
+func ParseFaults(spec string) (*Faults, error) {
+	faults := &Faults{Seed: time.Now().UTC().UnixNano()}
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.New("Invalid fault ‘" + field + "’, please use ‘name=value’.")
+		}
+		name, value := kv[0], kv[1]
+		var e error
+		switch name {
+		case "error":
+			faults.ErrorRate, e = parseFaultRate(value)
+		case "truncate":
+			faults.TruncateRate, e = parseFaultRate(value)
+		case "reset":
+			var bytes uint64
+			bytes, e = humanize.ParseBytes(value)
+			faults.ResetAfter = int64(bytes)
+		case "latency":
+			faults.Latency, e = time.ParseDuration(value)
+			if e == nil && faults.Latency < 0 {
+				e = errors.New("negative latency")
+			}
+		case "seed":
+			faults.Seed, e = strconv.ParseInt(value, 10, 64)
+		default:
+			return nil, errors.New("Unknown fault ‘" + name + "’, please use one of ‘error’, ‘reset’, ‘latency’, ‘truncate’ or ‘seed’.")
+		}
+		if e != nil {
+			return nil, errors.New("Invalid value ‘" + value + "’ for fault ‘" + name + "’.")
+		}
+	}
+	return faults, nil
+}
+
+// parseFaultRate parses a rate between 0 and 1.
+func parseFaultRate(value string) (float64, error) {
+	rate, e := strconv.ParseFloat(value, 64)
+	if e != nil {
+		return 0, e
+	}
+	if rate < 0 || rate > 1 {
+		return 0, errors.New("rate out of range")
+	}
+	return rate, nil
+}
+
+// faultResponses are the error responses injected, by S3 error code.
+var faultResponses = []struct {
+	statusCode int
+	code       string
+	message    string
+}{
+	{http.StatusInternalServerError, "InternalError", "We encountered an internal error, please try again."},
+	{http.StatusServiceUnavailable, "ServiceUnavailable", "Reduce your request rate."},
+	{http.StatusServiceUnavailable, "SlowDown", "Reduce your request rate."},
+}
+
+// faultTransport injects faults into requests sent through it.
+type faultTransport struct {
+	faults    Faults
+	transport http.RoundTripper
+
+	mutex       sync.Mutex
+	rand        *rand.Rand
+	transferred int64 // bytes sent and received since the last reset.
+}
+
+// NewFaultTransport returns transport, with faults injected into its requests.
+func NewFaultTransport(faults Faults, transport http.RoundTripper) http.RoundTripper {
+	return &faultTransport{faults: faults, transport: transport, rand: rand.New(rand.NewSource(faults.Seed))}
+}
+
+// chance returns true with probability rate.
+func (t *faultTransport) chance(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.rand.Float64() < rate
+}
+
+// intn returns a random number in [0, n).
+func (t *faultTransport) intn(n int64) int64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.rand.Int63n(n)
+}
+
+// transfer accounts for n bytes, and returns how many of them go through before the connection is reset.
+func (t *faultTransport) transfer(n int) (int, bool) {
+	if t.faults.ResetAfter <= 0 {
+		return n, false
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if left := t.faults.ResetAfter - t.transferred; int64(n) >= left {
+		t.transferred = 0
+		return int(left), true
+	}
+	t.transferred += int64(n)
+	return n, false
+}
+
+// RoundTrip sends the request, unless it is answered with an error, and injects faults into its body
+// and its response body.
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.faults.Latency > 0 {
+		timer := time.NewTimer(t.faults.Latency)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, req.Context().Err()
+		}
+	}
+
+	if t.chance(t.faults.ErrorRate) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		fault := faultResponses[t.intn(int64(len(faultResponses)))]
+		console.Debugln("Injected fault: " + fault.code + " response to " + req.Method + " " + req.URL.Path)
+		body := fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<Error><Code>%s</Code><Message>%s</Message><Resource>%s</Resource><RequestId>fault</RequestId></Error>",
+			fault.code, fault.message, req.URL.Path)
+		header := make(http.Header)
+		header.Set("Content-Type", "application/xml")
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+		if req.Method == "HEAD" {
+			body = ""
+		}
+		return &http.Response{
+			Status:        strconv.Itoa(fault.statusCode) + " " + http.StatusText(fault.statusCode),
+			StatusCode:    fault.statusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader([]byte(body))),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	if req.Body != nil && t.faults.ResetAfter > 0 {
+		outReq := new(http.Request)
+		*outReq = *req
+		outReq.Body = &faultBody{ReadCloser: req.Body, transport: t, op: "write", limit: -1}
+		req = outReq
+	}
+	res, err := t.transport.RoundTrip(req)
+	if err != nil || res.Body == nil {
+		return res, err
+	}
+	body := &faultBody{ReadCloser: res.Body, transport: t, op: "read", limit: -1}
+	if res.ContentLength > 0 && t.chance(t.faults.TruncateRate) {
+		body.limit = t.intn(res.ContentLength)
+		console.Debugln("Injected fault: response body to " + req.Method + " " + req.URL.Path + " truncated to " +
+			strconv.FormatInt(body.limit, 10) + " bytes")
+	}
+	res.Body = body
+	return res, nil
+}
+
+// faultBody resets the connection every so many bytes, and ends early once limit bytes
+// were read unless limit is negative.
+type faultBody struct {
+	io.ReadCloser
+	transport *faultTransport
+	op        string
+	limit     int64
+	read      int64
+}
+
+func (b *faultBody) Read(p []byte) (int, error) {
+	if b.limit >= 0 {
+		if b.read >= b.limit {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if int64(len(p)) > b.limit-b.read {
+			p = p[:b.limit-b.read]
+		}
+	}
+	n, e := b.ReadCloser.Read(p)
+	n, reset := b.transport.transfer(n)
+	b.read += int64(n)
+	if reset {
+		console.Debugln("Injected fault: connection reset after " + strconv.FormatInt(b.read, 10) + " bytes")
+		return n, &net.OpError{Op: b.op, Net: "tcp", Err: os.NewSyscallError(b.op, syscall.ECONNRESET)}
+	}
+	return n, e
+}
@@ -0,0 +1,88 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httptracer
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestParseFaults(c *C) {
+	faults, err := ParseFaults("error=0.1, reset=1KiB,latency=200ms,truncate=0.05,seed=1")
+	c.Assert(err, IsNil)
+	c.Assert(*faults, DeepEquals, Faults{ErrorRate: 0.1, ResetAfter: 1024, Latency: 200 * time.Millisecond, TruncateRate: 0.05, Seed: 1})
+
+	for _, spec := range []string{"error", "error=2", "latency=-1s", "reset=lots", "unknown=1"} {
+		_, err = ParseFaults(spec)
+		c.Assert(err, NotNil, Commentf(spec))
+	}
+}
+
+func (s *MySuite) TestFaults(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+		w.Write([]byte(strings.Repeat("a", 100)))
+	}))
+	defer server.Close()
+
+	// Every request fails.
+	client := &http.Client{Transport: NewFaultTransport(Faults{ErrorRate: 1}, http.DefaultTransport)}
+	res, err := client.Get(server.URL + "/bucket/object")
+	c.Assert(err, IsNil)
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	c.Assert(err, IsNil)
+	c.Assert(res.StatusCode >= 500, Equals, true)
+	c.Assert(string(body), Matches, "(?s).*<Code>(InternalError|ServiceUnavailable|SlowDown)</Code>.*")
+
+	// Every response body ends early.
+	client = &http.Client{Transport: NewFaultTransport(Faults{TruncateRate: 1}, http.DefaultTransport)}
+	res, err = client.Get(server.URL + "/bucket/object")
+	c.Assert(err, IsNil)
+	body, err = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	c.Assert(err, Equals, io.ErrUnexpectedEOF)
+	c.Assert(len(body) < 100, Equals, true)
+
+	// Connections are reset every 150 bytes, first while receiving then while sending.
+	client = &http.Client{Transport: NewFaultTransport(Faults{ResetAfter: 150}, http.DefaultTransport)}
+	res, err = client.Get(server.URL + "/bucket/object")
+	c.Assert(err, IsNil)
+	body, err = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	c.Assert(err, IsNil)
+	c.Assert(len(body), Equals, 100)
+	_, err = client.Post(server.URL+"/bucket/object", "text/plain", strings.NewReader(strings.Repeat("b", 100)))
+	c.Assert(err, NotNil)
+	_, ok := err.(net.Error)
+	c.Assert(ok, Equals, true)
+
+	// Requests are delayed.
+	client = &http.Client{Transport: NewFaultTransport(Faults{Latency: 50 * time.Millisecond}, http.DefaultTransport)}
+	start := time.Now()
+	res, err = client.Get(server.URL + "/bucket/object")
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(time.Since(start) >= 50*time.Millisecond, Equals, true)
+}
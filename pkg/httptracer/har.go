@@ -0,0 +1,237 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httptracer
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// harVersion is the HAR format version written.
+const harVersion = "1.2"
+
+// HTTP Archive (HAR) structures, see http://www.softwareishard.com/blog/har-12-spec/
+type (
+	harLog struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	}
+
+	harCreator struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	harEntry struct {
+		StartedDateTime string      `json:"startedDateTime"`
+		Time            float64     `json:"time"`
+		Request         harRequest  `json:"request"`
+		Response        harResponse `json:"response"`
+		Cache           struct{}    `json:"cache"`
+		Timings         harTimings  `json:"timings"`
+	}
+
+	harRequest struct {
+		Method      string    `json:"method"`
+		URL         string    `json:"url"`
+		HTTPVersion string    `json:"httpVersion"`
+		Cookies     []harPair `json:"cookies"`
+		Headers     []harPair `json:"headers"`
+		QueryString []harPair `json:"queryString"`
+		HeadersSize int64     `json:"headersSize"`
+		BodySize    int64     `json:"bodySize"`
+	}
+
+	harResponse struct {
+		Status      int        `json:"status"`
+		StatusText  string     `json:"statusText"`
+		HTTPVersion string     `json:"httpVersion"`
+		Cookies     []harPair  `json:"cookies"`
+		Headers     []harPair  `json:"headers"`
+		Content     harContent `json:"content"`
+		RedirectURL string     `json:"redirectURL"`
+		HeadersSize int64      `json:"headersSize"`
+		BodySize    int64      `json:"bodySize"`
+		Error       string     `json:"_error,omitempty"` // Transport error of a request without response.
+	}
+
+	harPair struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	harContent struct {
+		Size     int64  `json:"size"`
+		MimeType string `json:"mimeType"`
+	}
+
+	// Only the time to the response headers is known, reading the response body is up to the caller.
+	harTimings struct {
+		Send    float64 `json:"send"`
+		Wait    float64 `json:"wait"`
+		Receive float64 `json:"receive"`
+	}
+)
+
+// HAR writes HTTP exchanges traced by RoundTripTrace in HAR format, with secrets redacted.
+type HAR struct {
+	mutex   sync.Mutex
+	writer  io.WriteSeeker
+	trailer int64 // offset of the trailer closing the log, overwritten by every entry.
+	entries int
+}
+
+// harTrailer closes the entries array and the log.
+const harTrailer = "\n]}}\n"
+
+// NewHAR writes an empty log to w, and returns a tracer adding entries to it. w is a valid
+// HAR file after every entry, even if it is never closed.
+func NewHAR(w io.WriteSeeker, creator, version string) (*HAR, error) {
+	header, e := json.Marshal(struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+	}{harVersion, harCreator{Name: creator, Version: version}})
+	if e != nil {
+		return nil, e
+	}
+	// Keep the log open for entries: {"log":{"version":...,"creator":...,"entries":[
+	prefix := `{"log":` + string(header[:len(header)-1]) + `,"entries":[`
+	if _, e = io.WriteString(w, prefix+harTrailer); e != nil {
+		return nil, e
+	}
+	return &HAR{writer: w, trailer: int64(len(prefix))}, nil
+}
+
+// Request - requests are written along with their response, see Timing.
+func (h *HAR) Request(req *http.Request) error {
+	return nil
+}
+
+// Response - responses are written along with their request, see Timing.
+func (h *HAR) Response(res *http.Response) error {
+	return nil
+}
+
+// Timing adds an entry for req and its response res to the log.
+func (h *HAR) Timing(req *http.Request, res *http.Response, sent time.Time, elapsed time.Duration) error {
+	entry := newHAREntry(req, sent, elapsed)
+	entry.Response = harResponse{
+		Status:      res.StatusCode,
+		StatusText:  http.StatusText(res.StatusCode),
+		HTTPVersion: res.Proto,
+		Cookies:     []harPair{},
+		Headers:     harHeaders(redactHeader(res.Header)),
+		Content:     harContent{Size: res.ContentLength, MimeType: res.Header.Get("Content-Type")},
+		HeadersSize: -1,
+		BodySize:    res.ContentLength,
+	}
+	return h.add(entry)
+}
+
+// Failure adds an entry for req which failed with err at the transport. Like browsers do,
+// its response has status 0 and the error in the custom field ‘_error’.
+func (h *HAR) Failure(req *http.Request, err error, sent time.Time, elapsed time.Duration) error {
+	entry := newHAREntry(req, sent, elapsed)
+	entry.Response = harResponse{
+		Status:      0,
+		HTTPVersion: entry.Request.HTTPVersion,
+		Cookies:     []harPair{},
+		Headers:     []harPair{},
+		HeadersSize: -1,
+		BodySize:    -1,
+		Error:       err.Error(),
+	}
+	return h.add(entry)
+}
+
+// newHAREntry returns an entry for req sent at sent, which waited elapsed for its response.
+func newHAREntry(req *http.Request, sent time.Time, elapsed time.Duration) harEntry {
+	wait := float64(elapsed) / float64(time.Millisecond)
+	entry := harEntry{
+		StartedDateTime: sent.Format(time.RFC3339Nano),
+		Time:            wait,
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         redactURL(req.URL),
+			HTTPVersion: req.Proto,
+			Cookies:     []harPair{},
+			Headers:     harHeaders(redactHeader(req.Header)),
+			QueryString: harQuery(req.URL),
+			HeadersSize: -1,
+			BodySize:    req.ContentLength,
+		},
+		Timings: harTimings{Send: 0, Wait: wait, Receive: 0},
+	}
+	if entry.Request.HTTPVersion == "" {
+		entry.Request.HTTPVersion = "HTTP/1.1"
+	}
+	if req.Body == nil {
+		entry.Request.BodySize = 0
+	}
+	return entry
+}
+
+// add writes entry at the end of the log.
+func (h *HAR) add(entry harEntry) error {
+	data, e := json.Marshal(entry)
+	if e != nil {
+		return e
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	separator := "\n"
+	if h.entries > 0 {
+		separator = ",\n"
+	}
+	if _, e = h.writer.Seek(h.trailer, io.SeekStart); e != nil {
+		return e
+	}
+	if _, e = io.WriteString(h.writer, separator+string(data)+harTrailer); e != nil {
+		return e
+	}
+	h.trailer += int64(len(separator) + len(data))
+	h.entries++
+	return nil
+}
+
+// harHeaders lists header, sorted by name.
+func harHeaders(header http.Header) []harPair {
+	pairs := []harPair{}
+	for name, values := range header {
+		for _, value := range values {
+			pairs = append(pairs, harPair{Name: name, Value: value})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Name < pairs[j].Name })
+	return pairs
+}
+
+// harQuery lists the query parameters of u, with secrets redacted.
+func harQuery(u *url.URL) []harPair {
+	redacted, e := url.Parse(redactURL(u))
+	if e != nil {
+		return []harPair{}
+	}
+	return harHeaders(http.Header(redacted.Query()))
+}
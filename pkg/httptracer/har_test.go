@@ -0,0 +1,107 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httptracer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestHAR(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte("<ListBucketResult/>"))
+	}))
+	defer server.Close()
+
+	file, err := ioutil.TempFile(os.TempDir(), "har-")
+	c.Assert(err, IsNil)
+	defer os.Remove(file.Name())
+	defer file.Close()
+	har, err := NewHAR(file, "mc", "test")
+	c.Assert(err, IsNil)
+	client := &http.Client{Transport: GetNewTraceTransport(har, http.DefaultTransport)}
+
+	var log struct {
+		Log harLog `json:"log"`
+	}
+	for i := 0; i < 3; i++ {
+		// The file is complete after every entry.
+		data, err := ioutil.ReadFile(file.Name())
+		c.Assert(err, IsNil)
+		c.Assert(json.Unmarshal(data, &log), IsNil)
+		c.Assert(len(log.Log.Entries), Equals, i)
+		c.Assert(strings.Contains(string(data), "secret"), Equals, false)
+
+		req, err := http.NewRequest("GET", server.URL+"/bucket?X-Amz-Signature=secret&prefix=a", nil)
+		c.Assert(err, IsNil)
+		req.Header.Set("Authorization", "AWS ACCESS:secret")
+		res, err := client.Do(req)
+		c.Assert(err, IsNil)
+		res.Body.Close()
+	}
+
+	c.Assert(log.Log.Version, Equals, "1.2")
+	c.Assert(log.Log.Creator, DeepEquals, harCreator{Name: "mc", Version: "test"})
+	entry := log.Log.Entries[0]
+	c.Assert(entry.Time >= 10, Equals, true)
+	_, err = time.Parse(time.RFC3339Nano, entry.StartedDateTime)
+	c.Assert(err, IsNil)
+	c.Assert(entry.Request.Method, Equals, "GET")
+	c.Assert(entry.Request.URL, Equals, server.URL+"/bucket?X-Amz-Signature="+Redacted+"&prefix=a")
+	c.Assert(entry.Request.QueryString, DeepEquals, []harPair{{"X-Amz-Signature", Redacted}, {"prefix", "a"}})
+	c.Assert(entry.Request.Headers, DeepEquals, []harPair{{"Authorization", Redacted}})
+	c.Assert(entry.Response.Status, Equals, http.StatusOK)
+	c.Assert(entry.Response.Content, DeepEquals, harContent{Size: int64(len("<ListBucketResult/>")), MimeType: "application/xml"})
+}
+
+func (s *MySuite) TestHARFailure(c *C) {
+	// A server which is gone, the request fails at the transport.
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	file, err := ioutil.TempFile(os.TempDir(), "har-")
+	c.Assert(err, IsNil)
+	defer os.Remove(file.Name())
+	defer file.Close()
+	har, err := NewHAR(file, "mc", "test")
+	c.Assert(err, IsNil)
+	client := &http.Client{Transport: GetNewTraceTransport(har, http.DefaultTransport)}
+
+	_, err = client.Get(server.URL + "/bucket")
+	c.Assert(err, NotNil)
+
+	data, err := ioutil.ReadFile(file.Name())
+	c.Assert(err, IsNil)
+	var log struct {
+		Log harLog `json:"log"`
+	}
+	c.Assert(json.Unmarshal(data, &log), IsNil)
+	c.Assert(len(log.Log.Entries), Equals, 1)
+	entry := log.Log.Entries[0]
+	c.Assert(entry.Request.URL, Equals, server.URL+"/bucket")
+	c.Assert(entry.Response.Status, Equals, 0)
+	c.Assert(strings.Contains(entry.Response.Error, "refused"), Equals, true)
+}
@@ -30,6 +30,20 @@ type HTTPTracer interface {
 	Response(res *http.Response) error
 }
 
+// HTTPTimingTracer is an HTTPTracer which is also told when a request was sent, and how long
+// its response took to arrive.
+type HTTPTimingTracer interface {
+	HTTPTracer
+	Timing(req *http.Request, res *http.Response, sent time.Time, elapsed time.Duration) error
+}
+
+// HTTPErrorTracer is an HTTPTracer which is also told about requests which failed at the
+// transport, without a response.
+type HTTPErrorTracer interface {
+	HTTPTracer
+	Failure(req *http.Request, err error, sent time.Time, elapsed time.Duration) error
+}
+
 // RoundTripTrace interposes HTTP transport requests and respsonses using HTTPTracer hooks
 type RoundTripTrace struct {
 	Trace     HTTPTracer        // User provides callback methods
@@ -46,6 +60,9 @@ func (t RoundTripTrace) RoundTrip(req *http.Request) (res *http.Response, err er
 
 	res, err = t.Transport.RoundTrip(req)
 	if err != nil {
+		if errorTrace, ok := t.Trace.(HTTPErrorTracer); ok {
+			errorTrace.Failure(req, err, timeStamp, time.Since(timeStamp))
+		}
 		return res, err
 	}
 
@@ -59,7 +76,14 @@ func (t RoundTripTrace) RoundTrip(req *http.Request) (res *http.Response, err er
 		if err != nil {
 			return nil, err
 		}
-		console.Debugln("Response Time: ", time.Since(timeStamp).String()+"\n")
+		elapsed := time.Since(timeStamp)
+		if timingTrace, ok := t.Trace.(HTTPTimingTracer); ok {
+			err = timingTrace.Timing(req, res, timeStamp, elapsed)
+			if err != nil {
+				return nil, err
+			}
+		}
+		console.Debugln("Response Time: ", elapsed.String()+"\n")
 	}
 	return res, err
 }
@@ -0,0 +1,371 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3fake
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	signV4Algorithm = "AWS4-HMAC-SHA256"
+	iso8601Format   = "20060102T150405Z"
+	yyyymmdd        = "20060102"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+
+	// maxClockSkew - requests dated further away from the server's clock are rejected.
+	maxClockSkew = 15 * time.Minute
+)
+
+// subResources - query parameters which are part of the resource signed with signature V2, sorted.
+var subResources = []string{
+	"acl", "location", "logging", "notification", "partNumber", "policy", "requestPayment",
+	"response-cache-control", "response-content-disposition", "response-content-encoding",
+	"response-content-language", "response-content-type", "response-expires",
+	"torrent", "uploadId", "uploads", "versionId", "versioning", "versions", "website",
+}
+
+// authenticate checks the signature of r, in any of the forms S3 accepts. It reports whether
+// r is signed with the server's credentials, anonymous requests are left to bucket ACLs.
+// Servers without credentials accept all requests as they are.
+func (s *Server) authenticate(r *http.Request, body []byte) (bool, error) {
+	if s.config.AccessKeyID == "" {
+		return true, nil
+	}
+	auth := r.Header.Get("Authorization")
+	query := r.URL.Query()
+	if auth != "" || query.Get("X-Amz-Signature") != "" || query.Get("Signature") != "" {
+		token := r.Header.Get("X-Amz-Security-Token")
+		if token == "" {
+			// Presigned with signature V4 or V2.
+			token = query.Get("X-Amz-Security-Token") + query.Get("x-amz-security-token")
+		}
+		if err := s.checkToken(token); err != nil {
+			return true, err
+		}
+	}
+	switch {
+	case s.config.SignatureV2Only && (strings.HasPrefix(auth, signV4Algorithm) || query.Get("X-Amz-Signature") != ""):
+		return true, errSignatureDoesNotMatch
+	case strings.HasPrefix(auth, signV4Algorithm):
+		return true, s.verifyV4(r, body)
+	case strings.HasPrefix(auth, "AWS "):
+		return true, s.verifyV2(r)
+	case auth != "":
+		return false, errAccessDenied
+	case query.Get("X-Amz-Signature") != "":
+		return true, s.verifyPresignedV4(r)
+	case query.Get("Signature") != "":
+		return true, s.verifyPresignedV2(r)
+	}
+	return false, nil
+}
+
+// checkToken rejects signed requests whose session token is not the server's.
+func (s *Server) checkToken(token string) error {
+	switch {
+	case token == s.config.SessionToken:
+		return nil
+	case s.expiredTokens[token]:
+		return errTokenExpired
+	}
+	return errInvalidToken
+}
+
+// checkSkew rejects requests dated too far away from the server's clock.
+func (s *Server) checkSkew(date time.Time) error {
+	skew := s.now().Sub(date)
+	if skew > maxClockSkew || skew < -maxClockSkew {
+		return errRequestTimeTooSkewed
+	}
+	return nil
+}
+
+// checkRegion rejects requests signed with signature V4 for another region than the location of
+// their bucket. Bucket locations are answered whatever the region, as clients discover them so.
+func (s *Server) checkRegion(r *http.Request, cred credential) error {
+	if _, ok := r.URL.Query()["location"]; ok {
+		return nil
+	}
+	bucketName, _ := splitPath(s.resourcePath(r))
+	if b, ok := s.buckets[bucketName]; ok && b.location != "" && b.location != cred.region {
+		return errAuthorizationHeaderMalformed
+	}
+	return nil
+}
+
+// credential - parsed credential of signature V4, accessKey/date/region/service/aws4_request.
+type credential struct {
+	accessKey string
+	date      string
+	region    string
+	scope     string
+}
+
+func (s *Server) parseCredential(value string) (credential, error) {
+	fields := strings.Split(value, "/")
+	if len(fields) != 5 || fields[3] != "s3" || fields[4] != "aws4_request" {
+		return credential{}, errInvalidArgument
+	}
+	if fields[0] != s.config.AccessKeyID {
+		return credential{}, errInvalidAccessKeyID
+	}
+	return credential{
+		accessKey: fields[0],
+		date:      fields[1],
+		region:    fields[2],
+		scope:     strings.Join(fields[1:], "/"),
+	}, nil
+}
+
+// verifyV4 checks a request signed with signature V4 in its Authorization header.
+func (s *Server) verifyV4(r *http.Request, body []byte) error {
+	fields := make(map[string]string)
+	for _, field := range strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), signV4Algorithm), ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) == 2 {
+			fields[kv[0]] = kv[1]
+		}
+	}
+	cred, err := s.parseCredential(fields["Credential"])
+	if err != nil {
+		return err
+	}
+	date, e := time.Parse(iso8601Format, r.Header.Get("X-Amz-Date"))
+	if e != nil {
+		return errAccessDenied
+	}
+	if err := s.checkSkew(date); err != nil {
+		return err
+	}
+	if err := s.checkRegion(r, cred); err != nil {
+		return err
+	}
+	hashedPayload := r.Header.Get("X-Amz-Content-Sha256")
+	switch hashedPayload {
+	case "":
+		return errMissingSecurityHeader
+	case unsignedPayload:
+	default:
+		sum := sha256.Sum256(body)
+		if hashedPayload != hex.EncodeToString(sum[:]) {
+			return errXAmzContentSHA256
+		}
+	}
+	canonicalRequest := canonicalRequestV4(r, r.URL.Query(), fields["SignedHeaders"], hashedPayload)
+	signature := s.signV4(cred, date, canonicalRequest)
+	if !hmac.Equal([]byte(signature), []byte(fields["Signature"])) {
+		return errSignatureDoesNotMatch
+	}
+	return nil
+}
+
+// verifyPresignedV4 checks a request presigned with signature V4 in its query.
+func (s *Server) verifyPresignedV4(r *http.Request) error {
+	query := r.URL.Query()
+	if query.Get("X-Amz-Algorithm") != signV4Algorithm {
+		return errAccessDenied
+	}
+	cred, err := s.parseCredential(query.Get("X-Amz-Credential"))
+	if err != nil {
+		return err
+	}
+	date, e := time.Parse(iso8601Format, query.Get("X-Amz-Date"))
+	if e != nil {
+		return errAccessDenied
+	}
+	expires, e := strconv.ParseInt(query.Get("X-Amz-Expires"), 10, 64)
+	if e != nil {
+		return errAccessDenied
+	}
+	if s.now().After(date.Add(time.Duration(expires) * time.Second)) {
+		return errExpiredToken
+	}
+	if err := s.checkRegion(r, cred); err != nil {
+		return err
+	}
+	signature := query.Get("X-Amz-Signature")
+	query.Del("X-Amz-Signature")
+	canonicalRequest := canonicalRequestV4(r, query, query.Get("X-Amz-SignedHeaders"), unsignedPayload)
+	if !hmac.Equal([]byte(s.signV4(cred, date, canonicalRequest)), []byte(signature)) {
+		return errSignatureDoesNotMatch
+	}
+	return nil
+}
+
+// canonicalRequestV4 - canonical form of r signed with signature V4.
+func canonicalRequestV4(r *http.Request, query url.Values, signedHeaders, hashedPayload string) string {
+	var headers bytes.Buffer
+	for _, name := range strings.Split(signedHeaders, ";") {
+		headers.WriteString(name)
+		headers.WriteByte(':')
+		if name == "host" {
+			headers.WriteString(r.Host)
+		} else {
+			headers.WriteString(strings.Join(r.Header[http.CanonicalHeaderKey(name)], ","))
+		}
+		headers.WriteByte('\n')
+	}
+	return strings.Join([]string{
+		r.Method,
+		encodePath(r.URL.Path),
+		strings.Replace(query.Encode(), "+", "%20", -1),
+		headers.String(),
+		signedHeaders,
+		hashedPayload,
+	}, "\n")
+}
+
+// signV4 - signature V4 of canonicalRequest, made at date within the credential's scope.
+func (s *Server) signV4(cred credential, date time.Time, canonicalRequest string) string {
+	sum := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := signV4Algorithm + "\n" + date.Format(iso8601Format) + "\n" + cred.scope + "\n" + hex.EncodeToString(sum[:])
+	return hex.EncodeToString(sumHMAC(s.signingKey(cred), []byte(stringToSign)))
+}
+
+// signingKey - key deriving signatures V4 within the credential's scope.
+func (s *Server) signingKey(cred credential) []byte {
+	key := sumHMAC([]byte("AWS4"+s.config.SecretAccessKey), []byte(cred.date))
+	key = sumHMAC(key, []byte(cred.region))
+	key = sumHMAC(key, []byte("s3"))
+	return sumHMAC(key, []byte("aws4_request"))
+}
+
+// verifyV2 checks a request signed with signature V2 in its Authorization header.
+func (s *Server) verifyV2(r *http.Request) error {
+	kv := strings.SplitN(strings.TrimPrefix(r.Header.Get("Authorization"), "AWS "), ":", 2)
+	if len(kv) != 2 {
+		return errAccessDenied
+	}
+	if kv[0] != s.config.AccessKeyID {
+		return errInvalidAccessKeyID
+	}
+	// X-Amz-Date takes precedence over Date, which is then signed empty.
+	date, dateLine := r.Header.Get("Date"), r.Header.Get("Date")
+	if amzDate := r.Header.Get("X-Amz-Date"); amzDate != "" {
+		date, dateLine = amzDate, ""
+	}
+	t, e := http.ParseTime(date)
+	if e != nil {
+		return errAccessDenied
+	}
+	if err := s.checkSkew(t); err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(s.signV2(r, dateLine)), []byte(kv[1])) {
+		return errSignatureDoesNotMatch
+	}
+	return nil
+}
+
+// verifyPresignedV2 checks a request presigned with signature V2 in its query.
+func (s *Server) verifyPresignedV2(r *http.Request) error {
+	query := r.URL.Query()
+	if query.Get("AWSAccessKeyId") != s.config.AccessKeyID {
+		return errInvalidAccessKeyID
+	}
+	expires, e := strconv.ParseInt(query.Get("Expires"), 10, 64)
+	if e != nil {
+		return errAccessDenied
+	}
+	if s.now().Unix() > expires {
+		return errExpiredToken
+	}
+	if token := query.Get("x-amz-security-token"); token != "" {
+		// Signed as if it were a header.
+		r.Header.Set("X-Amz-Security-Token", token)
+	}
+	if !hmac.Equal([]byte(s.signV2(r, query.Get("Expires"))), []byte(query.Get("Signature"))) {
+		return errSignatureDoesNotMatch
+	}
+	return nil
+}
+
+// signV2 - signature V2 of r, with date standing for its Date header or its expiry.
+func (s *Server) signV2(r *http.Request, date string) string {
+	var buf bytes.Buffer
+	buf.WriteString(r.Method + "\n")
+	buf.WriteString(r.Header.Get("Content-MD5") + "\n")
+	buf.WriteString(r.Header.Get("Content-Type") + "\n")
+	buf.WriteString(date + "\n")
+
+	var amzHeaders []string
+	for name := range r.Header {
+		if lname := strings.ToLower(name); strings.HasPrefix(lname, "x-amz") {
+			amzHeaders = append(amzHeaders, lname)
+		}
+	}
+	sort.Strings(amzHeaders)
+	for _, name := range amzHeaders {
+		buf.WriteString(name + ":" + strings.Join(r.Header[http.CanonicalHeaderKey(name)], ",") + "\n")
+	}
+
+	buf.WriteString(encodePath(s.resourcePath(r)))
+	query := r.URL.Query()
+	separator := "?"
+	for _, resource := range subResources {
+		values, ok := query[resource]
+		if !ok {
+			continue
+		}
+		buf.WriteString(separator + resource)
+		if len(values) > 0 && values[0] != "" {
+			buf.WriteString("=" + url.QueryEscape(values[0]))
+		}
+		separator = "&"
+	}
+	hm := hmac.New(sha1.New, []byte(s.config.SecretAccessKey))
+	hm.Write(buf.Bytes())
+	return base64.StdEncoding.EncodeToString(hm.Sum(nil))
+}
+
+func sumHMAC(key, data []byte) []byte {
+	hm := hmac.New(sha256.New, key)
+	hm.Write(data)
+	return hm.Sum(nil)
+}
+
+// encodePath - URI encoding of path as signed by S3 clients, leaving only unreserved characters as they are.
+func encodePath(path string) string {
+	var buf bytes.Buffer
+	for _, r := range path {
+		switch {
+		case 'A' <= r && r <= 'Z', 'a' <= r && r <= 'z', '0' <= r && r <= '9':
+			buf.WriteRune(r)
+		case r == '-', r == '_', r == '.', r == '~', r == '/':
+			buf.WriteRune(r)
+		default:
+			b := make([]byte, utf8.RuneLen(r))
+			utf8.EncodeRune(b, r)
+			for _, c := range b {
+				buf.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+			}
+		}
+	}
+	return buf.String()
+}
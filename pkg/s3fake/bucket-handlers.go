@@ -0,0 +1,311 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3fake
+
+import (
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// cannedACLs - grants of each canned ACL, besides the owner's full control.
+var cannedACLs = map[string][]grant{
+	"private":            nil,
+	"public-read":        {publicGrant("AllUsers", "READ")},
+	"public-read-write":  {publicGrant("AllUsers", "READ"), publicGrant("AllUsers", "WRITE")},
+	"authenticated-read": {publicGrant("AuthenticatedUsers", "READ")},
+}
+
+func publicGrant(group, permission string) grant {
+	return grant{
+		Grantee:    grantee{Type: "Group", URI: "http://acs.amazonaws.com/groups/global/" + group},
+		Permission: permission,
+	}
+}
+
+// cannedACL - canned ACL requested by the x-amz-acl header, private by default.
+func cannedACL(r *http.Request) (string, error) {
+	acl := r.Header.Get("x-amz-acl")
+	if acl == "" {
+		return "private", nil
+	}
+	if _, ok := cannedACLs[acl]; !ok {
+		return "", errInvalidArgument
+	}
+	return acl, nil
+}
+
+func (s *Server) listBuckets(w http.ResponseWriter, r *http.Request, _, _ string, _ []byte) error {
+	var names []string
+	for name := range s.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := listAllMyBucketsResult{Xmlns: xmlns, Owner: fakeOwner}
+	for _, name := range names {
+		result.Buckets = append(result.Buckets, bucketEntry{Name: name, CreationDate: formatTime(s.buckets[name].created)})
+	}
+	s.writeXML(w, http.StatusOK, result)
+	return nil
+}
+
+func (s *Server) putBucket(w http.ResponseWriter, r *http.Request, bucketName, _ string, body []byte) error {
+	if !isValidBucketName(bucketName) {
+		return errInvalidBucketName
+	}
+	if _, ok := s.buckets[bucketName]; ok {
+		return errBucketAlreadyOwned
+	}
+	acl, err := cannedACL(r)
+	if err != nil {
+		return err
+	}
+	var configuration struct {
+		Location string `xml:"LocationConstraint"`
+	}
+	if len(body) > 0 {
+		if err := xml.Unmarshal(body, &configuration); err != nil {
+			return errMalformedXML
+		}
+	}
+	s.buckets[bucketName] = &bucket{
+		created:  s.now(),
+		acl:      acl,
+		location: configuration.Location,
+		objects:  make(map[string]object),
+		uploads:  make(map[string]*upload),
+	}
+	w.Header().Set("Location", "/"+bucketName)
+	writeEmpty(w, http.StatusOK)
+	return nil
+}
+
+func (s *Server) headBucket(w http.ResponseWriter, r *http.Request, bucketName, _ string, _ []byte) error {
+	if _, err := s.bucket(bucketName); err != nil {
+		return err
+	}
+	writeEmpty(w, http.StatusOK)
+	return nil
+}
+
+func (s *Server) deleteBucket(w http.ResponseWriter, r *http.Request, bucketName, _ string, _ []byte) error {
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	if len(b.objects) > 0 {
+		return errBucketNotEmpty
+	}
+	for _, u := range b.uploads {
+		s.removeUpload(b, u)
+	}
+	delete(s.buckets, bucketName)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) getBucketACL(w http.ResponseWriter, r *http.Request, bucketName, _ string, _ []byte) error {
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	policy := accessControlPolicy{Xmlns: xmlns, Owner: fakeOwner}
+	policy.Grants = append(policy.Grants, grant{
+		Grantee:    grantee{Type: "CanonicalUser", ID: fakeOwner.ID, DisplayName: fakeOwner.DisplayName},
+		Permission: "FULL_CONTROL",
+	})
+	policy.Grants = append(policy.Grants, cannedACLs[b.acl]...)
+	s.writeXML(w, http.StatusOK, policy)
+	return nil
+}
+
+func (s *Server) putBucketACL(w http.ResponseWriter, r *http.Request, bucketName, _ string, body []byte) error {
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	if r.Header.Get("x-amz-acl") == "" && len(body) > 0 {
+		// Only canned ACLs are supported.
+		return errNotImplemented
+	}
+	acl, err := cannedACL(r)
+	if err != nil {
+		return err
+	}
+	b.acl = acl
+	writeEmpty(w, http.StatusOK)
+	return nil
+}
+
+func (s *Server) getBucketLocation(w http.ResponseWriter, r *http.Request, bucketName, _ string, _ []byte) error {
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	s.writeXML(w, http.StatusOK, locationConstraint{Xmlns: xmlns, Location: b.location})
+	return nil
+}
+
+func (s *Server) listObjects(w http.ResponseWriter, r *http.Request, bucketName, _ string, _ []byte) error {
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	query := r.URL.Query()
+	maxKeys, err := queryInt(r, "max-keys", 1000)
+	if err != nil {
+		return err
+	}
+	l := listing{prefix: query.Get("prefix"), marker: query.Get("marker"), delimiter: query.Get("delimiter"), max: maxKeys}
+	keys := b.sortedKeys()
+	matches, prefixes, truncated := l.list(keys, func(i int) bool { return keys[i] > l.marker })
+
+	result := listBucketResult{
+		Xmlns:       xmlns,
+		Name:        bucketName,
+		Prefix:      l.prefix,
+		Marker:      l.marker,
+		MaxKeys:     l.max,
+		Delimiter:   l.delimiter,
+		IsTruncated: truncated,
+	}
+	for _, i := range matches {
+		o := b.objects[keys[i]]
+		result.Contents = append(result.Contents, objectEntry{
+			Key:          keys[i],
+			LastModified: formatTime(o.modTime),
+			ETag:         o.etag,
+			Size:         o.size,
+			StorageClass: "STANDARD",
+			Owner:        fakeOwner,
+		})
+	}
+	for _, prefix := range prefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: prefix})
+	}
+	if truncated {
+		result.NextMarker = l.last(keys, matches, prefixes)
+	}
+	s.writeXML(w, http.StatusOK, result)
+	return nil
+}
+
+func (s *Server) listUploads(w http.ResponseWriter, r *http.Request, bucketName, _ string, _ []byte) error {
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	query := r.URL.Query()
+	maxUploads, err := queryInt(r, "max-uploads", 1000)
+	if err != nil {
+		return err
+	}
+	l := listing{prefix: query.Get("prefix"), marker: query.Get("key-marker"), delimiter: query.Get("delimiter"), max: maxUploads}
+	uploadIDMarker := query.Get("upload-id-marker")
+	uploads := b.sortedUploads()
+	keys := make([]string, len(uploads))
+	for i, u := range uploads {
+		keys[i] = u.key
+	}
+	matches, prefixes, truncated := l.list(keys, func(i int) bool {
+		// Uploads of the key marker come after the upload ID marker, if any.
+		return keys[i] > l.marker || keys[i] == l.marker && uploadIDMarker != "" && uploads[i].id > uploadIDMarker
+	})
+
+	result := listMultipartUploadsResult{
+		Xmlns:          xmlns,
+		Bucket:         bucketName,
+		KeyMarker:      l.marker,
+		UploadIDMarker: uploadIDMarker,
+		Prefix:         l.prefix,
+		Delimiter:      l.delimiter,
+		MaxUploads:     l.max,
+		IsTruncated:    truncated,
+	}
+	for _, i := range matches {
+		result.Uploads = append(result.Uploads, uploadEntry{
+			Key:          uploads[i].key,
+			UploadID:     uploads[i].id,
+			Initiator:    fakeOwner,
+			Owner:        fakeOwner,
+			StorageClass: "STANDARD",
+			Initiated:    formatTime(uploads[i].initiated),
+		})
+	}
+	for _, prefix := range prefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: prefix})
+	}
+	if truncated {
+		result.NextKeyMarker = l.last(keys, matches, prefixes)
+		if len(matches) > 0 && keys[matches[len(matches)-1]] == result.NextKeyMarker {
+			result.NextUploadIDMarker = uploads[matches[len(matches)-1]].id
+		}
+	}
+	s.writeXML(w, http.StatusOK, result)
+	return nil
+}
+
+// listing - parameters of an S3 listing.
+type listing struct {
+	prefix    string
+	marker    string
+	delimiter string
+	max       int
+}
+
+// list returns the indexes of the sorted keys after the marker, as reported by after, which
+// start with the prefix. Keys containing the delimiter past the prefix are rolled up into
+// common prefixes, which count as one entry towards max.
+func (l listing) list(keys []string, after func(i int) bool) (matches []int, prefixes []string, truncated bool) {
+	for i, key := range keys {
+		if !strings.HasPrefix(key, l.prefix) || !after(i) {
+			continue
+		}
+		if l.delimiter != "" {
+			if j := strings.Index(key[len(l.prefix):], l.delimiter); j >= 0 {
+				prefix := key[:len(l.prefix)+j+len(l.delimiter)]
+				if strings.HasPrefix(l.marker, prefix) || len(prefixes) > 0 && prefixes[len(prefixes)-1] == prefix {
+					// Listed already, on this page or on an earlier one.
+					continue
+				}
+				if len(matches)+len(prefixes) == l.max {
+					return matches, prefixes, true
+				}
+				prefixes = append(prefixes, prefix)
+				continue
+			}
+		}
+		if len(matches)+len(prefixes) == l.max {
+			return matches, prefixes, true
+		}
+		matches = append(matches, i)
+	}
+	return matches, prefixes, false
+}
+
+// last - last entry of a listing page, marking where the next page starts.
+func (l listing) last(keys []string, matches []int, prefixes []string) string {
+	var last string
+	if len(matches) > 0 {
+		last = keys[matches[len(matches)-1]]
+	}
+	if len(prefixes) > 0 && prefixes[len(prefixes)-1] > last {
+		last = prefixes[len(prefixes)-1]
+	}
+	return last
+}
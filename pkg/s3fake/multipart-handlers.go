@@ -0,0 +1,192 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3fake
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// minPartSize - smallest size of all parts but the last of a multipart upload.
+	minPartSize = 5 * 1024 * 1024
+	// maxPartNumber - highest part number of a multipart upload.
+	maxPartNumber = 10000
+)
+
+// upload returns the incomplete upload of key named by the uploadId query parameter.
+func (s *Server) upload(r *http.Request, bucketName, key string) (*bucket, *upload, error) {
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return nil, nil, err
+	}
+	u, ok := b.uploads[r.URL.Query().Get("uploadId")]
+	if !ok || u.key != key {
+		return nil, nil, errNoSuchUpload
+	}
+	return b, u, nil
+}
+
+// removeUpload forgets u and the data of its parts.
+func (s *Server) removeUpload(b *bucket, u *upload) {
+	for _, p := range u.parts {
+		s.blobs.remove(p.id)
+	}
+	delete(b.uploads, u.id)
+}
+
+func (s *Server) initiateUpload(w http.ResponseWriter, r *http.Request, bucketName, key string, _ []byte) error {
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	// IDs sort in the order uploads are initiated.
+	s.uploads++
+	u := &upload{
+		id:          fmt.Sprintf("%016x", s.uploads),
+		key:         key,
+		contentType: r.Header.Get("Content-Type"),
+		initiated:   s.now(),
+		parts:       make(map[int]part),
+	}
+	b.uploads[u.id] = u
+	s.writeXML(w, http.StatusOK, initiateMultipartUploadResult{Xmlns: xmlns, Bucket: bucketName, Key: key, UploadID: u.id})
+	return nil
+}
+
+func (s *Server) putPart(w http.ResponseWriter, r *http.Request, bucketName, key string, body []byte) error {
+	_, u, err := s.upload(r, bucketName, key)
+	if err != nil {
+		return err
+	}
+	number, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if err != nil || number < 1 || number > maxPartNumber {
+		return errInvalidArgument
+	}
+	if err := checkMD5(r, body); err != nil {
+		return err
+	}
+	id, err := s.blobs.put(body)
+	if err != nil {
+		return err
+	}
+	if old, ok := u.parts[number]; ok {
+		s.blobs.remove(old.id)
+	}
+	p := part{id: id, size: int64(len(body)), etag: md5ETag(body), modTime: s.now()}
+	u.parts[number] = p
+	w.Header().Set("ETag", p.etag)
+	writeEmpty(w, http.StatusOK)
+	return nil
+}
+
+func (s *Server) listParts(w http.ResponseWriter, r *http.Request, bucketName, key string, _ []byte) error {
+	_, u, err := s.upload(r, bucketName, key)
+	if err != nil {
+		return err
+	}
+	marker, err := queryInt(r, "part-number-marker", 0)
+	if err != nil {
+		return err
+	}
+	maxParts, err := queryInt(r, "max-parts", 1000)
+	if err != nil {
+		return err
+	}
+	result := listPartsResult{
+		Xmlns:            xmlns,
+		Bucket:           bucketName,
+		Key:              key,
+		UploadID:         u.id,
+		Initiator:        fakeOwner,
+		Owner:            fakeOwner,
+		StorageClass:     "STANDARD",
+		PartNumberMarker: marker,
+		MaxParts:         maxParts,
+	}
+	for _, number := range u.partNumbers() {
+		if number <= marker {
+			continue
+		}
+		if len(result.Parts) == maxParts {
+			result.IsTruncated = true
+			break
+		}
+		p := u.parts[number]
+		result.Parts = append(result.Parts, partEntry{PartNumber: number, LastModified: formatTime(p.modTime), ETag: p.etag, Size: p.size})
+		result.NextPartNumberMarker = number
+	}
+	s.writeXML(w, http.StatusOK, result)
+	return nil
+}
+
+func (s *Server) completeUpload(w http.ResponseWriter, r *http.Request, bucketName, key string, body []byte) error {
+	b, u, err := s.upload(r, bucketName, key)
+	if err != nil {
+		return err
+	}
+	var complete completeMultipartUpload
+	if err := xml.Unmarshal(body, &complete); err != nil || len(complete.Parts) == 0 {
+		return errMalformedXML
+	}
+	var data []byte
+	var etags []string
+	for i, completed := range complete.Parts {
+		if i > 0 && completed.PartNumber <= complete.Parts[i-1].PartNumber {
+			return errInvalidPartOrder
+		}
+		p, ok := u.parts[completed.PartNumber]
+		if !ok || strings.Trim(completed.ETag, `"`) != strings.Trim(p.etag, `"`) {
+			return errInvalidPart
+		}
+		if i < len(complete.Parts)-1 && p.size < minPartSize {
+			return errEntityTooSmall
+		}
+		partData, err := s.blobs.get(p.id)
+		if err != nil {
+			return err
+		}
+		data = append(data, partData...)
+		etags = append(etags, p.etag)
+	}
+	o, err := s.storeObject(b, key, u.contentType, data, multipartETag(etags))
+	if err != nil {
+		return err
+	}
+	s.removeUpload(b, u)
+	s.writeXML(w, http.StatusOK, completeMultipartUploadResult{
+		Xmlns:    xmlns,
+		Location: "/" + bucketName + "/" + key,
+		Bucket:   bucketName,
+		Key:      key,
+		ETag:     o.etag,
+	})
+	return nil
+}
+
+func (s *Server) abortUpload(w http.ResponseWriter, r *http.Request, bucketName, key string, _ []byte) error {
+	b, u, err := s.upload(r, bucketName, key)
+	if err != nil {
+		return err
+	}
+	s.removeUpload(b, u)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,262 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3fake
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxFormSize - largest browser upload kept in memory while parsing.
+const maxFormSize = 64 * 1024 * 1024
+
+// checkMD5 verifies body against the Content-MD5 header of r, if any.
+func checkMD5(r *http.Request, body []byte) error {
+	value := r.Header.Get("Content-MD5")
+	if value == "" {
+		return nil
+	}
+	want, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(want) != md5.Size {
+		return errInvalidDigest
+	}
+	if sum := md5.Sum(body); !bytes.Equal(sum[:], want) {
+		return errBadDigest
+	}
+	return nil
+}
+
+// parseRange - first and last byte of the range requested of an object of size. Malformed
+// ranges are ignored, as S3 does.
+func parseRange(value string, size int64) (first, last int64, ok bool, err error) {
+	if !strings.HasPrefix(value, "bytes=") || strings.Contains(value, ",") {
+		return 0, 0, false, nil
+	}
+	bounds := strings.SplitN(strings.TrimPrefix(value, "bytes="), "-", 2)
+	if len(bounds) != 2 {
+		return 0, 0, false, nil
+	}
+	if bounds[0] == "" {
+		// Suffix range, the last bytes of the object.
+		n, e := strconv.ParseInt(bounds[1], 10, 64)
+		if e != nil {
+			return 0, 0, false, nil
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, false, errInvalidRange
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, true, nil
+	}
+	first, e := strconv.ParseInt(bounds[0], 10, 64)
+	if e != nil {
+		return 0, 0, false, nil
+	}
+	last = size - 1
+	if bounds[1] != "" {
+		if last, e = strconv.ParseInt(bounds[1], 10, 64); e != nil || last < first {
+			return 0, 0, false, nil
+		}
+		if last >= size {
+			last = size - 1
+		}
+	}
+	if first >= size {
+		return 0, 0, false, errInvalidRange
+	}
+	return first, last, true, nil
+}
+
+// objectHeaders sets the headers describing o.
+func objectHeaders(w http.ResponseWriter, o object) {
+	w.Header().Set("Content-Type", o.contentType)
+	w.Header().Set("ETag", o.etag)
+	w.Header().Set("Last-Modified", o.modTime.Format(http.TimeFormat))
+	w.Header().Set("Accept-Ranges", "bytes")
+}
+
+// object returns the object stored under key in the named bucket.
+func (s *Server) object(bucketName, key string) (*bucket, object, error) {
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return nil, object{}, err
+	}
+	o, ok := b.objects[key]
+	if !ok {
+		return nil, object{}, errNoSuchKey
+	}
+	return b, o, nil
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, bucketName, key string, _ []byte) error {
+	_, o, err := s.object(bucketName, key)
+	if err != nil {
+		return err
+	}
+	first, last, ranged, err := parseRange(r.Header.Get("Range"), o.size)
+	if err != nil {
+		return err
+	}
+	data, err := s.blobs.get(o.id)
+	if err != nil {
+		return err
+	}
+	objectHeaders(w, o)
+	if !ranged {
+		w.Header().Set("Content-Length", strconv.FormatInt(o.size, 10))
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+		return nil
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(last-first+1, 10))
+	w.Header().Set("Content-Range", "bytes "+strconv.FormatInt(first, 10)+"-"+strconv.FormatInt(last, 10)+"/"+strconv.FormatInt(o.size, 10))
+	w.WriteHeader(http.StatusPartialContent)
+	w.Write(data[first : last+1])
+	return nil
+}
+
+func (s *Server) headObject(w http.ResponseWriter, r *http.Request, bucketName, key string, _ []byte) error {
+	_, o, err := s.object(bucketName, key)
+	if err != nil {
+		return err
+	}
+	objectHeaders(w, o)
+	w.Header().Set("Content-Length", strconv.FormatInt(o.size, 10))
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) putObject(w http.ResponseWriter, r *http.Request, bucketName, key string, body []byte) error {
+	if r.Header.Get("x-amz-copy-source") != "" {
+		return errNotImplemented
+	}
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	if err := checkMD5(r, body); err != nil {
+		return err
+	}
+	o, err := s.storeObject(b, key, r.Header.Get("Content-Type"), body, md5ETag(body))
+	if err != nil {
+		return err
+	}
+	w.Header().Set("ETag", o.etag)
+	writeEmpty(w, http.StatusOK)
+	return nil
+}
+
+// storeObject stores data under key in b, replacing any object there.
+func (s *Server) storeObject(b *bucket, key, contentType string, data []byte, etag string) (object, error) {
+	id, err := s.blobs.put(data)
+	if err != nil {
+		return object{}, err
+	}
+	if old, ok := b.objects[key]; ok {
+		s.blobs.remove(old.id)
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	o := object{id: id, size: int64(len(data)), etag: etag, contentType: contentType, modTime: s.now()}
+	b.objects[key] = o
+	return o, nil
+}
+
+func (s *Server) deleteObject(w http.ResponseWriter, r *http.Request, bucketName, key string, _ []byte) error {
+	b, o, err := s.object(bucketName, key)
+	if err != nil {
+		return err
+	}
+	s.blobs.remove(o.id)
+	delete(b.objects, key)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// postObject serves a browser upload, a form carrying the object and a signed policy.
+func (s *Server) postObject(w http.ResponseWriter, r *http.Request, bucketName string, body []byte) error {
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err := r.ParseMultipartForm(maxFormSize); err != nil {
+		return errMalformedPOSTRequest
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	form := make(map[string]string)
+	for name, values := range r.MultipartForm.Value {
+		if len(values) > 0 {
+			form[strings.ToLower(name)] = values[0]
+		}
+	}
+	files := r.MultipartForm.File["file"]
+	if len(files) != 1 {
+		return errMalformedPOSTRequest
+	}
+	file, err := files[0].Open()
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+	form["bucket"] = bucketName
+	form["key"] = strings.Replace(form["key"], "${filename}", files[0].Filename, -1)
+	if form["key"] == "" {
+		return errMalformedPOSTRequest
+	}
+
+	signed, err := s.verifyPostPolicy(form, int64(len(data)))
+	if err != nil {
+		return err
+	}
+	if !signed && !s.allowed(bucketName, accessWrite) {
+		return errAccessDenied
+	}
+	b, err := s.bucket(bucketName)
+	if err != nil {
+		return err
+	}
+	o, err := s.storeObject(b, form["key"], form["content-type"], data, md5ETag(data))
+	if err != nil {
+		return err
+	}
+	w.Header().Set("ETag", o.etag)
+	w.Header().Set("Location", "/"+bucketName+"/"+form["key"])
+	switch form["success_action_status"] {
+	case "200":
+		writeEmpty(w, http.StatusOK)
+	case "201":
+		s.writeXML(w, http.StatusCreated, postResponse{
+			Location: "/" + bucketName + "/" + form["key"],
+			Bucket:   bucketName,
+			Key:      form["key"],
+			ETag:     o.etag,
+		})
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3fake
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var errPolicyConditionFailed = apiError{http.StatusForbidden, "AccessDenied", "Invalid according to Policy: Policy Condition failed."}
+
+// postPolicy - policy document of a browser upload.
+type postPolicy struct {
+	Expiration string        `json:"expiration"`
+	Conditions []interface{} `json:"conditions"`
+}
+
+// unconditionedFields - form fields which the policy does not need to cover.
+var unconditionedFields = map[string]bool{
+	"awsaccesskeyid":  true,
+	"bucket":          true,
+	"file":            true,
+	"policy":          true,
+	"signature":       true,
+	"x-amz-signature": true,
+}
+
+// verifyPostPolicy checks the signature and the policy of a browser upload of size bytes. form
+// holds the form fields by lower-cased name. It reports whether the upload is signed with the
+// server's credentials, anonymous uploads are left to bucket ACLs.
+func (s *Server) verifyPostPolicy(form map[string]string, size int64) (bool, error) {
+	signed, err := s.verifyPostSignature(form)
+	if err != nil {
+		return false, err
+	}
+	if form["policy"] == "" {
+		if signed && s.config.AccessKeyID != "" {
+			return false, errInvalidPolicyDocument
+		}
+		return signed, nil
+	}
+	document, e := base64.StdEncoding.DecodeString(form["policy"])
+	if e != nil {
+		return false, errInvalidPolicyDocument
+	}
+	var policy postPolicy
+	if e := json.Unmarshal(document, &policy); e != nil {
+		return false, errInvalidPolicyDocument
+	}
+	expiration, e := time.Parse(time.RFC3339, policy.Expiration)
+	if e != nil {
+		return false, errInvalidPolicyDocument
+	}
+	if s.now().After(expiration) {
+		return false, errExpiredToken
+	}
+
+	conditioned := make(map[string]bool)
+	for _, condition := range policy.Conditions {
+		switch condition := condition.(type) {
+		case map[string]interface{}:
+			for field, value := range condition {
+				field = strings.ToLower(field)
+				conditioned[field] = true
+				if value, ok := value.(string); !ok || form[field] != value {
+					return false, errPolicyConditionFailed
+				}
+			}
+		case []interface{}:
+			if len(condition) != 3 {
+				return false, errInvalidPolicyDocument
+			}
+			op, _ := condition[0].(string)
+			if strings.ToLower(op) == "content-length-range" {
+				min, ok1 := condition[1].(float64)
+				max, ok2 := condition[2].(float64)
+				if !ok1 || !ok2 {
+					return false, errInvalidPolicyDocument
+				}
+				if size < int64(min) {
+					return false, errEntityTooSmall
+				}
+				if size > int64(max) {
+					return false, errEntityTooLarge
+				}
+				continue
+			}
+			field, ok1 := condition[1].(string)
+			value, ok2 := condition[2].(string)
+			if !ok1 || !ok2 || !strings.HasPrefix(field, "$") {
+				return false, errInvalidPolicyDocument
+			}
+			field = strings.ToLower(strings.TrimPrefix(field, "$"))
+			conditioned[field] = true
+			switch strings.ToLower(op) {
+			case "eq":
+				if form[field] != value {
+					return false, errPolicyConditionFailed
+				}
+			case "starts-with":
+				if !strings.HasPrefix(form[field], value) {
+					return false, errPolicyConditionFailed
+				}
+			default:
+				return false, errInvalidPolicyDocument
+			}
+		default:
+			return false, errInvalidPolicyDocument
+		}
+	}
+	// Like S3, every field of the form must be covered by the policy.
+	for field := range form {
+		if !conditioned[field] && !unconditionedFields[field] && !strings.HasPrefix(field, "x-ignore-") {
+			return false, errPolicyConditionFailed
+		}
+	}
+	return signed, nil
+}
+
+// verifyPostSignature checks the signature of the policy of a browser upload, made with either
+// signature V4 or V2. It reports whether the policy is signed.
+func (s *Server) verifyPostSignature(form map[string]string) (bool, error) {
+	if s.config.AccessKeyID == "" {
+		return true, nil
+	}
+	if form["x-amz-signature"] != "" || form["signature"] != "" {
+		if err := s.checkToken(form["x-amz-security-token"]); err != nil {
+			return false, err
+		}
+	}
+	switch {
+	case form["x-amz-signature"] != "":
+		if form["x-amz-algorithm"] != signV4Algorithm {
+			return false, errAccessDenied
+		}
+		cred, err := s.parseCredential(form["x-amz-credential"])
+		if err != nil {
+			return false, err
+		}
+		signature := hex.EncodeToString(sumHMAC(s.signingKey(cred), []byte(form["policy"])))
+		if !hmac.Equal([]byte(signature), []byte(form["x-amz-signature"])) {
+			return false, errSignatureDoesNotMatch
+		}
+		return true, nil
+	case form["signature"] != "":
+		if form["awsaccesskeyid"] != s.config.AccessKeyID {
+			return false, errInvalidAccessKeyID
+		}
+		hm := hmac.New(sha1.New, []byte(s.config.SecretAccessKey))
+		hm.Write([]byte(form["policy"]))
+		if !hmac.Equal([]byte(base64.StdEncoding.EncodeToString(hm.Sum(nil))), []byte(form["signature"])) {
+			return false, errSignatureDoesNotMatch
+		}
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,308 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package s3fake implements a fake S3 server for offline integration tests. It serves the subset
+// of the S3 REST API which mc relies on: buckets, objects, multipart and incomplete uploads,
+// canned ACLs, browser uploads and requests signed or presigned with signature V2 or V4.
+// State lives in memory, object data optionally in a directory.
+package s3fake
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config - settings of a fake S3 server.
+type Config struct {
+	// AccessKeyID and SecretAccessKey are the only credentials the server accepts. A server
+	// without credentials accepts every request, signed or not.
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// SessionToken, when set, must accompany signed requests, as with temporary credentials.
+	SessionToken string
+
+	// Dir, when set, is an existing directory holding object data in place of memory.
+	Dir string
+
+	// Now, when set, replaces the server's clock.
+	Now func() time.Time
+
+	// Domain, when set, makes requests to hosts ‘<bucket>.<Domain>’ address the bucket by host
+	// name, as virtual host styled requests do.
+	Domain string
+
+	// SignatureV2Only, when set, rejects requests signed with signature V4, as older servers do.
+	SignatureV2Only bool
+}
+
+// Server - fake S3 server, serving requests as an http.Handler.
+type Server struct {
+	// URL - endpoint of the server started by NewServer.
+	URL string
+
+	config     Config
+	httpServer *httptest.Server
+
+	mutex         sync.Mutex
+	buckets       map[string]*bucket
+	blobs         *blobs
+	requests      int64
+	uploads       int64
+	expiredTokens map[string]bool
+}
+
+// New returns a fake S3 server, to be served by the caller.
+func New(config Config) *Server {
+	return &Server{
+		config:        config,
+		buckets:       make(map[string]*bucket),
+		blobs:         newBlobs(config.Dir),
+		expiredTokens: make(map[string]bool),
+	}
+}
+
+// SetCredentials rotates the credentials the server accepts. Requests with the session token
+// rotated away fail with ExpiredToken from then on.
+func (s *Server) SetCredentials(accessKeyID, secretAccessKey, sessionToken string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.config.SessionToken != "" && s.config.SessionToken != sessionToken {
+		s.expiredTokens[s.config.SessionToken] = true
+	}
+	s.config.AccessKeyID = accessKeyID
+	s.config.SecretAccessKey = secretAccessKey
+	s.config.SessionToken = sessionToken
+}
+
+// NewServer returns a fake S3 server listening on a loopback address, see Server.URL.
+func NewServer(config Config) *Server {
+	s := New(config)
+	s.httpServer = httptest.NewServer(s)
+	s.URL = s.httpServer.URL
+	return s
+}
+
+// Close stops a server started by NewServer.
+func (s *Server) Close() {
+	if s.httpServer != nil {
+		s.httpServer.Close()
+	}
+}
+
+// now - time on the server's clock.
+func (s *Server) now() time.Time {
+	if s.config.Now != nil {
+		return s.config.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+// access - permission required by a request, checked against the bucket ACL for anonymous requests.
+type access int
+
+const (
+	accessRead  access = iota // granted by public-read and public-read-write
+	accessWrite               // granted by public-read-write
+	accessOwner               // never granted
+)
+
+// handler serves a request on the named bucket and key, given its payload.
+type handler func(w http.ResponseWriter, r *http.Request, bucketName, key string, body []byte) error
+
+// ServeHTTP serves an S3 request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Read the payload before locking, clients may be slow to send it.
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.requests++
+	w.Header().Set("x-amz-request-id", strconv.FormatInt(s.requests, 10))
+	w.Header().Set("Date", s.now().Format(http.TimeFormat))
+	w.Header().Set("Server", "s3fake")
+
+	bucketName, key := splitPath(s.resourcePath(r))
+	if r.Method == "POST" && bucketName != "" && key == "" {
+		// Browser uploads are authenticated by their policy.
+		if err := s.postObject(w, r, bucketName, body); err != nil {
+			s.writeError(w, r, err)
+		}
+		return
+	}
+
+	signed, err := s.authenticate(r, body)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	h, required := s.route(r, bucketName, key)
+	if h == nil {
+		s.writeError(w, r, errMethodNotAllowed)
+		return
+	}
+	if !signed && !s.allowed(bucketName, required) {
+		s.writeError(w, r, errAccessDenied)
+		return
+	}
+	if err := h(w, r, bucketName, key, body); err != nil {
+		s.writeError(w, r, err)
+	}
+}
+
+// route returns the handler of a request, with the permission it requires.
+func (s *Server) route(r *http.Request, bucketName, key string) (handler, access) {
+	query := r.URL.Query()
+	has := func(name string) bool {
+		_, ok := query[name]
+		return ok
+	}
+	switch {
+	case bucketName == "":
+		if r.Method == "GET" {
+			return s.listBuckets, accessOwner
+		}
+	case key == "":
+		switch r.Method {
+		case "GET":
+			switch {
+			case has("acl"):
+				return s.getBucketACL, accessOwner
+			case has("location"):
+				return s.getBucketLocation, accessOwner
+			case has("uploads"):
+				return s.listUploads, accessRead
+			}
+			return s.listObjects, accessRead
+		case "HEAD":
+			return s.headBucket, accessRead
+		case "PUT":
+			if has("acl") {
+				return s.putBucketACL, accessOwner
+			}
+			return s.putBucket, accessOwner
+		case "DELETE":
+			return s.deleteBucket, accessOwner
+		}
+	default:
+		switch r.Method {
+		case "GET":
+			if has("uploadId") {
+				return s.listParts, accessWrite
+			}
+			return s.getObject, accessRead
+		case "HEAD":
+			return s.headObject, accessRead
+		case "PUT":
+			if has("uploadId") {
+				return s.putPart, accessWrite
+			}
+			return s.putObject, accessWrite
+		case "POST":
+			switch {
+			case has("uploads"):
+				return s.initiateUpload, accessWrite
+			case has("uploadId"):
+				return s.completeUpload, accessWrite
+			}
+		case "DELETE":
+			if has("uploadId") {
+				return s.abortUpload, accessWrite
+			}
+			return s.deleteObject, accessWrite
+		}
+	}
+	return nil, accessOwner
+}
+
+// allowed reports whether the ACL of the named bucket grants required to anonymous requests.
+// Requests on missing buckets are let through to report them missing.
+func (s *Server) allowed(bucketName string, required access) bool {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return required != accessOwner
+	}
+	switch required {
+	case accessRead:
+		return b.acl == "public-read" || b.acl == "public-read-write"
+	case accessWrite:
+		return b.acl == "public-read-write"
+	}
+	return false
+}
+
+// bucket returns the named bucket.
+func (s *Server) bucket(name string) (*bucket, error) {
+	b, ok := s.buckets[name]
+	if !ok {
+		return nil, errNoSuchBucket
+	}
+	return b, nil
+}
+
+// resourcePath - path of a request with its bucket, which virtual host styled requests carry in
+// their host name.
+func (s *Server) resourcePath(r *http.Request) string {
+	if s.config.Domain == "" {
+		return r.URL.Path
+	}
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if !strings.HasSuffix(host, "."+s.config.Domain) {
+		return r.URL.Path
+	}
+	return "/" + strings.TrimSuffix(host, "."+s.config.Domain) + r.URL.Path
+}
+
+// splitPath - bucket name and object key of a path style request.
+func splitPath(path string) (bucketName, key string) {
+	path = strings.TrimPrefix(path, "/")
+	if i := strings.Index(path, "/"); i >= 0 {
+		return path[:i], path[i+1:]
+	}
+	return path, ""
+}
+
+// queryInt - integer query parameter, def when it is missing.
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		return 0, errInvalidArgument
+	}
+	return i, nil
+}
+
+// writeEmpty writes a response without a body.
+func writeEmpty(w http.ResponseWriter, status int) {
+	w.Header().Set("Content-Length", "0")
+	w.WriteHeader(status)
+}
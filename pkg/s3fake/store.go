@@ -0,0 +1,193 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3fake
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// object - metadata of an object, its data is held by the blob store under id.
+type object struct {
+	id          string
+	size        int64
+	etag        string
+	contentType string
+	modTime     time.Time
+}
+
+// part - an uploaded part of a multipart upload.
+type part struct {
+	id      string
+	size    int64
+	etag    string
+	modTime time.Time
+}
+
+// upload - an incomplete multipart upload.
+type upload struct {
+	id          string
+	key         string
+	contentType string
+	initiated   time.Time
+	parts       map[int]part
+}
+
+// size - total size of the parts uploaded so far.
+func (u *upload) size() (size int64) {
+	for _, p := range u.parts {
+		size += p.size
+	}
+	return size
+}
+
+// partNumbers - part numbers in ascending order.
+func (u *upload) partNumbers() []int {
+	var numbers []int
+	for number := range u.parts {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// bucket - objects and incomplete uploads by key.
+type bucket struct {
+	created  time.Time
+	acl      string
+	location string
+	objects  map[string]object
+	uploads  map[string]*upload
+}
+
+// sortedKeys - keys of all objects in lexical order, as S3 lists them.
+func (b *bucket) sortedKeys() []string {
+	var keys []string
+	for key := range b.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedUploads - incomplete uploads ordered by key, then by initiation.
+func (b *bucket) sortedUploads() []*upload {
+	var uploads []*upload
+	for _, u := range b.uploads {
+		uploads = append(uploads, u)
+	}
+	sort.Sort(byKeyAndInitiated(uploads))
+	return uploads
+}
+
+type byKeyAndInitiated []*upload
+
+func (u byKeyAndInitiated) Len() int      { return len(u) }
+func (u byKeyAndInitiated) Swap(i, j int) { u[i], u[j] = u[j], u[i] }
+func (u byKeyAndInitiated) Less(i, j int) bool {
+	if u[i].key != u[j].key {
+		return u[i].key < u[j].key
+	}
+	return u[i].id < u[j].id
+}
+
+// blobs holds object and part data, in memory or as files in a directory.
+type blobs struct {
+	dir  string
+	mem  map[string][]byte
+	next int64
+}
+
+func newBlobs(dir string) *blobs {
+	return &blobs{dir: dir, mem: make(map[string][]byte)}
+}
+
+// put stores data and returns its id.
+func (b *blobs) put(data []byte) (string, error) {
+	b.next++
+	id := strconv.FormatInt(b.next, 10)
+	if b.dir == "" {
+		b.mem[id] = data
+		return id, nil
+	}
+	if err := ioutil.WriteFile(filepath.Join(b.dir, id), data, 0600); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// get returns the data stored under id.
+func (b *blobs) get(id string) ([]byte, error) {
+	if b.dir == "" {
+		return b.mem[id], nil
+	}
+	return ioutil.ReadFile(filepath.Join(b.dir, id))
+}
+
+// remove forgets the data stored under id.
+func (b *blobs) remove(id string) {
+	if b.dir == "" {
+		delete(b.mem, id)
+		return
+	}
+	os.Remove(filepath.Join(b.dir, id))
+}
+
+// md5ETag - quoted ETag of data put in a single request.
+func md5ETag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// multipartETag - quoted ETag of an object assembled from parts, computed like S3 does.
+func multipartETag(etags []string) string {
+	var sums []byte
+	for _, etag := range etags {
+		sum, _ := hex.DecodeString(strings.Trim(etag, `"`))
+		sums = append(sums, sum...)
+	}
+	sum := md5.Sum(sums)
+	return `"` + hex.EncodeToString(sum[:]) + "-" + strconv.Itoa(len(etags)) + `"`
+}
+
+// isValidBucketName - DNS compatible bucket names, as S3 requires outside of us-east-1.
+func isValidBucketName(name string) bool {
+	if len(name) < 3 || len(name) > 63 {
+		return false
+	}
+	if name[0] == '.' || name[0] == '-' || name[len(name)-1] == '.' || name[len(name)-1] == '-' {
+		return false
+	}
+	if strings.Contains(name, "..") {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case 'a' <= r && r <= 'z', '0' <= r && r <= '9', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrKeyringUnavailable is returned on systems without a keyring mc knows to use.
+var ErrKeyringUnavailable = errors.New("no keyring available, such as macOS Keychain or a Secret Service provider with ‘secret-tool’")
+
+// Keyring - a secret in the keyring of the operating system, found by service and account. macOS
+// Keychain is used through ‘security’, Secret Service providers such as GNOME Keyring through
+// ‘secret-tool’.
+type Keyring struct {
+	Service string
+	Account string
+}
+
+// keyringProbe - entry looked up to tell whether the keyring can be reached.
+var keyringProbe = Keyring{Service: "mc", Account: "mc-keyring-probe"}
+
+// checkKeyringTool fails with ErrKeyringUnavailable unless the command line tool of the keyring
+// is installed.
+func checkKeyringTool() error {
+	var tool string
+	switch runtime.GOOS {
+	case "darwin":
+		tool = "security"
+	case "linux", "freebsd", "openbsd", "netbsd":
+		tool = "secret-tool"
+	default:
+		return ErrKeyringUnavailable
+	}
+	if _, e := exec.LookPath(tool); e != nil {
+		return ErrKeyringUnavailable
+	}
+	return nil
+}
+
+// KeyringAvailable reports whether the system has a keyring mc knows to use and that keyring
+// can be reached, e.g. ‘secret-tool’ finds a Secret Service provider on D-Bus and macOS
+// Keychain is unlocked.
+func KeyringAvailable() bool {
+	if checkKeyringTool() != nil {
+		return false
+	}
+	_, e := keyringProbe.Get()
+	return e == nil
+}
+
+// Get returns the secret, nil if there is none. Failures of the keyring itself, such as a
+// locked keychain or no Secret Service on D-Bus, are returned as errors.
+func (k Keyring) Get() ([]byte, error) {
+	if e := checkKeyringTool(); e != nil {
+		return nil, e
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "find-generic-password", "-s", k.Service, "-a", k.Account, "-w")
+	} else {
+		cmd = exec.Command("secret-tool", "lookup", "service", k.Service, "account", k.Account)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, e := cmd.Output()
+	if e != nil {
+		message := strings.TrimSpace(stderr.String())
+		if exitErr, ok := e.(*exec.ExitError); ok && notFound(exitErr, message) {
+			return nil, nil
+		}
+		if message != "" {
+			return nil, errors.New(message)
+		}
+		return nil, e
+	}
+	return bytes.TrimSuffix(output, []byte("\n")), nil
+}
+
+// Set stores secret, replacing any previous one.
+func (k Keyring) Set(secret []byte) error {
+	if e := checkKeyringTool(); e != nil {
+		return e
+	}
+	return run(k.setCommand(secret))
+}
+
+// Delete removes the secret, if any.
+func (k Keyring) Delete() error {
+	if e := checkKeyringTool(); e != nil {
+		return e
+	}
+	if runtime.GOOS == "darwin" {
+		if secret, e := k.Get(); e != nil || secret == nil {
+			return e
+		}
+		return run(exec.Command("security", "delete-generic-password", "-s", k.Service, "-a", k.Account))
+	}
+	return run(exec.Command("secret-tool", "clear", "service", k.Service, "account", k.Account))
+}
+
+// run runs cmd, failing with its error output.
+func run(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if e := cmd.Run(); e != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return errors.New(message)
+		}
+		return e
+	}
+	return nil
+}
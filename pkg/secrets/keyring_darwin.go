@@ -0,0 +1,43 @@
+//go:build darwin
+// +build darwin
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secrets
+
+import (
+	"bytes"
+	"os/exec"
+	"syscall"
+)
+
+// setCommand returns the command storing secret in macOS Keychain. Arguments of commands are
+// visible to other users, so ‘security’ is left to prompt for the secret, which it reads from
+// stdin once it has no terminal to open. It asks for the secret twice, to confirm it.
+func (k Keyring) setCommand(secret []byte) *exec.Cmd {
+	cmd := exec.Command("security", "add-generic-password", "-U", "-s", k.Service, "-a", k.Account, "-w")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	line := append(append([]byte(nil), secret...), '\n')
+	cmd.Stdin = bytes.NewReader(bytes.Repeat(line, 2))
+	return cmd
+}
+
+// notFound reports whether ‘security’ failed because the secret is missing, which it tells by
+// exiting with errSecItemNotFound. A locked keychain or a denied prompt exit otherwise.
+func notFound(e *exec.ExitError, stderr string) bool {
+	return e.ExitCode() == 44
+}
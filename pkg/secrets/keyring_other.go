@@ -0,0 +1,39 @@
+//go:build !darwin
+// +build !darwin
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secrets
+
+import (
+	"bytes"
+	"os/exec"
+)
+
+// setCommand returns the command storing secret with a Secret Service provider, which reads it
+// from stdin.
+func (k Keyring) setCommand(secret []byte) *exec.Cmd {
+	cmd := exec.Command("secret-tool", "store", "--label="+k.Service+" "+k.Account, "service", k.Service, "account", k.Account)
+	cmd.Stdin = bytes.NewReader(secret)
+	return cmd
+}
+
+// notFound reports whether ‘secret-tool’ failed because the secret is missing, which it tells by
+// exiting with 1 silently. Failures to reach a Secret Service provider are written to stderr.
+func notFound(e *exec.ExitError, stderr string) bool {
+	return e.ExitCode() == 1 && stderr == ""
+}
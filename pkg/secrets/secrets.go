@@ -0,0 +1,91 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package secrets keeps secrets of mc away from plain text, either encrypted with a key derived
+// from a passphrase or in the keyring of the operating system.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// ErrWrongPassphrase is returned when opening sealed data with a passphrase other than its own.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted data")
+
+// Key derivation related constants.
+const (
+	saltSize   = 16
+	keySize    = 32
+	iterations = 100000
+)
+
+// Sealed - data encrypted with AES-256-GCM, under a key derived from a passphrase with
+// PBKDF2-HMAC-SHA256. Byte slices marshal to base64 in JSON.
+type Sealed struct {
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// Seal encrypts data with a key derived from passphrase, under a fresh salt.
+func Seal(passphrase string, data []byte) (*Sealed, error) {
+	sealed := &Sealed{Iterations: iterations, Salt: make([]byte, saltSize)}
+	if _, e := rand.Read(sealed.Salt); e != nil {
+		return nil, e
+	}
+	aead, e := sealed.aead(passphrase)
+	if e != nil {
+		return nil, e
+	}
+	sealed.Nonce = make([]byte, aead.NonceSize())
+	if _, e := rand.Read(sealed.Nonce); e != nil {
+		return nil, e
+	}
+	sealed.Data = aead.Seal(nil, sealed.Nonce, data, nil)
+	return sealed, nil
+}
+
+// Open decrypts sealed data with passphrase.
+func (s *Sealed) Open(passphrase string) ([]byte, error) {
+	aead, e := s.aead(passphrase)
+	if e != nil {
+		return nil, e
+	}
+	if len(s.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	data, e := aead.Open(nil, s.Nonce, s.Data, nil)
+	if e != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return data, nil
+}
+
+// aead - cipher keyed from passphrase.
+func (s *Sealed) aead(passphrase string) (cipher.AEAD, error) {
+	block, e := aes.NewCipher(pbkdf2.Key([]byte(passphrase), s.Salt, s.Iterations, keySize, sha256.New))
+	if e != nil {
+		return nil, e
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,95 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secrets
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+func (s *MySuite) TestSeal(c *C) {
+	data := []byte(`{"s3.amazonaws.com":{"secretAccessKey":"secret"}}`)
+	sealed, err := Seal("passphrase", data)
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Contains(sealed.Data, []byte("secret")), Equals, false)
+
+	opened, err := sealed.Open("passphrase")
+	c.Assert(err, IsNil)
+	c.Assert(opened, DeepEquals, data)
+
+	_, err = sealed.Open("wrong")
+	c.Assert(err, Equals, ErrWrongPassphrase)
+
+	// Same data, fresh salt and nonce.
+	resealed, err := Seal("passphrase", data)
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Equal(resealed.Data, sealed.Data), Equals, false)
+}
+
+// TestKeyringGet runs Get against a stand-in ‘secret-tool’, telling missing secrets apart from
+// an unreachable Secret Service.
+func (s *MySuite) TestKeyringGet(c *C) {
+	if runtime.GOOS != "linux" {
+		c.Skip("stand-in ‘secret-tool’ is a shell script")
+	}
+	dir, e := ioutil.TempDir("", "secrets-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(dir)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir)
+
+	tool := filepath.Join(dir, "secret-tool")
+	setTool := func(script string) {
+		c.Assert(ioutil.WriteFile(tool, []byte("#!/bin/sh\n"+script+"\n"), 0700), IsNil)
+	}
+	keyring := Keyring{Service: "mc", Account: "/home/user/.mc/config.json"}
+
+	setTool("echo secret")
+	secret, e := keyring.Get()
+	c.Assert(e, IsNil)
+	c.Assert(string(secret), Equals, "secret")
+	c.Assert(KeyringAvailable(), Equals, true)
+
+	// Missing secrets exit silently.
+	setTool("exit 1")
+	secret, e = keyring.Get()
+	c.Assert(e, IsNil)
+	c.Assert(secret, IsNil)
+	c.Assert(KeyringAvailable(), Equals, true)
+
+	setTool("echo 'Cannot autolaunch D-Bus without X11 $DISPLAY' >&2; exit 1")
+	_, e = keyring.Get()
+	c.Assert(e, ErrorMatches, "Cannot autolaunch D-Bus without X11 .*")
+	c.Assert(KeyringAvailable(), Equals, false)
+
+	os.Remove(tool)
+	_, e = keyring.Get()
+	c.Assert(e, Equals, ErrKeyringUnavailable)
+	c.Assert(KeyringAvailable(), Equals, false)
+}
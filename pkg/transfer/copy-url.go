@@ -0,0 +1,245 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// CopyURLs - a source object and the target it is copied to.
+type CopyURLs struct {
+	SourceContent *client.Content
+	TargetContent *client.Content
+	Error         *probe.Error `json:"-"`
+}
+
+// CopyURLsType - kind of copy, as told by its arguments.
+type CopyURLsType uint8
+
+// Kinds of copy.
+const (
+	CopyURLsTypeInvalid CopyURLsType = iota
+	CopyURLsTypeA                    // file to file
+	CopyURLsTypeB                    // file to dir
+	CopyURLsTypeC                    // recursive to dir
+	CopyURLsTypeD                    // complex to dir
+)
+
+//   NOTE: All the parse rules should reduced to A: Copy(Source, Target).
+//
+//   * VALID RULES
+//   =======================
+//   A: copy(f, f) -> copy(f, f)
+//   B: copy(f, d) -> copy(f, d/f) -> A
+//   C: copy(d1..., d2) -> []copy(d1/f, d2/d1/f) -> []A
+//   D: copy([]{d1... | f}, d2) -> []{copy(d1/f, d2/d1/f) | copy(f, d2/f )} -> []A
+//
+//   * INVALID RULES
+//   =========================
+//   A: copy(d, *)
+//   B: copy(d..., f)
+//   C: copy(*, d...)
+
+// GuessCopyURLType guesses the type of URL. This approach all allows prepareURL
+// functions to accurately report failure causes.
+func GuessCopyURLType(ctx context.Context, opts Options, sourceURLs []string, targetURL string) CopyURLsType {
+	if strings.TrimSpace(targetURL) == "" || targetURL == "" { // Target is empty
+		return CopyURLsTypeInvalid
+	}
+	if len(sourceURLs) == 0 || sourceURLs == nil { // Source list is empty
+		return CopyURLsTypeInvalid
+	}
+	for _, sourceURL := range sourceURLs {
+		if sourceURL == "" { // One of the source is empty
+			return CopyURLsTypeInvalid
+		}
+	}
+	if len(sourceURLs) == 1 { // 1 Source, 1 Target
+		switch {
+		// Type C
+		case IsURLRecursive(sourceURLs[0]):
+			return CopyURLsTypeC
+		// Type B
+		case IsTargetURLDir(ctx, opts, targetURL):
+			return CopyURLsTypeB
+		// Type A
+		default:
+			return CopyURLsTypeA
+		}
+	} // else Type D
+	return CopyURLsTypeD
+}
+
+// SINGLE SOURCE - Type A: copy(f, f) -> copy(f, f)
+// prepareCopyURLsTypeA - prepares target and source URLs for copying.
+func prepareCopyURLsTypeA(ctx context.Context, opts Options, sourceURL string, targetURL string) CopyURLs {
+	_, sourceContent, err := opts.stat(ctx, sourceURL)
+	if err != nil {
+		// Source does not exist or insufficient privileges.
+		return CopyURLs{Error: err.Trace(sourceURL)}
+	}
+	if !sourceContent.Type.IsRegular() {
+		// Source is not a regular file
+		return CopyURLs{Error: probe.NewError(InvalidSource{URL: sourceURL})}
+	}
+	// All OK.. We can proceed. Type A
+	sourceContent.Name = sourceURL
+	return CopyURLs{SourceContent: sourceContent, TargetContent: &client.Content{Name: targetURL}}
+}
+
+// SINGLE SOURCE - Type B: copy(f, d) -> copy(f, d/f) -> A
+// prepareCopyURLsTypeB - prepares target and source URLs for copying.
+func prepareCopyURLsTypeB(ctx context.Context, opts Options, sourceURL string, targetURL string) CopyURLs {
+	_, sourceContent, err := opts.stat(ctx, sourceURL)
+	if err != nil {
+		// Source does not exist or insufficient privileges.
+		return CopyURLs{Error: err.Trace(sourceURL)}
+	}
+	if !sourceContent.Type.IsRegular() {
+		if sourceContent.Type.IsDir() {
+			return CopyURLs{Error: probe.NewError(SourceIsDir{URL: sourceURL})}
+		}
+		// Source is not a regular file.
+		return CopyURLs{Error: probe.NewError(InvalidSource{URL: sourceURL})}
+	}
+
+	// All OK.. We can proceed. Type B: source is a file, target is a folder and exists.
+	{
+		sourceURLParse := client.NewURL(sourceURL)
+		targetURLParse := client.NewURL(targetURL)
+		targetURLParse.Path = filepath.Join(targetURLParse.Path, filepath.Base(sourceURLParse.Path))
+		return prepareCopyURLsTypeA(ctx, opts, sourceURL, targetURLParse.String())
+	}
+}
+
+// SINGLE SOURCE - Type C: copy(d1..., d2) -> []copy(d1/f, d1/d2/f) -> []A
+// prepareCopyRecursiveURLTypeC - prepares target and source URLs for copying.
+func prepareCopyURLsTypeC(ctx context.Context, opts Options, sourceURL, targetURL string) <-chan CopyURLs {
+	copyURLsCh := make(chan CopyURLs)
+	go func(sourceURL, targetURL string, copyURLsCh chan CopyURLs) {
+		defer close(copyURLsCh)
+		if !IsURLRecursive(sourceURL) {
+			// Source is not of recursive type.
+			copyURLsCh <- CopyURLs{Error: probe.NewError(SourceNotRecursive{URL: sourceURL})}
+			return
+		}
+
+		// add `/` after trimming off `...` to emulate folders
+		sourceURL = StripRecursiveURL(sourceURL)
+		sourceClient, sourceContent, err := opts.stat(ctx, sourceURL)
+		if err != nil {
+			// Source does not exist or insufficient privileges.
+			copyURLsCh <- CopyURLs{Error: err.Trace(sourceURL)}
+			return
+		}
+
+		if !sourceContent.Type.IsDir() {
+			// Source is not a dir.
+			copyURLsCh <- CopyURLs{Error: probe.NewError(SourceIsNotDir{URL: sourceURL})}
+			return
+		}
+
+		for sourceContent := range sourceClient.List(ctx, true, false) {
+			if sourceContent.Err != nil {
+				// Listing failed.
+				copyURLsCh <- CopyURLs{Error: sourceContent.Err.Trace()}
+				continue
+			}
+
+			if !sourceContent.Content.Type.IsRegular() {
+				// Source is not a regular file. Skip it for copy.
+				continue
+			}
+
+			// All OK.. We can proceed. Type B: source is a file, target is a folder and exists.
+			sourceURLParse := client.NewURL(sourceURL)
+			targetURLParse := client.NewURL(targetURL)
+
+			sourceURLDelimited := sourceURLParse.String()[:strings.LastIndex(sourceURLParse.String(),
+				string(sourceURLParse.Separator))+1]
+			sourceContentName := sourceContent.Content.Name
+			sourceContentURL := sourceURLDelimited + sourceContentName
+			sourceContentParse := client.NewURL(sourceContentURL)
+
+			// Construct target path from recursive path of source without its prefix dir.
+			newTargetURLParse := *targetURLParse
+			newTargetURLParse.Path = filepath.Join(newTargetURLParse.Path, sourceContentName)
+			copyURLsCh <- prepareCopyURLsTypeA(ctx, opts, sourceContentParse.String(), newTargetURLParse.String())
+		}
+	}(sourceURL, targetURL, copyURLsCh)
+	return copyURLsCh
+}
+
+// MULTI-SOURCE - Type D: copy([]f, d) -> []B
+// prepareCopyURLsTypeD - prepares target and source URLs for copying.
+func prepareCopyURLsTypeD(ctx context.Context, opts Options, sourceURLs []string, targetURL string) <-chan CopyURLs {
+	copyURLsCh := make(chan CopyURLs)
+	go func(sourceURLs []string, targetURL string, copyURLsCh chan CopyURLs) {
+		defer close(copyURLsCh)
+
+		if sourceURLs == nil {
+			// Source list is empty.
+			copyURLsCh <- CopyURLs{Error: probe.NewError(SourceListEmpty{})}
+			return
+		}
+
+		for _, sourceURL := range sourceURLs {
+			// Target is folder. Possibilities are only Type B and C
+			// Is it a recursive URL "..."?
+			if IsURLRecursive(sourceURL) {
+				for cURLs := range prepareCopyURLsTypeC(ctx, opts, sourceURL, targetURL) {
+					copyURLsCh <- cURLs
+				}
+			} else {
+				copyURLsCh <- prepareCopyURLsTypeB(ctx, opts, sourceURL, targetURL)
+			}
+		}
+	}(sourceURLs, targetURL, copyURLsCh)
+	return copyURLsCh
+}
+
+// PrepareCopyURLs - prepares target and source URLs for copying. Channel is
+// closed once all sources are listed, it has to be read to its end.
+func PrepareCopyURLs(ctx context.Context, opts Options, sourceURLs []string, targetURL string) <-chan CopyURLs {
+	copyURLsCh := make(chan CopyURLs)
+	go func(sourceURLs []string, targetURL string, copyURLsCh chan CopyURLs) {
+		defer close(copyURLsCh)
+		switch GuessCopyURLType(ctx, opts, sourceURLs, targetURL) {
+		case CopyURLsTypeA:
+			copyURLsCh <- prepareCopyURLsTypeA(ctx, opts, sourceURLs[0], targetURL)
+		case CopyURLsTypeB:
+			copyURLsCh <- prepareCopyURLsTypeB(ctx, opts, sourceURLs[0], targetURL)
+		case CopyURLsTypeC:
+			for cURLs := range prepareCopyURLsTypeC(ctx, opts, sourceURLs[0], targetURL) {
+				copyURLsCh <- cURLs
+			}
+		case CopyURLsTypeD:
+			for cURLs := range prepareCopyURLsTypeD(ctx, opts, sourceURLs, targetURL) {
+				copyURLsCh <- cURLs
+			}
+		default:
+			copyURLsCh <- CopyURLs{Error: probe.NewError(InvalidArgument{})}
+		}
+	}(sourceURLs, targetURL, copyURLsCh)
+
+	return copyURLsCh
+}
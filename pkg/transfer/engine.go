@@ -0,0 +1,379 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// EventType - what an Event reports.
+type EventType int
+
+// Events of a Copy or Mirror, in the order they are sent for an object.
+const (
+	// EventScanned - a transfer of Source was prepared.
+	EventScanned EventType = iota
+	// EventScanFailed - a source could not be prepared, Err tells why. Others still are.
+	EventScanFailed
+	// EventScanDone - all transfers are prepared, Size is their total. Sent by resumed runs
+	// too, which prepare none.
+	EventScanDone
+	// EventSkipped - Source was transferred by an earlier run of the session.
+	EventSkipped
+	// EventStarted - transfer of Source to Targets started.
+	EventStarted
+	// EventProgress - Bytes more of Source were read.
+	EventProgress
+	// EventRetrying - an attempt failed with a transient Err, the Bytes it read are read
+	// again by the next one.
+	EventRetrying
+	// EventFinished - Source was transferred to all its Targets.
+	EventFinished
+	// EventFailed - transfer of Source failed with Err, Bytes were read by its last attempt.
+	EventFailed
+)
+
+// Event - progress of a Copy or Mirror, see EventType for the fields set.
+type Event struct {
+	Type    EventType
+	Source  string
+	Targets []string
+	// Size of Source.
+	Size  int64
+	Bytes int64
+	Err   *probe.Error
+}
+
+// Copy copies sources to target, arguments follow the rules of ‘mc cp’. Transfers are
+// prepared first and stored to session, a session storing transfers already is resumed.
+// Session may be nil for runs which are not to be resumed. Events, if not nil, are sent
+// to events until Copy returns, which closes it. It has to be read promptly, transfers
+// wait on it.
+//
+// A failed object does not stop the others, Copy returns TransfersFailed if any failed.
+// Once opts.Stop is closed Copy returns as soon as transfers in progress are finished,
+// once ctx is done they are aborted and Copy returns OperationTimeout or context.Canceled.
+// A session stopped before EventScanDone stores part of the transfers only, it is to be
+// discarded rather than resumed.
+func Copy(ctx context.Context, opts Options, session Session, sourceURLs []string, targetURL string, events chan<- Event) *probe.Error {
+	scan := func(ctx context.Context, preparedCh chan<- prepared) {
+		defer close(preparedCh)
+		for cpURLs := range PrepareCopyURLs(ctx, opts, sourceURLs, targetURL) {
+			preparedCh <- prepared{urls: cpURLs, source: cpURLs.SourceContent, err: cpURLs.Error}
+		}
+	}
+	parse := func(line []byte) (job, bool) {
+		var cpURLs CopyURLs
+		if e := json.Unmarshal(line, &cpURLs); e != nil || cpURLs.SourceContent == nil || cpURLs.TargetContent == nil {
+			return job{}, false
+		}
+		return job{source: cpURLs.SourceContent.Name, targets: []string{cpURLs.TargetContent.Name}, size: cpURLs.SourceContent.Size}, true
+	}
+	return run(ctx, opts, session, events, scan, parse)
+}
+
+// Mirror mirrors source folder to target folders, arguments follow the rules of ‘mc mirror’.
+// Only objects missing from a target, or differing in size, are transferred to it. See
+// Copy for how transfers are run and reported.
+func Mirror(ctx context.Context, opts Options, session Session, sourceURL string, targetURLs []string, events chan<- Event) *probe.Error {
+	scan := func(ctx context.Context, preparedCh chan<- prepared) {
+		defer close(preparedCh)
+		for sURLs := range PrepareMirrorURLs(ctx, opts, sourceURL, targetURLs) {
+			if sURLs.Error == nil && sURLs.IsEmpty() {
+				continue
+			}
+			preparedCh <- prepared{urls: sURLs, source: sURLs.SourceContent, err: sURLs.Error}
+		}
+	}
+	parse := func(line []byte) (job, bool) {
+		var sURLs MirrorURLs
+		if e := json.Unmarshal(line, &sURLs); e != nil || sURLs.SourceContent == nil {
+			return job{}, false
+		}
+		j := job{source: sURLs.SourceContent.Name, size: sURLs.SourceContent.Size}
+		for _, targetContent := range sURLs.TargetContents {
+			j.targets = append(j.targets, targetContent.Name)
+		}
+		return j, true
+	}
+	return run(ctx, opts, session, events, scan, parse)
+}
+
+// prepared - a transfer to store, CopyURLs or MirrorURLs, or the error met preparing it.
+type prepared struct {
+	urls   interface{}
+	source *client.Content
+	err    *probe.Error
+}
+
+// job - a transfer read back from session data.
+type job struct {
+	source  string
+	targets []string
+	size    int64
+}
+
+// run prepares transfers with scan unless session stores them already, then runs the
+// stored transfers, which parse reads back.
+func run(ctx context.Context, opts Options, session Session, events chan<- Event, scan func(ctx context.Context, preparedCh chan<- prepared), parse func(line []byte) (job, bool)) *probe.Error {
+	if events != nil {
+		defer close(events)
+	}
+	if session == nil {
+		session = &memorySession{}
+	}
+	var failed int
+	if !session.HasData() {
+		n, stopped, err := prepare(ctx, opts, session, events, scan)
+		if err != nil {
+			return err.Trace()
+		}
+		if ctx.Err() != nil {
+			return probe.NewError(client.ContextError(ctx, ctx.Err()))
+		}
+		if stopped {
+			return nil
+		}
+		failed += n
+	}
+	emit(ctx, events, Event{Type: EventScanDone, Size: session.Progress().TotalBytes})
+
+	n, err := transferAll(ctx, opts, session, events, parse)
+	if ctx.Err() != nil {
+		return probe.NewError(client.ContextError(ctx, ctx.Err()))
+	}
+	if err != nil {
+		return err.Trace()
+	}
+	if failed += n; failed > 0 {
+		return probe.NewError(TransfersFailed{Count: failed})
+	}
+	return nil
+}
+
+// prepare stores the transfers told by scan to session, along with their totals. It returns
+// the number of sources which could not be prepared, and whether opts.Stop interrupted it.
+func prepare(ctx context.Context, opts Options, session Session, events chan<- Event, scan func(ctx context.Context, preparedCh chan<- prepared)) (failed int, stopped bool, err *probe.Error) {
+	scanCtx, cancel := context.WithCancel(ctx)
+	preparedCh := make(chan prepared)
+	go scan(scanCtx, preparedCh)
+	defer func() {
+		// Preparation ends soon once cancelled, do not leave it blocked meanwhile.
+		cancel()
+		go func() {
+			for range preparedCh {
+			}
+		}()
+	}()
+
+	writer := session.NewDataWriter()
+	progress := session.Progress()
+	progress.TotalBytes, progress.TotalObjects = 0, 0
+	for {
+		var p prepared
+		var ok bool
+		select {
+		case p, ok = <-preparedCh:
+		case <-opts.Stop:
+			return failed, true, nil
+		}
+		if !ok {
+			break
+		}
+		if p.err != nil {
+			failed++
+			emit(ctx, events, Event{Type: EventScanFailed, Err: p.err.Trace()})
+			continue
+		}
+		data, e := json.Marshal(p.urls)
+		if e != nil {
+			return failed, false, probe.NewError(e)
+		}
+		if _, e = fmt.Fprintln(writer, string(data)); e != nil {
+			return failed, false, probe.NewError(e)
+		}
+		emit(ctx, events, Event{Type: EventScanned, Source: p.source.Name, Size: p.source.Size})
+		progress.TotalBytes += p.source.Size
+		progress.TotalObjects++
+	}
+	if err = session.SaveProgress(progress); err != nil {
+		return failed, false, err.Trace()
+	}
+	return failed, false, nil
+}
+
+// transferAll runs the transfers stored in session in parallel, skipping those up to the
+// last one finished by an earlier run. It returns the number of failed transfers.
+func transferAll(ctx context.Context, opts Options, session Session, events chan<- Event, parse func(line []byte) (job, bool)) (failed int, err *probe.Error) {
+	progress := session.Progress()
+	parallelN := opts.Parallel
+	if progress.Parallel > 0 {
+		parallelN = progress.Parallel
+	}
+	parallel := newParallelManager(parallelN, opts.Adaptive)
+	defer parallel.Close()
+	limits := newLimiters(opts.Limits)
+	// isCopied returns true if an object has been already copied
+	// or not. This is useful when we resume from a session.
+	isCopied := isCopiedFactory(progress.LastCopied)
+
+	// Status of finished transfers, recorded by one routine since sessions are not safe
+	// for concurrent use.
+	type status struct {
+		job job
+		err *probe.Error
+	}
+	statusCh := make(chan status)
+	var saveErr *probe.Error
+	recorded := make(chan struct{})
+	go func() {
+		defer close(recorded)
+		for st := range statusCh {
+			// Record the number of routines in use, so that a resumed session continues with it.
+			parallel.Done(st.job.size, st.err != nil)
+			progress.Parallel = parallel.Workers()
+			if st.err != nil {
+				failed++
+				continue
+			}
+			progress.LastCopied = st.job.source
+			if err := session.SaveProgress(progress); err != nil && saveErr == nil {
+				saveErr = err.Trace(st.job.source)
+			}
+		}
+	}()
+
+	wg := new(sync.WaitGroup)
+	scanner := bufio.NewScanner(session.NewDataReader())
+scanLoop:
+	for scanner.Scan() {
+		j, ok := parse(scanner.Bytes())
+		if !ok {
+			err = probe.NewError(InvalidSessionData{Line: scanner.Text()})
+			break
+		}
+		if isCopied(j.source) {
+			emit(ctx, events, Event{Type: EventSkipped, Source: j.source, Targets: j.targets, Size: j.size})
+			continue
+		}
+		// Wait for other transfer routines to
+		// complete. We only have limited CPU
+		// and network resources.
+		select {
+		case parallel.queueCh <- true:
+		case <-opts.Stop: // Do not start any new transfer routines.
+			break scanLoop
+		case <-ctx.Done():
+			break scanLoop
+		}
+		wg.Add(1)
+		go func(j job) {
+			defer wg.Done()
+			defer func() {
+				<-parallel.queueCh
+			}()
+			statusCh <- status{j, transferJob(ctx, opts, limits, j, events)}
+		}(j)
+	}
+	if e := scanner.Err(); e != nil && err == nil {
+		err = probe.NewError(e)
+	}
+	wg.Wait()
+	close(statusCh)
+	<-recorded
+	if err == nil {
+		err = saveErr
+	}
+	return failed, err
+}
+
+// emit sends event, unless events is nil or ctx is done.
+func emit(ctx context.Context, events chan<- Event, event Event) {
+	if events == nil {
+		return
+	}
+	select {
+	case events <- event:
+	case <-ctx.Done():
+	}
+}
+
+// transferJob transfers an object to its targets, retrying transient failures.
+func transferJob(ctx context.Context, opts Options, limits *limiters, j job, events chan<- Event) *probe.Error {
+	event := func(eventType EventType, bytes int64, err *probe.Error) Event {
+		return Event{Type: eventType, Source: j.source, Targets: j.targets, Size: j.size, Bytes: bytes, Err: err}
+	}
+	emit(ctx, events, event(EventStarted, 0, nil))
+
+	throttle := limits.throttle(j.source, j.targets...)
+	// Bytes read by the current attempt, read again by the next one.
+	var read int64
+	progress := func(n int) {
+		throttle(n)
+		atomic.AddInt64(&read, int64(n))
+		emit(ctx, events, event(EventProgress, int64(n), nil))
+	}
+
+	err := Retry(ctx, opts.Retries, func() *probe.Error {
+		return transferObject(ctx, opts, j.source, j.targets, progress)
+	}, func(err *probe.Error) {
+		emit(ctx, events, event(EventRetrying, atomic.SwapInt64(&read, 0), err))
+	})
+	if err != nil {
+		emit(ctx, events, event(EventFailed, atomic.SwapInt64(&read, 0), err))
+		return err.Trace(j.source)
+	}
+	emit(ctx, events, event(EventFinished, 0, nil))
+	return nil
+}
+
+// progressReader calls progress with the number of bytes read.
+type progressReader struct {
+	reader   io.Reader
+	progress func(n int)
+}
+
+func (r progressReader) Read(p []byte) (int, error) {
+	n, e := r.reader.Read(p)
+	if n > 0 {
+		r.progress(n)
+	}
+	return n, e
+}
+
+// transferObject makes a single attempt at transferring source to targets.
+func transferObject(ctx context.Context, opts Options, sourceURL string, targetURLs []string, progress func(n int)) *probe.Error {
+	sourceClnt, err := opts.NewClient(sourceURL)
+	if err != nil {
+		return err.Trace(sourceURL)
+	}
+	reader, length, err := sourceClnt.Get(ctx, 0, 0)
+	if err != nil {
+		return err.Trace(sourceURL)
+	}
+	defer reader.Close()
+	return PutTargets(ctx, opts, targetURLs, length, progressReader{reader: reader, progress: progress})
+}
@@ -0,0 +1,99 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import "strconv"
+
+/// Collection of errors reported while preparing and running transfers
+
+// InvalidArgument - source or target arguments are missing or empty.
+type InvalidArgument struct{}
+
+func (e InvalidArgument) Error() string {
+	return "Invalid arguments provided, cannot proceed."
+}
+
+// SourceListEmpty - no source was given.
+type SourceListEmpty struct{}
+
+func (e SourceListEmpty) Error() string {
+	return "Source argument list is empty."
+}
+
+// InvalidSource - source is neither a file nor a folder.
+type InvalidSource struct {
+	URL string
+}
+
+func (e InvalidSource) Error() string {
+	return "Invalid source ‘" + e.URL + "’."
+}
+
+// InvalidTarget - target is not a folder.
+type InvalidTarget struct {
+	URL string
+}
+
+func (e InvalidTarget) Error() string {
+	return "Invalid target ‘" + e.URL + "’."
+}
+
+// SourceNotRecursive - source was expected to end with ‘...’.
+type SourceNotRecursive struct {
+	URL string
+}
+
+func (e SourceNotRecursive) Error() string {
+	return "Source ‘" + e.URL + "’ is not recursive."
+}
+
+// SourceIsNotDir - recursive source is not a folder.
+type SourceIsNotDir struct {
+	URL string
+}
+
+func (e SourceIsNotDir) Error() string {
+	return "Source ‘" + e.URL + "’ is not a folder."
+}
+
+// SourceIsDir - source is a folder, but was not given as recursive.
+type SourceIsDir struct {
+	URL string
+}
+
+func (e SourceIsDir) Error() string {
+	return "Source ‘" + e.URL + "’ is a folder."
+}
+
+// InvalidSessionData - a transfer stored in a session cannot be read back.
+type InvalidSessionData struct {
+	Line string
+}
+
+func (e InvalidSessionData) Error() string {
+	return "Invalid transfer ‘" + e.Line + "’ in session data."
+}
+
+// TransfersFailed - some sources could not be prepared or transferred, each was reported
+// by an Event.
+type TransfersFailed struct {
+	Count int
+}
+
+func (e TransfersFailed) Error() string {
+	return strconv.Itoa(e.Count) + " transfers failed."
+}
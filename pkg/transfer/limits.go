@@ -0,0 +1,114 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+)
+
+// Limits - upload and download bandwidth shared by all transfers of a Copy or Mirror.
+type Limits struct {
+	// Upload and Download return the rates allowed at a time in bytes per second, 0 stands
+	// for unlimited. Either may be nil for unlimited.
+	Upload   func(now time.Time) int64
+	Download func(now time.Time) int64
+}
+
+// rateLimiter is a token bucket shared by all concurrent transfers. Bucket
+// holds at most a second worth of bytes.
+type rateLimiter struct {
+	mutex  sync.Mutex
+	rate   func(now time.Time) int64 // bytes per second, 0 stands for unlimited.
+	tokens float64
+	last   time.Time
+}
+
+// Wait takes n bytes worth of tokens from the bucket, sleeping off any shortage.
+func (l *rateLimiter) Wait(n int) {
+	l.mutex.Lock()
+	now := time.Now()
+	rate := l.rate(now)
+	if rate <= 0 {
+		l.tokens = 0
+		l.last = now
+		l.mutex.Unlock()
+		return
+	}
+	if l.last.IsZero() {
+		// Start with a full bucket.
+		l.tokens = float64(rate)
+	} else {
+		l.tokens += now.Sub(l.last).Seconds() * float64(rate)
+	}
+	if l.tokens > float64(rate) {
+		l.tokens = float64(rate)
+	}
+	l.last = now
+	// Go into debt, concurrent callers queue up behind it.
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / float64(rate) * float64(time.Second))
+	}
+	l.mutex.Unlock()
+	time.Sleep(delay)
+}
+
+// limiters - rate limiters of Limits, shared by all transfers of a run.
+type limiters struct {
+	upload   *rateLimiter
+	download *rateLimiter
+}
+
+// newLimiters - instantiate limiters for l, nil stands for unlimited.
+func newLimiters(l *Limits) *limiters {
+	unlimited := func(time.Time) int64 { return 0 }
+	b := &limiters{upload: &rateLimiter{rate: unlimited}, download: &rateLimiter{rate: unlimited}}
+	if l == nil {
+		return b
+	}
+	if l.Upload != nil {
+		b.upload.rate = l.Upload
+	}
+	if l.Download != nil {
+		b.download.rate = l.Download
+	}
+	return b
+}
+
+// throttle returns a function which throttles bytes read from sourceURL and written to
+// targetURLs. Downloads count against remote sources, uploads against every remote target.
+func (b *limiters) throttle(sourceURL string, targetURLs ...string) func(n int) {
+	download := client.NewURL(sourceURL).Type == client.Object
+	uploads := 0
+	for _, targetURL := range targetURLs {
+		if client.NewURL(targetURL).Type == client.Object {
+			uploads++
+		}
+	}
+	return func(n int) {
+		if download {
+			b.download.Wait(n)
+		}
+		if uploads > 0 {
+			b.upload.Wait(n * uploads)
+		}
+	}
+}
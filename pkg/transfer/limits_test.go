@@ -0,0 +1,54 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestRateLimiter(c *C) {
+	limiter := &rateLimiter{rate: func(time.Time) int64 { return 100 * 1024 }}
+	start := time.Now()
+	// First 100KiB fill the bucket, the next 50KiB have to wait for half a second.
+	limiter.Wait(100 * 1024)
+	limiter.Wait(50 * 1024)
+	elapsed := time.Since(start)
+	c.Assert(elapsed >= 400*time.Millisecond, Equals, true)
+	c.Assert(elapsed < 2*time.Second, Equals, true)
+
+	unlimited := &rateLimiter{rate: func(time.Time) int64 { return 0 }}
+	start = time.Now()
+	unlimited.Wait(1024 * 1024 * 1024)
+	c.Assert(time.Since(start) < 100*time.Millisecond, Equals, true)
+}
+
+func (s *MySuite) TestThrottle(c *C) {
+	var uploaded, downloaded int
+	b := newLimiters(nil)
+	b.upload.rate = func(time.Time) int64 { uploaded++; return 0 }
+	b.download.rate = func(time.Time) int64 { downloaded++; return 0 }
+
+	// Downloads count against remote sources, uploads against every remote target.
+	b.throttle("https://s3.amazonaws.com/bucket/object", "/tmp/object")(1)
+	c.Assert(downloaded, Equals, 1)
+	c.Assert(uploaded, Equals, 0)
+	b.throttle("/tmp/object", "https://s3.amazonaws.com/bucket/object", "/tmp/copy")(1)
+	c.Assert(downloaded, Equals, 1)
+	c.Assert(uploaded, Equals, 1)
+}
@@ -0,0 +1,176 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"context"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// MirrorURLs - a source object and the targets it is missing from.
+type MirrorURLs struct {
+	SourceContent  *client.Content
+	TargetContents []*client.Content
+	Error          *probe.Error `json:"-"`
+}
+
+// IsEmpty - true if there is nothing to mirror.
+func (m MirrorURLs) IsEmpty() bool {
+	if m.SourceContent == nil && len(m.TargetContents) == 0 && m.Error == nil {
+		return true
+	}
+	if m.SourceContent.Size == 0 && len(m.TargetContents) == 0 && m.Error == nil {
+		return true
+	}
+	return false
+}
+
+//
+//   * MIRROR ARGS - VALID CASES
+//   =========================
+//   mirror(d1..., [](d2)) -> []mirror(d1/f, [](d2/d1/f))
+
+func getContent(ch <-chan client.ContentOnChannel) (c *client.Content) {
+	for rv := range ch {
+		if rv.Err != nil {
+			continue
+		}
+		if rv.Content.Type.IsDir() {
+			// ignore directories
+			continue
+		}
+
+		c = rv.Content
+		break
+	}
+
+	return
+}
+
+func getTargetContent(srcContent *client.Content, targetContent *client.Content, targetCh <-chan client.ContentOnChannel) (c *client.Content) {
+	if srcContent == nil {
+		// nothing to do for empty source content
+		return
+	}
+
+	if targetContent == nil {
+		c = getContent(targetCh)
+	} else {
+		c = targetContent
+	}
+
+	for ; c != nil; c = getContent(targetCh) {
+		if srcContent.Name <= c.Name {
+			break
+		}
+	}
+
+	return
+}
+
+func deltaSourceTargets(ctx context.Context, opts Options, sourceURL string, targetURLs []string, mirrorURLsCh chan<- MirrorURLs) {
+	defer close(mirrorURLsCh)
+
+	newSourceURL := StripRecursiveURL(sourceURL)
+	if strings.HasSuffix(newSourceURL, "/") == false {
+		newSourceURL = newSourceURL + "/"
+	}
+	sourceClient, err := opts.NewClient(newSourceURL)
+	if err != nil {
+		mirrorURLsCh <- MirrorURLs{Error: err.Trace(sourceURL)}
+		return
+	}
+
+	targetLen := len(targetURLs)
+	newTargetURLs := make([]string, targetLen)
+	targetClients := make([]client.Client, targetLen)
+	for i, targetURL := range targetURLs {
+		targetClient, targetContent, err := opts.stat(ctx, targetURL)
+		if err != nil {
+			mirrorURLsCh <- MirrorURLs{Error: err.Trace(targetURL)}
+			return
+		}
+		// targets have to be directory
+		if !targetContent.Type.IsDir() {
+			mirrorURLsCh <- MirrorURLs{Error: probe.NewError(InvalidTarget{URL: targetURL})}
+			return
+		}
+		// special case, be extremely careful before changing this behavior - will lead to data loss
+		newTargetURL := strings.TrimSuffix(targetURL, string(targetClient.URL().Separator)) + string(targetClient.URL().Separator)
+		targetClient, err = opts.NewClient(newTargetURL)
+		if err != nil {
+			mirrorURLsCh <- MirrorURLs{Error: err.Trace(newTargetURL)}
+			return
+		}
+		targetClients[i] = targetClient
+		newTargetURLs[i] = newTargetURL
+	}
+
+	// Target listings are not read to their end once source listing is done.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	targetChs := make([]<-chan client.ContentOnChannel, targetLen)
+	for i, targetClient := range targetClients {
+		targetChs[i] = targetClient.List(ctx, true, false)
+	}
+	targetContents := make([]*client.Content, targetLen)
+
+	srcCh := sourceClient.List(ctx, true, false)
+	for srcContent := getContent(srcCh); srcContent != nil; srcContent = getContent(srcCh) {
+		var mirrorTargets []*client.Content
+		for i := range targetChs {
+			targetContents[i] = getTargetContent(srcContent, targetContents[i], targetChs[i])
+
+			// either target reached EOF or target does not have source content
+			if targetContents[i] == nil || srcContent.Name != targetContents[i].Name {
+				mirrorTargets = append(mirrorTargets, &client.Content{Name: newTargetURLs[i] + srcContent.Name})
+				continue
+			}
+
+			// source and target have same content
+			if srcContent.Type.IsRegular() && targetContents[i].Type.IsRegular() {
+				// but size mismatches
+				if srcContent.Size != targetContents[i].Size {
+					mirrorTargets = append(mirrorTargets, &client.Content{Name: newTargetURLs[i] + srcContent.Name})
+				}
+				continue
+			}
+
+			// source and target have different content type
+			// TODO: add error
+		}
+		if len(mirrorTargets) > 0 {
+			srcContent.Name = newSourceURL + srcContent.Name
+			mirrorURLsCh <- MirrorURLs{
+				SourceContent:  srcContent,
+				TargetContents: mirrorTargets,
+			}
+		}
+	}
+}
+
+// PrepareMirrorURLs - prepares source objects missing from, or differing in size
+// on, any of the targets. Channel has to be read to its end.
+func PrepareMirrorURLs(ctx context.Context, opts Options, sourceURL string, targetURLs []string) <-chan MirrorURLs {
+	mirrorURLsCh := make(chan MirrorURLs)
+	go deltaSourceTargets(ctx, opts, sourceURL, targetURLs, mirrorURLsCh)
+	return mirrorURLsCh
+}
@@ -0,0 +1,184 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// parallel transfer related constants.
+const (
+	// MaxParallel - upper bound on concurrent transfers, also the queue capacity in adaptive mode.
+	MaxParallel = 256
+
+	// interval at which adaptive mode re-evaluates number of transfers.
+	parallelAdaptInterval = 5 * time.Second
+
+	// adaptive mode halves the number of transfers above this error rate.
+	parallelMaxErrorRate = 0.1
+
+	// bytes a finished object is worth while measuring throughput, small
+	// objects are bound by request latency and not by bandwidth.
+	parallelObjectWeight = 64 * 1024
+)
+
+// DefaultParallel - number of transfers when none is configured, based on available CPU resources.
+func DefaultParallel() int {
+	return int(math.Max(float64(runtime.NumCPU())-1, 1))
+}
+
+// parallelManager bounds the number of concurrent transfers. Every
+// transfer occupies a slot in queueCh, in adaptive mode the manager
+// occupies the slots which are not to be used.
+type parallelManager struct {
+	queueCh  chan bool
+	workers  int32 // current number of allowed transfers, read atomically.
+	reserved int   // slots in queueCh held by the manager.
+
+	// transfer statistics since the last adaptation.
+	bytes   int64
+	objects int64
+	errors  int64
+
+	stopCh    chan struct{}
+	closeOnce sync.Once
+}
+
+// newParallelManager - instantiate a parallelManager for parallel transfers, and adapt it if requested.
+func newParallelManager(parallel int, adaptive bool) *parallelManager {
+	if parallel < 1 {
+		parallel = DefaultParallel()
+	}
+	if parallel > MaxParallel {
+		parallel = MaxParallel
+	}
+	if !adaptive {
+		return &parallelManager{
+			queueCh: make(chan bool, parallel),
+			workers: int32(parallel),
+			stopCh:  make(chan struct{}),
+		}
+	}
+	p := &parallelManager{
+		queueCh:  make(chan bool, MaxParallel),
+		workers:  int32(parallel),
+		reserved: MaxParallel - parallel,
+		stopCh:   make(chan struct{}),
+	}
+	for i := 0; i < p.reserved; i++ {
+		p.queueCh <- true
+	}
+	go p.adapter()
+	return p
+}
+
+// Workers returns the current number of allowed transfers.
+func (p *parallelManager) Workers() int {
+	return int(atomic.LoadInt32(&p.workers))
+}
+
+// Done accounts for a finished transfer.
+func (p *parallelManager) Done(size int64, failed bool) {
+	if failed {
+		atomic.AddInt64(&p.errors, 1)
+		return
+	}
+	atomic.AddInt64(&p.bytes, size)
+	atomic.AddInt64(&p.objects, 1)
+}
+
+// Close stops adapting the number of transfers.
+func (p *parallelManager) Close() {
+	p.closeOnce.Do(func() {
+		close(p.stopCh)
+	})
+}
+
+// resize grows or shrinks allowed transfers to n, shrinking waits for transfers in progress to finish.
+func (p *parallelManager) resize(n int) {
+	for p.Workers() < n && p.reserved > 0 {
+		select {
+		case <-p.queueCh:
+		case <-p.stopCh:
+			return
+		}
+		p.reserved--
+		atomic.AddInt32(&p.workers, 1)
+	}
+	for p.Workers() > n && p.Workers() > 1 {
+		select {
+		case p.queueCh <- true:
+		case <-p.stopCh:
+			return
+		}
+		p.reserved++
+		atomic.AddInt32(&p.workers, -1)
+	}
+}
+
+// adapter climbs towards the number of transfers with the best throughput.
+// It keeps stepping in one direction while throughput improves, reverses
+// when it drops and halves the transfers when too many of them fail.
+func (p *parallelManager) adapter() {
+	var lastThroughput float64
+	direction := 1
+	ticker := time.NewTicker(parallelAdaptInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+		}
+		bytes := atomic.SwapInt64(&p.bytes, 0)
+		objects := atomic.SwapInt64(&p.objects, 0)
+		errors := atomic.SwapInt64(&p.errors, 0)
+		if objects+errors == 0 {
+			// Nothing finished, large objects are still in progress.
+			continue
+		}
+		workers := p.Workers()
+		if float64(errors)/float64(objects+errors) > parallelMaxErrorRate {
+			// Back off hard, then probe upwards again from there.
+			direction = 1
+			lastThroughput = 0
+			p.resize(workers / 2)
+			continue
+		}
+		throughput := float64(bytes) + float64(objects)*parallelObjectWeight
+		switch {
+		case throughput > lastThroughput*1.05:
+			// keep going.
+		case throughput < lastThroughput*0.95:
+			direction = -direction
+		default:
+			// no significant change, stay put.
+			lastThroughput = throughput
+			continue
+		}
+		lastThroughput = throughput
+		step := workers / 4
+		if step < 1 {
+			step = 1
+		}
+		p.resize(workers + direction*step)
+	}
+}
@@ -0,0 +1,43 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import . "gopkg.in/check.v1"
+
+func (s *MySuite) TestParallelManager(c *C) {
+	p := newParallelManager(4, false)
+	defer p.Close()
+	c.Assert(cap(p.queueCh), Equals, 4)
+	c.Assert(p.Workers(), Equals, 4)
+
+	p = newParallelManager(4, true)
+	defer p.Close()
+	c.Assert(p.Workers(), Equals, 4)
+	c.Assert(len(p.queueCh), Equals, MaxParallel-4)
+
+	p.resize(8)
+	c.Assert(p.Workers(), Equals, 8)
+	c.Assert(len(p.queueCh), Equals, MaxParallel-8)
+
+	p.resize(2)
+	c.Assert(p.Workers(), Equals, 2)
+	c.Assert(len(p.queueCh), Equals, MaxParallel-2)
+
+	// Never shrinks below one transfer.
+	p.resize(0)
+	c.Assert(p.Workers(), Equals, 1)
+}
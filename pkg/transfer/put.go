@@ -0,0 +1,109 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// PutTargets writes to URLs from reader. If length=0, read until EOF.
+func PutTargets(ctx context.Context, opts Options, targetURLs []string, length int64, reader io.Reader) *probe.Error {
+	if len(targetURLs) == 1 {
+		targetClnt, err := opts.NewClient(targetURLs[0])
+		if err != nil {
+			return err.Trace(targetURLs[0])
+		}
+		if err = targetClnt.Put(ctx, length, reader); err != nil {
+			return err.Trace(targetURLs[0])
+		}
+		return nil
+	}
+
+	var tgtReaders []*io.PipeReader
+	var tgtWriters []*io.PipeWriter
+	var tgtClients []client.Client
+
+	for _, targetURL := range targetURLs {
+		tgtClient, err := opts.NewClient(targetURL)
+		if err != nil {
+			return err.Trace(targetURL)
+		}
+		tgtClients = append(tgtClients, tgtClient)
+		tgtReader, tgtWriter := io.Pipe()
+		tgtReaders = append(tgtReaders, tgtReader)
+		tgtWriters = append(tgtWriters, tgtWriter)
+	}
+
+	go func() {
+		var writers []io.Writer
+		for _, tgtWriter := range tgtWriters {
+			writers = append(writers, io.Writer(tgtWriter))
+		}
+
+		multiTgtWriter := io.MultiWriter(writers...)
+		var e error
+		switch length {
+		case 0:
+			_, e = io.Copy(multiTgtWriter, reader)
+		default:
+			_, e = io.CopyN(multiTgtWriter, reader, length)
+		}
+		for _, tgtWriter := range tgtWriters {
+			if e != nil {
+				tgtWriter.CloseWithError(e)
+			}
+			tgtWriter.Close()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	errorCh := make(chan *probe.Error, len(tgtClients))
+
+	func() { // Parallel putObject
+		defer close(errorCh) // Each routine gets to return one err status.
+		for i := range tgtClients {
+			wg.Add(1)
+			// make local copy for go routine
+			tgtClient := tgtClients[i]
+			tgtReader := tgtReaders[i]
+
+			go func(targetClient client.Client, reader io.ReadCloser, errorCh chan<- *probe.Error) {
+				defer wg.Done()
+				defer reader.Close()
+				err := targetClient.Put(ctx, length, reader)
+				if err != nil {
+					errorCh <- err.Trace()
+					return
+				}
+			}(tgtClient, tgtReader, errorCh)
+		}
+		wg.Wait()
+	}()
+
+	// Return on first error encounter.
+	err := <-errorCh
+	if err != nil {
+		return err.Trace()
+	}
+
+	return nil // success.
+}
@@ -0,0 +1,119 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// retry related constants.
+const (
+	// DefaultRetryLimit - default number of times a failed transfer is retried
+	DefaultRetryLimit = 5
+
+	// backoff is doubled for every attempt, starting from these values
+	retryUnitDelay     = time.Second
+	slowDownUnitDelay  = 5 * time.Second
+	retryMaxDelay      = 2 * time.Minute
+	retryMaxAttemptExp = 16
+)
+
+// retryRand is a private random source for jitter, callers may reseed math/rand's default source.
+var retryRand = struct {
+	*rand.Rand
+	sync.Mutex
+}{Rand: rand.New(rand.NewSource(time.Now().UTC().UnixNano()))}
+
+// IsRetryable returns true for transient errors, which are worth retrying
+// before giving up and saving the session.
+func IsRetryable(err *probe.Error) bool {
+	if err == nil {
+		return false
+	}
+	e := err.ToGoError()
+	switch e.(type) {
+	case client.SlowDown, client.InternalError, client.RequestTimeout, client.OperationTimeout, client.CredentialsExpired:
+		return true
+	}
+	// Connections reset by a peer or a proxy in between are worth another attempt.
+	if errors.Is(e, syscall.ECONNRESET) || errors.Is(e, syscall.ECONNABORTED) {
+		return true
+	}
+	// Network errors which are permanent, e.g. unknown hosts or refused
+	// connections, fail the same way every time.
+	if netErr, ok := e.(net.Error); ok {
+		return netErr.Timeout() || netErr.Temporary()
+	}
+	return false
+}
+
+// RetryDelay returns exponential backoff with jitter for a given attempt, starting with 0.
+// Servers asking us to slow down get a larger unit delay.
+func RetryDelay(attempt int, err *probe.Error) time.Duration {
+	unit := retryUnitDelay
+	if err != nil {
+		if _, ok := err.ToGoError().(client.SlowDown); ok {
+			unit = slowDownUnitDelay
+		}
+	}
+	if attempt > retryMaxAttemptExp {
+		attempt = retryMaxAttemptExp
+	}
+	delay := unit * time.Duration(1<<uint(attempt))
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	// Sleep for anywhere between half and full of the computed
+	// delay, so that concurrent transfers do not retry in lockstep.
+	retryRand.Lock()
+	jitter := time.Duration(retryRand.Int63n(int64(delay/2) + 1))
+	retryRand.Unlock()
+	return delay/2 + jitter
+}
+
+// Retry calls transfer until it succeeds, fails with an error which is not
+// transient, maxRetries is exhausted or ctx is done. onRetry is invoked before
+// sleeping, callers reporting progress have to roll back what the failed
+// attempt reported there, since the next attempt starts over.
+func Retry(ctx context.Context, maxRetries int, transfer func() *probe.Error, onRetry func(err *probe.Error)) *probe.Error {
+	for attempt := 0; ; attempt++ {
+		err := transfer()
+		if err == nil {
+			return nil
+		}
+		if attempt >= maxRetries || !IsRetryable(err) {
+			return err.Trace()
+		}
+		if onRetry != nil {
+			onRetry(err)
+		}
+		select {
+		case <-time.After(RetryDelay(attempt, err)):
+		case <-ctx.Done():
+			return probe.NewError(client.ContextError(ctx, ctx.Err()))
+		}
+	}
+}
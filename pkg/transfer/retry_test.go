@@ -0,0 +1,86 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"syscall"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestRetryable(c *C) {
+	c.Assert(IsRetryable(nil), Equals, false)
+	c.Assert(IsRetryable(probe.NewError(errors.New("Access Denied"))), Equals, false)
+	c.Assert(IsRetryable(probe.NewError(client.ObjectNotFound{})), Equals, false)
+	c.Assert(IsRetryable(probe.NewError(&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)})), Equals, true)
+	c.Assert(IsRetryable(probe.NewError(&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)})), Equals, false)
+	c.Assert(IsRetryable(probe.NewError(&net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true})), Equals, false)
+	c.Assert(IsRetryable(probe.NewError(&net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true})), Equals, true)
+	c.Assert(IsRetryable(probe.NewError(client.SlowDown{Code: "SlowDown"})), Equals, true)
+	c.Assert(IsRetryable(probe.NewError(client.InternalError{Code: "InternalError"})), Equals, true)
+	c.Assert(IsRetryable(probe.NewError(client.RequestTimeout{})), Equals, true)
+	c.Assert(IsRetryable(probe.NewError(client.OperationTimeout{})), Equals, true)
+	c.Assert(IsRetryable(probe.NewError(client.CredentialsExpired{Code: "ExpiredToken"})), Equals, true)
+	c.Assert(IsRetryable(probe.NewError(context.Canceled)), Equals, false)
+}
+
+func (s *MySuite) TestRetryDelay(c *C) {
+	for attempt := 0; attempt < 64; attempt++ {
+		delay := RetryDelay(attempt, nil)
+		c.Assert(delay > 0, Equals, true)
+		c.Assert(delay <= retryMaxDelay, Equals, true)
+	}
+	// Servers asking us to slow down back off longer.
+	c.Assert(RetryDelay(0, probe.NewError(client.SlowDown{})) >= slowDownUnitDelay/2, Equals, true)
+}
+
+func (s *MySuite) TestRetryTransfer(c *C) {
+	attempts := 0
+	perr := Retry(context.Background(), 2, func() *probe.Error {
+		attempts++
+		return probe.NewError(errors.New("Access Denied"))
+	}, nil)
+	c.Assert(perr, Not(IsNil))
+	c.Assert(attempts, Equals, 1)
+
+	attempts = 0
+	perr = Retry(context.Background(), 0, func() *probe.Error {
+		attempts++
+		return probe.NewError(client.RequestTimeout{})
+	}, nil)
+	c.Assert(perr, Not(IsNil))
+	c.Assert(attempts, Equals, 1)
+
+	attempts = 0
+	retried := 0
+	perr = Retry(context.Background(), 3, func() *probe.Error {
+		attempts++
+		if attempts < 2 {
+			return probe.NewError(client.RequestTimeout{})
+		}
+		return nil
+	}, func(*probe.Error) { retried++ })
+	c.Assert(perr, IsNil)
+	c.Assert(attempts, Equals, 2)
+	c.Assert(retried, Equals, 1)
+}
@@ -0,0 +1,97 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Session - storage of a Copy or Mirror, such as the sessions of ‘mc’, so that it can be
+// resumed once interrupted. Transfers are prepared once and stored as data, one JSON
+// encoded CopyURLs or MirrorURLs per line. A resumed run skips the stored transfers up to
+// the last one finished. Methods are not called concurrently.
+type Session interface {
+	// HasData reports whether transfers were prepared and stored by an earlier run.
+	HasData() bool
+	// NewDataReader returns the stored transfers, from the first one.
+	NewDataReader() io.Reader
+	// NewDataWriter returns the writer prepared transfers are stored to.
+	NewDataWriter() io.Writer
+	// Progress returns the progress saved last, SaveProgress saves it.
+	Progress() Progress
+	SaveProgress(progress Progress) *probe.Error
+}
+
+// Progress - state of a Copy or Mirror kept by its Session.
+type Progress struct {
+	// TotalBytes and TotalObjects of all prepared transfers.
+	TotalBytes   int64
+	TotalObjects int
+	// LastCopied is the source transferred last, empty if none was.
+	LastCopied string
+	// Parallel is the number of concurrent transfers in use, resumed runs start with it.
+	Parallel int
+}
+
+// memorySession - Session of runs which are not resumed.
+type memorySession struct {
+	data     bytes.Buffer
+	progress Progress
+}
+
+func (s *memorySession) HasData() bool {
+	return s.data.Len() > 0
+}
+
+func (s *memorySession) NewDataReader() io.Reader {
+	return bytes.NewReader(s.data.Bytes())
+}
+
+func (s *memorySession) NewDataWriter() io.Writer {
+	s.data.Reset()
+	return &s.data
+}
+
+func (s *memorySession) Progress() Progress {
+	return s.progress
+}
+
+func (s *memorySession) SaveProgress(progress Progress) *probe.Error {
+	s.progress = progress
+	return nil
+}
+
+// isCopiedFactory returns a function telling whether a source was transferred by an earlier
+// run, given the stored transfers in order. These are the ones up to lastCopied.
+func isCopiedFactory(lastCopied string) func(string) bool {
+	copied := true // closure
+	return func(sourceURL string) bool {
+		if lastCopied == "" {
+			return false
+		}
+		if copied {
+			if lastCopied == sourceURL {
+				copied = false // from next call onwards we say false.
+			}
+			return true
+		}
+		return false
+	}
+}
@@ -0,0 +1,65 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package transfer runs the transfers of ‘mc cp’ and ‘mc mirror’, usable from other Go
+// programs: Copy and Mirror prepare transfers, keep them in a resumable Session and run them
+// in parallel, reporting progress as Events. It never exits the process or prints, failures
+// are returned as errors.
+package transfer
+
+import (
+	"context"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// Options - how URLs are resolved and objects transferred.
+type Options struct {
+	// NewClient returns a client for a URL, required. Backends have to be
+	// registered with the client package, see client.RegisterBackend.
+	NewClient func(urlStr string) (client.Client, *probe.Error)
+	// Stat, if set, replaces NewClient followed by Client.Stat, e.g. to memoize results.
+	Stat func(urlStr string) (client.Client, *client.Content, *probe.Error)
+
+	// Parallel is the number of concurrent transfers, 0 picks DefaultParallel. Adaptive
+	// adapts it to measured throughput.
+	Parallel int
+	Adaptive bool
+	// Retries is the number of times a transfer failing with a transient error is retried.
+	Retries int
+	// Limits bound bandwidth of transfers, nil for unlimited.
+	Limits *Limits
+	// Stop, once closed, stops starting transfers. Those in progress are finished, unlike
+	// once the context of Copy or Mirror is done.
+	Stop <-chan struct{}
+}
+
+// stat returns client and content of urlStr.
+func (o Options) stat(ctx context.Context, urlStr string) (client.Client, *client.Content, *probe.Error) {
+	if o.Stat != nil {
+		return o.Stat(urlStr)
+	}
+	clnt, err := o.NewClient(urlStr)
+	if err != nil {
+		return nil, nil, err.Trace(urlStr)
+	}
+	content, err := clnt.Stat(ctx)
+	if err != nil {
+		return nil, nil, err.Trace(urlStr)
+	}
+	return clnt, content, nil
+}
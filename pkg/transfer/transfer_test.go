@@ -0,0 +1,250 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/fs"
+	"github.com/minio/minio-xl/pkg/probe"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+// fsOptions - transfer options for local filesystem URLs.
+func fsOptions() Options {
+	return Options{
+		NewClient: func(urlStr string) (client.Client, *probe.Error) {
+			return fs.New(urlStr)
+		},
+	}
+}
+
+// makeSource - a folder of count objects.
+func makeSource(c *C, count int) string {
+	source, e := ioutil.TempDir(os.TempDir(), "transfer-")
+	c.Assert(e, IsNil)
+	for i := 0; i < count; i++ {
+		e = ioutil.WriteFile(filepath.Join(source, "object"+strconv.Itoa(i)), []byte("hello"), 0600)
+		c.Assert(e, IsNil)
+	}
+	return source
+}
+
+func (s *MySuite) TestGuessCopyURLType(c *C) {
+	source := makeSource(c, 1)
+	defer os.RemoveAll(source)
+
+	opts := fsOptions()
+	object := filepath.Join(source, "object0")
+	ctx := context.Background()
+	c.Assert(GuessCopyURLType(ctx, opts, []string{object}, filepath.Join(source, "new")), Equals, CopyURLsTypeA)
+	c.Assert(GuessCopyURLType(ctx, opts, []string{object}, source), Equals, CopyURLsTypeB)
+	c.Assert(GuessCopyURLType(ctx, opts, []string{filepath.Join(source, "...")}, source), Equals, CopyURLsTypeC)
+	c.Assert(GuessCopyURLType(ctx, opts, []string{object, object}, source), Equals, CopyURLsTypeD)
+	c.Assert(GuessCopyURLType(ctx, opts, nil, source), Equals, CopyURLsTypeInvalid)
+	c.Assert(GuessCopyURLType(ctx, opts, []string{object}, ""), Equals, CopyURLsTypeInvalid)
+}
+
+func (s *MySuite) TestPutTargets(c *C) {
+	target, e := ioutil.TempDir(os.TempDir(), "transfer-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(target)
+
+	targetURLs := []string{filepath.Join(target, "object0"), filepath.Join(target, "object1")}
+	err := PutTargets(context.Background(), fsOptions(), targetURLs, int64(len("hello")), strings.NewReader("hello"))
+	c.Assert(err, IsNil)
+	for _, targetURL := range targetURLs {
+		data, e := ioutil.ReadFile(targetURL)
+		c.Assert(e, IsNil)
+		c.Assert(string(data), Equals, "hello")
+	}
+}
+
+// collect counts events until events is closed, bytes for EventProgress.
+func collect(events <-chan Event) <-chan map[EventType]int {
+	countCh := make(chan map[EventType]int, 1)
+	go func() {
+		counts := make(map[EventType]int)
+		for event := range events {
+			if event.Type == EventProgress {
+				counts[event.Type] += int(event.Bytes)
+				continue
+			}
+			counts[event.Type]++
+		}
+		countCh <- counts
+	}()
+	return countCh
+}
+
+func (s *MySuite) TestCopy(c *C) {
+	source := makeSource(c, 10)
+	defer os.RemoveAll(source)
+	target, e := ioutil.TempDir(os.TempDir(), "transfer-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(target)
+
+	opts := fsOptions()
+	opts.Parallel = 4
+	events := make(chan Event)
+	countCh := collect(events)
+	err := Copy(context.Background(), opts, nil, []string{filepath.Join(source, "...")}, target, events)
+	c.Assert(err, IsNil)
+	counts := <-countCh
+	c.Assert(counts[EventScanned], Equals, 10)
+	c.Assert(counts[EventScanDone], Equals, 1)
+	c.Assert(counts[EventStarted], Equals, 10)
+	c.Assert(counts[EventFinished], Equals, 10)
+	c.Assert(counts[EventProgress], Equals, 10*len("hello"))
+	c.Assert(counts[EventFailed], Equals, 0)
+
+	data, e := ioutil.ReadFile(filepath.Join(target, "object7"))
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "hello")
+
+	// Failures are returned rather than fatal, and do not stop other sources.
+	events = make(chan Event)
+	countCh = collect(events)
+	err = Copy(context.Background(), opts, nil, []string{filepath.Join(source, "missing"), filepath.Join(source, "object0")}, target, events)
+	c.Assert(err, Not(IsNil))
+	c.Assert(err.ToGoError(), Equals, TransfersFailed{Count: 1})
+	counts = <-countCh
+	c.Assert(counts[EventScanFailed], Equals, 1)
+	c.Assert(counts[EventFinished], Equals, 1)
+}
+
+func (s *MySuite) TestCopyResume(c *C) {
+	source := makeSource(c, 10)
+	defer os.RemoveAll(source)
+	target, e := ioutil.TempDir(os.TempDir(), "transfer-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(target)
+
+	// Stopped once the first transfer started, transfers in progress are finished.
+	stop := make(chan struct{})
+	opts := fsOptions()
+	opts.Parallel = 1
+	opts.Stop = stop
+	session := &memorySession{}
+	events := make(chan Event)
+	started := 0
+	go func() {
+		c.Assert(Copy(context.Background(), opts, session, []string{filepath.Join(source, "...")}, target, events), IsNil)
+	}()
+	for event := range events {
+		if event.Type == EventStarted {
+			if started == 0 {
+				close(stop)
+			}
+			started++
+		}
+	}
+	c.Assert(started > 0 && started < 10, Equals, true)
+	c.Assert(session.Progress().TotalObjects, Equals, 10)
+	c.Assert(session.Progress().LastCopied, Not(Equals), "")
+
+	// Resuming skips what was copied already.
+	opts.Stop = nil
+	events = make(chan Event)
+	countCh := collect(events)
+	err := Copy(context.Background(), opts, session, []string{filepath.Join(source, "...")}, target, events)
+	c.Assert(err, IsNil)
+	counts := <-countCh
+	c.Assert(counts[EventScanned], Equals, 0)
+	c.Assert(counts[EventSkipped], Equals, started)
+	c.Assert(counts[EventFinished], Equals, 10-started)
+	for i := 0; i < 10; i++ {
+		_, e := os.Stat(filepath.Join(target, "object"+strconv.Itoa(i)))
+		c.Assert(e, IsNil)
+	}
+}
+
+func (s *MySuite) TestMirror(c *C) {
+	source := makeSource(c, 5)
+	defer os.RemoveAll(source)
+	target1, e := ioutil.TempDir(os.TempDir(), "transfer-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(target1)
+	target2, e := ioutil.TempDir(os.TempDir(), "transfer-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(target2)
+
+	// An object already on a target is left alone.
+	e = ioutil.WriteFile(filepath.Join(target1, "object0"), []byte("hello"), 0600)
+	c.Assert(e, IsNil)
+
+	events := make(chan Event)
+	countCh := collect(events)
+	err := Mirror(context.Background(), fsOptions(), nil, source, []string{target1, target2}, events)
+	c.Assert(err, IsNil)
+	counts := <-countCh
+	c.Assert(counts[EventFinished], Equals, 5)
+	// Every source object is read once, however many targets it goes to.
+	c.Assert(counts[EventProgress], Equals, 5*len("hello"))
+
+	for _, target := range []string{target1, target2} {
+		for i := 0; i < 5; i++ {
+			data, e := ioutil.ReadFile(filepath.Join(target, "object"+strconv.Itoa(i)))
+			c.Assert(e, IsNil)
+			c.Assert(string(data), Equals, "hello")
+		}
+	}
+
+	// Nothing left to mirror.
+	events = make(chan Event)
+	countCh = collect(events)
+	err = Mirror(context.Background(), fsOptions(), nil, source, []string{target1, target2}, events)
+	c.Assert(err, IsNil)
+	counts = <-countCh
+	c.Assert(counts[EventStarted], Equals, 0)
+
+	// A missing target is reported, not fatal.
+	events = make(chan Event)
+	countCh = collect(events)
+	err = Mirror(context.Background(), fsOptions(), nil, source, []string{filepath.Join(target1, "missing")}, events)
+	c.Assert(err, Not(IsNil))
+	c.Assert(err.ToGoError(), Equals, TransfersFailed{Count: 1})
+	c.Assert((<-countCh)[EventScanFailed], Equals, 1)
+}
+
+func (s *MySuite) TestCancel(c *C) {
+	source := makeSource(c, 10)
+	defer os.RemoveAll(source)
+	target, e := ioutil.TempDir(os.TempDir(), "transfer-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(target)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Copy(ctx, fsOptions(), nil, []string{filepath.Join(source, "...")}, target, make(chan Event))
+	c.Assert(err, Not(IsNil))
+	c.Assert(err.ToGoError(), Equals, context.Canceled)
+}
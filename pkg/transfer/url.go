@@ -0,0 +1,63 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"context"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+)
+
+// RecursiveSeparator - URLs ending with it stand for a folder and all its contents.
+const RecursiveSeparator = "..."
+
+// IsURLRecursive - find out if requested url is recursive.
+func IsURLRecursive(urlStr string) bool {
+	return strings.HasSuffix(urlStr, RecursiveSeparator)
+}
+
+// StripRecursiveURL - Strip "..." from the URL if present.
+func StripRecursiveURL(urlStr string) string {
+	if !IsURLRecursive(urlStr) {
+		return urlStr
+	}
+	urlStr = strings.TrimSuffix(urlStr, RecursiveSeparator)
+	if urlStr == "" {
+		urlStr = "."
+	}
+	return urlStr
+}
+
+// IsTargetURLDir - Check if the target URL represents folder. It may or may not exist yet.
+func IsTargetURLDir(ctx context.Context, opts Options, targetURL string) bool {
+	targetURLParse := client.NewURL(targetURL)
+	_, targetContent, perr := opts.stat(ctx, targetURL)
+	if perr != nil {
+		if targetURLParse.Path == string(targetURLParse.Separator) && targetURLParse.Scheme != "" {
+			return false
+		}
+		if strings.HasSuffix(targetURLParse.Path, string(targetURLParse.Separator)) {
+			return true
+		}
+		return false
+	}
+	if !targetContent.Type.IsDir() { // Target is a dir.
+		return false
+	}
+	return true
+}
@@ -37,8 +37,7 @@ type pbBar int
 const (
 	pbBarProgress pbBar = iota
 	pbBarFinish
-	pbBarPutError
-	pbBarGetError
+	pbBarRollback
 	pbBarSetCaption
 )
 
@@ -75,12 +74,9 @@ func (b barSend) Progress(progress int64) {
 	b.opCh <- barMsg{Op: pbBarProgress, Arg: progress}
 }
 
-func (b barSend) ErrorPut(size int64) {
-	b.opCh <- barMsg{Op: pbBarPutError, Arg: size}
-}
-
-func (b barSend) ErrorGet(size int64) {
-	b.opCh <- barMsg{Op: pbBarGetError, Arg: size}
+// Rollback takes back progress of bytes which have to be transferred again.
+func (b barSend) Rollback(size int64) {
+	b.opCh <- barMsg{Op: pbBarRollback, Arg: size}
 }
 
 func (b *barSend) SetCaption(c string) {
@@ -180,13 +176,13 @@ func newProgressBar(total int64) *barSend {
 					totalBytesRead += msg.Arg.(int64)
 					bar.Add64(msg.Arg.(int64))
 				}
-			case pbBarPutError:
-				if totalBytesRead > msg.Arg.(int64) {
-					bar.Set64(totalBytesRead - msg.Arg.(int64))
-				}
-			case pbBarGetError:
+			case pbBarRollback:
 				if msg.Arg.(int64) > 0 {
-					bar.Add64(msg.Arg.(int64))
+					totalBytesRead -= msg.Arg.(int64)
+					if totalBytesRead < 0 {
+						totalBytesRead = 0
+					}
+					bar.Set64(totalBytesRead)
 				}
 			case pbBarFinish:
 				if started {
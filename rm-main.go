@@ -17,6 +17,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"path/filepath"
@@ -100,7 +101,9 @@ func rmList(url string) <-chan rmListOnChannel {
 		}
 		return rmListCh
 	}
-	in := clnt.List(true, false)
+	// Stop listing if we bail out early on an error.
+	ctx, cancel := context.WithCancel(globalContext)
+	in := clnt.List(ctx, true, false)
 	var depthFirst func(currentDir string) (*client.Content, bool)
 	depthFirst = func(currentDir string) (*client.Content, bool) {
 		entry, ok := <-in
@@ -138,6 +141,7 @@ func rmList(url string) <-chan rmListOnChannel {
 		}
 	}
 	go func() {
+		defer cancel()
 		depthFirst("")
 		close(rmListCh)
 	}()
@@ -150,7 +154,7 @@ func rmSingle(url string, rmPrint rmPrinterFunc) {
 		errorIf(err.Trace(url), "Unable to get client object for "+url+".")
 		return
 	}
-	err = clnt.Remove(false)
+	err = clnt.Remove(globalContext, false)
 	if err == nil {
 		rmPrint(rmMessage{url})
 	}
@@ -171,7 +175,7 @@ func rmAll(url string, rmPrint rmPrinterFunc) {
 			errorIf(err.Trace(newURL.String()), "Unable to create client object : "+newURL.String()+" .")
 			continue
 		}
-		err = newClnt.Remove(false)
+		err = newClnt.Remove(globalContext, false)
 		if err == nil {
 			rmPrint(rmMessage{rmListCh.keyName})
 		}
@@ -186,7 +190,7 @@ func rmIncompleteUpload(url string, rmPrint rmPrinterFunc) {
 		errorIf(err.Trace(), "Unable to get client object for "+url+" .")
 		return
 	}
-	err = clnt.Remove(true)
+	err = clnt.Remove(globalContext, true)
 	if err == nil {
 		rmPrint(rmMessage{url})
 	}
@@ -200,7 +204,7 @@ func rmAllIncompleteUploads(url string, rmPrint rmPrinterFunc) {
 		return
 	}
 	urlDir := url2Dir(url)
-	for entry := range clnt.List(true, true) {
+	for entry := range clnt.List(globalContext, true, true) {
 		newURL := client.NewURL(urlDir)
 		newURL.Path = filepath.Join(newURL.Path, entry.Content.Name)
 		newClnt, err := url2Client(newURL.String())
@@ -208,7 +212,7 @@ func rmAllIncompleteUploads(url string, rmPrint rmPrinterFunc) {
 			errorIf(err.Trace(newURL.String()), "Unable to create client object : "+newURL.String()+" .")
 			continue
 		}
-		err = newClnt.Remove(true)
+		err = newClnt.Remove(globalContext, true)
 		if err == nil {
 			rmPrint(rmMessage{entry.Content.Name})
 		}
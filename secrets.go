@@ -0,0 +1,294 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/mattn/go-isatty"
+	"github.com/minio/mc/pkg/secrets"
+	"github.com/minio/minio-xl/pkg/probe"
+	"github.com/minio/minio-xl/pkg/quick"
+)
+
+// Secrets of hosts, their secret access keys and session tokens, are kept in config.json unless
+// a store is chosen with ‘mc config secrets’:
+//
+//	passphrase - encrypted in config.json, with a key derived from a passphrase read from
+//	             ‘MC_CONFIG_PASSPHRASE’ or the terminal.
+//	keyring    - in the keyring of the operating system, falling back to ‘file’ on Linux
+//	             without a Secret Service provider.
+//	file       - in ‘secrets.json’ next to config.json, readable by its owner only.
+const (
+	secretsStorePlain      = "plain"
+	secretsStorePassphrase = "passphrase"
+	secretsStoreKeyring    = "keyring"
+	secretsStoreFile       = "file"
+
+	envConfigPassphrase = "MC_CONFIG_PASSPHRASE"
+	secretsFile         = "secrets.json"
+	keyringService      = "mc"
+)
+
+// secretsStores - stores known to ‘mc config secrets’.
+var secretsStores = []string{secretsStorePlain, secretsStorePassphrase, secretsStoreKeyring, secretsStoreFile}
+
+// secretsConfig - where secrets of hosts are stored, in config.json itself if Store is empty.
+type secretsConfig struct {
+	Store string `json:"store,omitempty"`
+	// Sealed holds secrets of the ‘passphrase’ store.
+	Sealed *secrets.Sealed `json:"sealed,omitempty"`
+}
+
+// hostSecrets - secrets of a host kept out of config.json.
+type hostSecrets struct {
+	SecretAccessKey string `json:"secretAccessKey,omitempty"`
+	SessionToken    string `json:"sessionToken,omitempty"`
+}
+
+// configPassphrase - passphrase of the ‘passphrase’ store, read once.
+var configPassphrase string
+
+// getConfigPassphrase returns the passphrase of the ‘passphrase’ store. New passphrases are
+// read twice from the terminal, to catch typing mistakes.
+func getConfigPassphrase(isNew bool) (string, *probe.Error) {
+	if configPassphrase != "" {
+		return configPassphrase, nil
+	}
+	if passphrase := os.Getenv(envConfigPassphrase); passphrase != "" {
+		configPassphrase = passphrase
+		return configPassphrase, nil
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return "", errPassphraseRequired().Trace()
+	}
+	passphrase, err := readSecret("Passphrase for secrets in configuration: ")
+	if err != nil {
+		return "", err.Trace()
+	}
+	if isNew {
+		confirmed, err := readSecret("Confirm passphrase: ")
+		if err != nil {
+			return "", err.Trace()
+		}
+		if confirmed != passphrase {
+			return "", errPassphraseMismatch().Trace()
+		}
+	}
+	if passphrase == "" {
+		return "", errPassphraseRequired().Trace()
+	}
+	configPassphrase = passphrase
+	return configPassphrase, nil
+}
+
+// getSecretsPath - path of the ‘file’ store.
+func getSecretsPath() (string, *probe.Error) {
+	dir, err := getMcConfigDir()
+	if err != nil {
+		return "", err.Trace()
+	}
+	return filepath.Join(dir, secretsFile), nil
+}
+
+// getSecretsKeyring - entry of the ‘keyring’ store, one per config folder.
+func getSecretsKeyring() (secrets.Keyring, *probe.Error) {
+	configPath, err := getMcConfigPath()
+	if err != nil {
+		return secrets.Keyring{}, err.Trace()
+	}
+	return secrets.Keyring{Service: keyringService, Account: configPath}, nil
+}
+
+// readSecrets returns secrets of hosts as stored in JSON, nil if none were stored yet.
+func readSecrets(cfg secretsConfig) ([]byte, *probe.Error) {
+	switch cfg.Store {
+	case secretsStorePassphrase:
+		if cfg.Sealed == nil {
+			return nil, nil
+		}
+		passphrase, err := getConfigPassphrase(false)
+		if err != nil {
+			return nil, err.Trace()
+		}
+		data, e := cfg.Sealed.Open(passphrase)
+		if e == secrets.ErrWrongPassphrase {
+			configPassphrase = ""
+			return nil, errWrongPassphrase().Trace()
+		}
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		return data, nil
+	case secretsStoreKeyring:
+		keyring, err := getSecretsKeyring()
+		if err != nil {
+			return nil, err.Trace()
+		}
+		data, e := keyring.Get()
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		return data, nil
+	case secretsStoreFile:
+		secretsPath, err := getSecretsPath()
+		if err != nil {
+			return nil, err.Trace()
+		}
+		data, e := ioutil.ReadFile(secretsPath)
+		if os.IsNotExist(e) {
+			return nil, nil
+		}
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		return data, nil
+	}
+	return nil, errInvalidSecretsStore(cfg.Store).Trace()
+}
+
+// writeSecrets stores secrets of hosts marshalled to JSON, returning the new settings of the store.
+func writeSecrets(cfg secretsConfig, data []byte) (secretsConfig, *probe.Error) {
+	switch cfg.Store {
+	case secretsStorePassphrase:
+		passphrase, err := getConfigPassphrase(cfg.Sealed == nil)
+		if err != nil {
+			return cfg, err.Trace()
+		}
+		sealed, e := secrets.Seal(passphrase, data)
+		if e != nil {
+			return cfg, probe.NewError(e)
+		}
+		cfg.Sealed = sealed
+		return cfg, nil
+	case secretsStoreKeyring:
+		keyring, err := getSecretsKeyring()
+		if err != nil {
+			return cfg, err.Trace()
+		}
+		if e := keyring.Set(data); e != nil {
+			return cfg, probe.NewError(e)
+		}
+		return cfg, nil
+	case secretsStoreFile:
+		secretsPath, err := getSecretsPath()
+		if err != nil {
+			return cfg, err.Trace()
+		}
+		if e := ioutil.WriteFile(secretsPath, data, 0600); e != nil {
+			return cfg, probe.NewError(e)
+		}
+		return cfg, nil
+	}
+	return cfg, errInvalidSecretsStore(cfg.Store).Trace()
+}
+
+// clearSecrets removes secrets kept outside of config.json by a store no longer in use.
+func clearSecrets(cfg secretsConfig) *probe.Error {
+	switch cfg.Store {
+	case secretsStoreKeyring:
+		keyring, err := getSecretsKeyring()
+		if err != nil {
+			return err.Trace()
+		}
+		if e := keyring.Delete(); e != nil {
+			return probe.NewError(e)
+		}
+	case secretsStoreFile:
+		secretsPath, err := getSecretsPath()
+		if err != nil {
+			return err.Trace()
+		}
+		if e := os.Remove(secretsPath); e != nil && !os.IsNotExist(e) {
+			return probe.NewError(e)
+		}
+	}
+	return nil
+}
+
+// loadSecrets fills in secrets of hosts from their store. Secrets edited into config.json
+// take precedence over stored ones.
+func loadSecrets(conf *configV7) *probe.Error {
+	if conf.Secrets.Store == "" {
+		return nil
+	}
+	data, err := readSecrets(conf.Secrets)
+	if err != nil {
+		return err.Trace(conf.Secrets.Store)
+	}
+	if data == nil {
+		return nil
+	}
+	stored := make(map[string]hostSecrets)
+	if e := json.Unmarshal(data, &stored); e != nil {
+		return probe.NewError(e)
+	}
+	for glob, secret := range stored {
+		hostCfg, ok := conf.Hosts[glob]
+		if !ok {
+			continue
+		}
+		if hostCfg.SecretAccessKey == "" {
+			hostCfg.SecretAccessKey = secret.SecretAccessKey
+		}
+		if hostCfg.SessionToken == "" {
+			hostCfg.SessionToken = secret.SessionToken
+		}
+		conf.Hosts[glob] = hostCfg
+	}
+	return nil
+}
+
+// saveSecrets moves secrets of hosts to their store, returning conf without them to be saved
+// as config.json.
+func saveSecrets(conf *configV7) (quick.Config, *probe.Error) {
+	stripped := *conf
+	stripped.Hosts = make(map[string]hostConfig, len(conf.Hosts))
+	stored := make(map[string]hostSecrets)
+	for glob, hostCfg := range conf.Hosts {
+		if hostCfg.SecretAccessKey != "" || hostCfg.SessionToken != "" {
+			stored[glob] = hostSecrets{SecretAccessKey: hostCfg.SecretAccessKey, SessionToken: hostCfg.SessionToken}
+		}
+		hostCfg.SecretAccessKey = ""
+		hostCfg.SessionToken = ""
+		stripped.Hosts[glob] = hostCfg
+	}
+	data, e := json.Marshal(stored)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	var err *probe.Error
+	if stripped.Secrets, err = writeSecrets(conf.Secrets, data); err != nil {
+		return nil, err.Trace(conf.Secrets.Store)
+	}
+	return quick.New(&stripped)
+}
+
+// maskSecret hides all but the last few characters of secret.
+func maskSecret(secret string) string {
+	const shown = 4
+	if secret == "" {
+		return ""
+	}
+	if len(secret) <= 2*shown {
+		return "********"
+	}
+	return "****" + secret[len(secret)-shown:]
+}
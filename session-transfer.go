@@ -0,0 +1,198 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/transfer"
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// transferDisplay shows the events of a copy or mirror, on a progress bar, or as
+// messages and a summary in quiet and JSON mode.
+type transferDisplay struct {
+	verb string
+	// message printed in quiet and JSON mode when a transfer starts.
+	message func(event transfer.Event) Message
+	scanBar scanBarFunc
+	bar     *barSend
+	acct    *accounter
+}
+
+func newTransferDisplay(verb string, message func(event transfer.Event) Message) *transferDisplay {
+	display := &transferDisplay{verb: verb, message: message}
+	if !globalQuietFlag && !globalJSONFlag { // set up scan bar
+		display.scanBar = scanBarFactory()
+	}
+	return display
+}
+
+// eraseLine prints in new line and adjusts to top so that we don't print over the ongoing bars.
+func (d *transferDisplay) eraseLine() {
+	if !globalQuietFlag && !globalJSONFlag {
+		console.Eraseline()
+	}
+}
+
+// rollback takes back progress of bytes which have to be transferred again.
+func (d *transferDisplay) rollback(n int64) {
+	if d.bar != nil {
+		d.bar.Rollback(n)
+		return
+	}
+	d.acct.Rollback(n)
+}
+
+func (d *transferDisplay) show(event transfer.Event) {
+	switch event.Type {
+	case transfer.EventScanned:
+		if d.scanBar != nil {
+			d.scanBar(event.Source)
+		}
+	case transfer.EventScanFailed:
+		d.eraseLine()
+		if _, ok := event.Err.ToGoError().(transfer.SourceIsDir); ok {
+			errorIf(event.Err.Trace(), "Folder cannot be copied. Please use ‘...’ suffix.")
+			return
+		}
+		errorIf(event.Err.Trace(), fmt.Sprintf("Unable to prepare URLs to %s.", d.verb))
+	case transfer.EventScanDone:
+		if !globalQuietFlag && !globalJSONFlag { // set up progress bar
+			d.bar = newProgressBar(event.Size)
+		} else {
+			d.acct = newAccounter(event.Size)
+		}
+	case transfer.EventSkipped:
+		// Account for objects transferred by an earlier run.
+		if d.bar != nil {
+			d.bar.Progress(event.Size)
+		}
+	case transfer.EventStarted:
+		if d.bar != nil {
+			d.bar.SetCaption(event.Source + ": ")
+		} else {
+			Prints("%s\n", d.message(event))
+		}
+	case transfer.EventProgress:
+		if d.bar != nil {
+			d.bar.Progress(event.Bytes)
+		} else {
+			d.acct.Add(event.Bytes)
+		}
+	case transfer.EventRetrying:
+		d.rollback(event.Bytes)
+		d.eraseLine()
+		errorIf(event.Err.Trace(), fmt.Sprintf("Failed to %s ‘%s’, retrying.", d.verb, event.Source))
+	case transfer.EventFailed:
+		d.rollback(event.Bytes)
+		d.eraseLine()
+		errorIf(event.Err.Trace(), fmt.Sprintf("Failed to %s ‘%s’.", d.verb, event.Source))
+	}
+}
+
+// finish ends the progress bar, or prints the summary in quiet and JSON mode.
+func (d *transferDisplay) finish(palette string) {
+	if d.bar != nil {
+		d.bar.Finish()
+	}
+	if d.acct != nil {
+		console.Println(console.Colorize(palette, d.acct.Finish()))
+	}
+}
+
+// doTransferSession runs a copy or mirror of session with run, configured as recorded in
+// session header, and shows its events. A signal while transfers are prepared drops the
+// session. Once transfers started the first signal stops starting new ones and waits for
+// those in progress, a second one or drainTimeout saves the session right away.
+func doTransferSession(session *sessionV2, verb, palette string, message func(event transfer.Event) Message, run func(opts transfer.Options, events chan<- transfer.Event) *probe.Error) {
+	trapCh := signalTrap()
+
+	opts := transferOptions()
+	opts.Parallel = session.Header.Parallel
+	opts.Adaptive = session.Header.Adaptive
+	opts.Retries = session.Header.maxRetries()
+	limits, err := getBandwidthLimits(session)
+	fatalIf(err.Trace(), "Unable to set up bandwidth limits.")
+	opts.Limits = limits
+	// Closed on the first signal, to stop starting new transfers.
+	stopCh := make(chan struct{})
+	opts.Stop = stopCh
+
+	events := make(chan transfer.Event)
+	errCh := make(chan *probe.Error, 1)
+	go func() {
+		errCh <- run(opts, events)
+	}()
+
+	display := newTransferDisplay(verb, message)
+	scanned := false
+	// Fires when transfers in progress took too long to finish after a signal.
+	var drainTimeoutCh <-chan time.Time
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok { // Transfers are done, their result follows.
+				events = nil
+				continue
+			}
+			if event.Type == transfer.EventScanDone {
+				scanned = true
+			}
+			display.show(event)
+			// all the cases which are handled where session should be saved are contained in the following
+			// switch case, we shouldn't be saving sessions for all errors since some errors might need to be
+			// reported to user properly.
+			//
+			// Transient errors have already been retried at this point.
+			if event.Type == transfer.EventFailed && transfer.IsRetryable(event.Err) {
+				gracefulSessionSave(session)
+			}
+		case err = <-errCh:
+			if drainTimeoutCh != nil { // Interrupted, transfers in progress are done.
+				display.eraseLine()
+				gracefulSessionSave(session)
+			}
+			display.finish(palette)
+			if err != nil {
+				// Failed transfers were reported as they failed.
+				if _, ok := err.ToGoError().(transfer.TransfersFailed); !ok {
+					fatalIf(err.Trace(), fmt.Sprintf("Unable to %s.", verb))
+				}
+			}
+			return
+		case <-trapCh: // Receive interrupt notification.
+			display.eraseLine()
+			if !scanned {
+				session.Delete() // If we are interrupted during the URL scanning, we drop the session.
+				os.Exit(0)
+			}
+			if drainTimeoutCh != nil { // Second signal, do not wait any further.
+				gracefulSessionSave(session)
+			}
+			console.Infoln("Waiting for transfers in progress to finish. Interrupt again to exit immediately.")
+			close(stopCh)
+			drainTimeoutCh = time.After(drainTimeout)
+		case <-drainTimeoutCh:
+			display.eraseLine()
+			gracefulSessionSave(session)
+		}
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/transfer"
 	"github.com/minio/minio-xl/pkg/probe"
 	"github.com/minio/minio-xl/pkg/quick"
 )
@@ -46,14 +47,28 @@ func migrateSessionV1ToV2() {
 
 // sessionV2Header
 type sessionV2Header struct {
-	Version      string    `json:"version"`
-	When         time.Time `json:"time"`
-	RootPath     string    `json:"working-folder"`
-	CommandType  string    `json:"command-type"`
-	CommandArgs  []string  `json:"cmd-args"`
-	LastCopied   string    `json:"last-copied"`
-	TotalBytes   int64     `json:"total-bytes"`
-	TotalObjects int       `json:"total-objects"`
+	Version       string    `json:"version"`
+	When          time.Time `json:"time"`
+	RootPath      string    `json:"working-folder"`
+	CommandType   string    `json:"command-type"`
+	CommandArgs   []string  `json:"cmd-args"`
+	LastCopied    string    `json:"last-copied"`
+	TotalBytes    int64     `json:"total-bytes"`
+	TotalObjects  int       `json:"total-objects"`
+	MaxRetries    *int      `json:"max-retries,omitempty"`
+	Parallel      int       `json:"parallel,omitempty"`
+	Adaptive      bool      `json:"adaptive-parallel,omitempty"`
+	LimitUpload   string    `json:"limit-upload,omitempty"`
+	LimitDownload string    `json:"limit-download,omitempty"`
+}
+
+// maxRetries number of times a failed transfer is retried, sessions saved before it was
+// recorded retry as many times as a new transfer does by default.
+func (h sessionV2Header) maxRetries() int {
+	if h.MaxRetries == nil {
+		return transfer.DefaultRetryLimit
+	}
+	return *h.MaxRetries
 }
 
 // SessionMessage container for session messages
@@ -132,6 +147,8 @@ func (s sessionV2) Info() {
 }
 
 func gracefulSessionSave(session *sessionV2) {
+	// Abort requests in progress, instead of waiting on them.
+	globalCancel()
 	session.Close()
 	session.Info()
 	os.Exit(0)
@@ -159,6 +176,31 @@ func (s *sessionV2) NewDataWriter() io.Writer {
 	return io.Writer(s.DataFP)
 }
 
+// Progress provides the transfer progress recorded in session header.
+func (s *sessionV2) Progress() transfer.Progress {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return transfer.Progress{
+		TotalBytes:   s.Header.TotalBytes,
+		TotalObjects: s.Header.TotalObjects,
+		LastCopied:   s.Header.LastCopied,
+		Parallel:     s.Header.Parallel,
+	}
+}
+
+// SaveProgress records transfer progress in session header and saves this session.
+func (s *sessionV2) SaveProgress(progress transfer.Progress) *probe.Error {
+	s.mutex.Lock()
+	s.Header.TotalBytes = progress.TotalBytes
+	s.Header.TotalObjects = progress.TotalObjects
+	s.Header.LastCopied = progress.LastCopied
+	s.Header.Parallel = progress.Parallel
+	s.mutex.Unlock()
+
+	return s.Save().Trace(s.SessionID)
+}
+
 // Save this session
 func (s *sessionV2) Save() *probe.Error {
 	s.mutex.Lock()
@@ -277,26 +319,3 @@ func loadSessionV2(sid string) (*sessionV2, *probe.Error) {
 
 	return s, nil
 }
-
-// Create a factory function to simplify checking if an
-// object has been copied or not.
-// isCopied(URL) -> true or false
-func isCopiedFactory(lastCopied string) func(string) bool {
-	copied := true // closure
-	return func(sourceURL string) bool {
-		if sourceURL == "" {
-			fatalIf(errInvalidArgument().Trace(), "Empty source argument passed.")
-		}
-		if lastCopied == "" {
-			return false
-		}
-
-		if copied {
-			if lastCopied == sourceURL {
-				copied = false // from next call onwards we say false.
-			}
-			return true
-		}
-		return false
-	}
-}
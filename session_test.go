@@ -17,10 +17,12 @@
 package main
 
 import (
+	"encoding/json"
 	"os"
 	"regexp"
 
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/transfer"
 	. "gopkg.in/check.v1"
 )
 
@@ -106,3 +108,13 @@ func (s *TestSuite) TestSessionContext(c *C) {
 	// reset back
 	console.IsExited = false
 }
+
+func (s *TestSuite) TestSessionMaxRetries(c *C) {
+	// sessions saved before retries were recorded resume with the default
+	var header sessionV2Header
+	c.Assert(json.Unmarshal([]byte(`{"version":"1.1.0","command-type":"cp"}`), &header), IsNil)
+	c.Assert(header.maxRetries(), Equals, transfer.DefaultRetryLimit)
+
+	c.Assert(json.Unmarshal([]byte(`{"version":"1.1.0","command-type":"cp","max-retries":0}`), &header), IsNil)
+	c.Assert(header.maxRetries(), Equals, 0)
+}
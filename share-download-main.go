@@ -17,6 +17,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -105,12 +106,20 @@ func doShareDownloadURL(targetURL string, recursive bool, expires time.Duration)
 	if expires.Seconds() > 604800 {
 		return probe.NewError(errors.New("Too high expires, expiration cannot be larger than 7 days."))
 	}
-	for contentCh := range clnt.List(recursive, false) {
+	// Stop listing if we bail out early on an error.
+	ctx, cancel := context.WithCancel(globalContext)
+	defer cancel()
+	for contentCh := range clnt.List(ctx, recursive, false) {
 		if contentCh.Err != nil {
 			return contentCh.Err.Trace()
 		}
 		var newClnt client.Client
-		newClnt, err = url2Client(getNewTargetURL(clnt.URL(), contentCh.Content.Name))
+		var newURL string
+		newURL, err = getNewTargetURL(clnt.URL(), contentCh.Content.Name)
+		if err != nil {
+			return err.Trace()
+		}
+		newClnt, err = url2Client(newURL)
 		if err != nil {
 			return err.Trace()
 		}
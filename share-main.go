@@ -24,7 +24,6 @@ import (
 	"time"
 
 	"github.com/minio/cli"
-	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/minio-xl/pkg/probe"
 )
@@ -70,18 +69,20 @@ type ShareMessageV3 struct {
 	Key         string            `json:"keyName"`
 }
 
-// ShareMessage ...
-type ShareMessage ShareMessageV3
+// ShareMessage - a share as printed, with the URL uploads are posted to resolved
+// when the share is made.
+type ShareMessage struct {
+	Expiry      time.Duration
+	DownloadURL string
+	UploadInfo  map[string]string
+	Key         string
+	PostURL     string
+}
 
-// String - regular colorized message
-func (s ShareMessage) String() string {
-	if len(s.DownloadURL) > 0 {
-		return console.Colorize("Share", fmt.Sprintf("%s", s.DownloadURL))
-	}
+// uploadCommand - curl command uploading a file to the share.
+func (s ShareMessage) uploadCommand() string {
 	var key string
-	URL := client.NewURL(s.Key)
-	postURL := URL.Scheme + URL.SchemeSeparator + URL.Host + string(URL.Separator) + s.UploadInfo["bucket"] + " "
-	curlCommand := "curl " + postURL
+	curlCommand := "curl " + s.PostURL + " "
 	for k, v := range s.UploadInfo {
 		if k == "key" {
 			key = v
@@ -89,9 +90,16 @@ func (s ShareMessage) String() string {
 		}
 		curlCommand = curlCommand + fmt.Sprintf("-F %s=%s ", k, v)
 	}
-	curlCommand = curlCommand + fmt.Sprintf("-F key=%s ", key) + "-F file=@<FILE> "
+	return curlCommand + fmt.Sprintf("-F key=%s ", key) + "-F file=@<FILE> "
+}
+
+// String - regular colorized message
+func (s ShareMessage) String() string {
+	if len(s.DownloadURL) > 0 {
+		return console.Colorize("Share", fmt.Sprintf("%s", s.DownloadURL))
+	}
 	emphasize := console.Colorize("File", "<FILE>")
-	curlCommand = strings.Replace(curlCommand, "<FILE>", emphasize, -1)
+	curlCommand := strings.Replace(s.uploadCommand(), "<FILE>", emphasize, -1)
 	return console.Colorize("Share", fmt.Sprintf("%s", curlCommand))
 }
 
@@ -110,26 +118,13 @@ func (s ShareMessage) JSON() string {
 			Key:         s.Key,
 		})
 	} else {
-		var key string
-		URL := client.NewURL(s.Key)
-		postURL := URL.Scheme + URL.SchemeSeparator + URL.Host + string(URL.Separator) + s.UploadInfo["bucket"] + " "
-		curlCommand := "curl " + postURL
-		for k, v := range s.UploadInfo {
-			if k == "key" {
-				key = v
-				continue
-			}
-			curlCommand = curlCommand + fmt.Sprintf("-F %s=%s ", k, v)
-		}
-		curlCommand = curlCommand + fmt.Sprintf("-F key=%s ", key) + "-F file=@<FILE> "
-
 		shareMessageBytes, err = json.Marshal(struct {
 			Expiry        humanizedTime `json:"expiry"`
 			UploadCommand string        `json:"uploadCommand"`
 			Key           string        `json:"keyName"`
 		}{
 			Expiry:        timeDurationToHumanizedTime(s.Expiry),
-			UploadCommand: curlCommand,
+			UploadCommand: s.uploadCommand(),
 			Key:           s.Key,
 		})
 	}
@@ -60,7 +60,9 @@ func checkShareUploadSyntax(ctx *cli.Context) {
 		cli.ShowCommandHelpAndExit(ctx, "upload", 1) // last argument is exit code
 	}
 	url := stripRecursiveURL(strings.TrimSpace(args.Get(0)))
-	if !isObjectKeyPresent(url) {
+	present, err := isObjectKeyPresent(url)
+	fatalIf(err.Trace(url), "Unable to read configuration of ‘"+url+"’.")
+	if !present {
 		fatalIf(errDummy().Trace(), fmt.Sprintf("Upload location needs object key ‘%s’.", strings.TrimSpace(args.Get(0))))
 	}
 	if strings.HasSuffix(strings.TrimSpace(args.Get(0)), "/") {
@@ -113,10 +115,15 @@ func doShareUploadURL(targetURL string, recursive bool, expires time.Duration, c
 		Key = Key + recursiveSeparator
 		m["key"] = m["key"] + "<FILE>"
 	}
+	postURL, err := getSharePostURL(targetURL, m["bucket"])
+	if err != nil {
+		return err.Trace(targetURL)
+	}
 	shareMessage := ShareMessage{
 		Expiry:     expires,
 		UploadInfo: m,
 		Key:        Key,
+		PostURL:    postURL,
 	}
 	shareMessageV3 := ShareMessageV3{
 		Expiry:     expires,
@@ -192,55 +192,44 @@ func createSharedURLsDataFile() *probe.Error {
 	return nil
 }
 
-func getNewTargetURL(targetParser *client.URL, name string) string {
-	match, _ := filepath.Match("*.s3*.amazonaws.com", targetParser.Host)
-	if match {
+func getNewTargetURL(targetParser *client.URL, name string) (string, *probe.Error) {
+	lookup, err := getBucketLookup(targetParser.String())
+	if err != nil {
+		return "", err.Trace()
+	}
+	if targetParser.IsVirtualHostStyle(lookup) {
 		targetParser.Path = string(targetParser.Separator) + name
 	} else {
-		targetParser.Path = string(targetParser.Separator) + path2Bucket(targetParser) + string(targetParser.Separator) + name
+		bucketName, _ := targetParser.BucketAndObject(lookup)
+		targetParser.Path = string(targetParser.Separator) + bucketName + string(targetParser.Separator) + name
 	}
-	return targetParser.String()
+	return targetParser.String(), nil
 }
 
-// this code is necessary since, share only operates on cloud storage URLs not filesystem
-func path2Bucket(u *client.URL) (bucketName string) {
-	pathSplits := strings.SplitN(u.Path, "?", 2)
-	splits := strings.SplitN(pathSplits[0], string(u.Separator), 3)
-	switch len(splits) {
-	case 0, 1:
-		bucketName = ""
-	case 2:
-		bucketName = splits[1]
-	case 3:
-		bucketName = splits[1]
+// getSharePostURL returns the URL which uploads shared for bucketName on the host of URL are posted to.
+func getSharePostURL(URL, bucketName string) (string, *probe.Error) {
+	lookup, err := getBucketLookup(URL)
+	if err != nil {
+		return "", err.Trace()
 	}
-	return bucketName
+	u := client.NewURL(URL)
+	postURL := u.Scheme + u.SchemeSeparator + u.Host + string(u.Separator)
+	if !u.IsVirtualHostStyle(lookup) {
+		postURL += bucketName
+	}
+	return postURL, nil
 }
 
 // this code is necessary since, share only operates on cloud storage URLs not filesystem
-func isObjectKeyPresent(url string) bool {
-	u := client.NewURL(url)
-	path := u.Path
-	match, _ := filepath.Match("*.s3*.amazonaws.com", u.Host)
-	switch {
-	case match == true:
-		hostSplits := strings.SplitN(u.Host, ".", 2)
-		path = string(u.Separator) + hostSplits[0] + u.Path
-	}
-	pathSplits := strings.SplitN(path, "?", 2)
-	splits := strings.SplitN(pathSplits[0], string(u.Separator), 3)
-	switch len(splits) {
-	case 0, 1:
-		return false
-	case 2:
-		return false
-	case 3:
-		if splits[2] == "" {
-			return false
-		}
-		return true
+func isObjectKeyPresent(url string) (bool, *probe.Error) {
+	lookup, err := getBucketLookup(url)
+	if err != nil {
+		return false, err.Trace()
 	}
-	return false
+	_, objectName := client.NewURL(url).BucketAndObject(lookup)
+	// Query strings are no part of the object key.
+	objectName = strings.SplitN(objectName, "?", 2)[0]
+	return objectName != "", nil
 }
 
 func setSharePalette(style string) {
@@ -18,6 +18,8 @@ package main
 
 import (
 	"os"
+	"strings"
+	"time"
 
 	"github.com/minio/mc/pkg/console"
 	. "gopkg.in/check.v1"
@@ -68,3 +70,16 @@ func (s *TestSuite) TestShareSuccess(c *C) {
 	// reset back
 	console.IsExited = false
 }
+
+func (s *TestSuite) TestShareMessage(c *C) {
+	// Upload commands are printed from the message alone, no configuration is read.
+	message := ShareMessage{
+		Expiry:     time.Hour,
+		UploadInfo: map[string]string{"key": "object", "policy": "p"},
+		Key:        "http://localhost:9000/bucket/object",
+		PostURL:    "http://localhost:9000/bucket",
+	}
+	c.Assert(message.uploadCommand(), Equals, "curl http://localhost:9000/bucket -F policy=p -F key=object -F file=@<FILE> ")
+	c.Assert(strings.Contains(message.JSON(), `"uploadCommand":"curl http://localhost:9000/bucket `), Equals, true)
+	c.Assert(console.IsExited, Equals, false)
+}
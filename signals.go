@@ -16,31 +16,82 @@
 
 package main
 
-// signalTrap traps the registered signals and notifies the caller.
 import (
 	"os"
 	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 )
 
-func signalTrap(sig ...os.Signal) <-chan bool {
-	// channel to notify the caller.
-	trapCh := make(chan bool, 1)
+// drainTimeout - time given to in-progress transfers to complete
+// after the first signal, before the session is saved regardless.
+const drainTimeout = 30 * time.Second
+
+// cancelTimeout - time given to a command to return once its requests
+// in progress are cancelled, before it exits regardless.
+const cancelTimeout = 2 * time.Second
+
+// sessionSignals are the signals on which cp and mirror save their session.
+var sessionSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
+
+// sessionTrap is the channel of a command which handles signals itself.
+var sessionTrap struct {
+	sync.Mutex
+	trapCh chan<- bool
+}
 
-	go func(chan<- bool) {
+var trapSignalsOnce sync.Once
+
+// trapSignals installs the signal handler shared by all commands. Commands
+// which save a session are notified through signalTrap, all others have
+// their requests in progress cancelled through globalContext and exit.
+func trapSignals() {
+	trapSignalsOnce.Do(func() {
 		// channel to receive signals.
 		sigCh := make(chan os.Signal, 1)
-		defer close(sigCh)
 
 		// `signal.Notify` registers the given channel to
 		// receive notifications of the specified signals.
-		signal.Notify(sigCh, sig...)
+		signal.Notify(sigCh, sessionSignals...)
+
+		go func() {
+			var exitCh <-chan time.Time
+			for {
+				select {
+				case <-sigCh:
+					sessionTrap.Lock()
+					trapCh := sessionTrap.trapCh
+					sessionTrap.Unlock()
+					if trapCh != nil {
+						trapCh <- true
+						continue
+					}
+					if exitCh != nil { // Second signal, do not wait any further.
+						os.Exit(1)
+					}
+					globalCancel()
+					exitCh = time.After(cancelTimeout)
+				case <-exitCh:
+					os.Exit(1)
+				}
+			}
+		}()
+	})
+}
 
-		// Wait for the signal
-		<-sigCh
+// signalTrap takes over signals from the shared handler and notifies
+// the caller for every signal received, a repeated signal may be
+// treated differently.
+func signalTrap() <-chan bool {
+	trapSignals()
+
+	// channel to notify the caller.
+	trapCh := make(chan bool, 1)
 
-		// Notify the caller
-		trapCh <- true
-	}(trapCh)
+	sessionTrap.Lock()
+	sessionTrap.trapCh = trapCh
+	sessionTrap.Unlock()
 
 	return trapCh
 }
@@ -0,0 +1,56 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/minio/minio-xl/pkg/probe"
+)
+
+// stdinReader is shared by all prompts, a reader of its own would keep input
+// buffered which the next prompt needs.
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// readLine prompts for a line on the terminal.
+func readLine(prompt string) (string, *probe.Error) {
+	fmt.Fprint(os.Stderr, prompt)
+	line, e := stdinReader.ReadString('\n')
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// readSecret prompts for a secret on the terminal, without echoing it so that it stays off
+// the screen and out of shell history.
+func readSecret(prompt string) (string, *probe.Error) {
+	fmt.Fprint(os.Stderr, prompt)
+	if e := setEcho(false); e != nil {
+		return "", probe.NewError(e)
+	}
+	line, e := stdinReader.ReadString('\n')
+	setEcho(true)
+	fmt.Fprintln(os.Stderr)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
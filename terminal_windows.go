@@ -0,0 +1,48 @@
+//go:build windows
+// +build windows
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// enableEchoInput - console mode echoing typed characters.
+const enableEchoInput = 0x0004
+
+// setEcho turns echoing of console input on or off.
+func setEcho(on bool) error {
+	handle := syscall.Handle(os.Stdin.Fd())
+	var mode uint32
+	if e := syscall.GetConsoleMode(handle, &mode); e != nil {
+		return e
+	}
+	if on {
+		mode |= enableEchoInput
+	} else {
+		mode &^= enableEchoInput
+	}
+	if r, _, e := procSetConsoleMode.Call(uintptr(handle), uintptr(mode)); r == 0 {
+		return e
+	}
+	return nil
+}
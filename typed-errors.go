@@ -18,7 +18,11 @@ package main
 
 import (
 	"errors"
+	"strconv"
+	"strings"
 
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/transfer"
 	"github.com/minio/minio-xl/pkg/probe"
 )
 
@@ -31,10 +35,6 @@ var (
 		return probe.NewError(errors.New("Invalid arguments provided, cannot proceed.")).Untrace()
 	}
 
-	errSourceListEmpty = func() *probe.Error {
-		return probe.NewError(errors.New("Source argument list is empty.")).Untrace()
-	}
-
 	errInvalidGlobURL = func(glob, request string) *probe.Error {
 		return probe.NewError(errors.New("Error reading glob URL ‘" + glob + "’ while comparing with ‘" + request + "’.")).Untrace()
 	}
@@ -47,25 +47,52 @@ var (
 		return probe.NewError(errors.New("Unable to initialize client for URL ‘" + URL + "’.")).Untrace()
 	}
 
-	errInvalidSource = func(URL string) *probe.Error {
-		return probe.NewError(errors.New("Invalid source ‘" + URL + "’.")).Untrace()
-	}
-
-	errInvalidTarget = func(URL string) *probe.Error {
-		return probe.NewError(errors.New("Invalid target ‘" + URL + "’.")).Untrace()
-	}
-
-	errSourceNotRecursive = func(URL string) *probe.Error {
-		return probe.NewError(errors.New("Source ‘" + URL + "’ is not recursive.")).Untrace()
-	}
-
-	errSourceIsNotDir = func(URL string) *probe.Error {
-		return probe.NewError(errors.New("Source ‘" + URL + "’ is not a folder.")).Untrace()
-	}
 	errSourceIsDir = func(URL string) *probe.Error {
 		return probe.NewError(errors.New("Source ‘" + URL + "’ is a folder.")).Untrace()
 	}
 	errNotAnObject = func(URL string) *probe.Error {
 		return probe.NewError(errors.New("‘" + URL + "’ is not an object.")).Untrace()
 	}
+	errInvalidParallel = func(value string) *probe.Error {
+		return probe.NewError(errors.New("Invalid number of parallel transfers ‘" + value + "’, please use a number between 1 and " + strconv.Itoa(transfer.MaxParallel) + " or ‘" + parallelAuto + "’.")).Untrace()
+	}
+	errInvalidRate = func(value string) *probe.Error {
+		return probe.NewError(errors.New("Invalid rate ‘" + value + "’, please use a rate such as ‘20MiB/s’ or ‘" + rateUnlimited + "’.")).Untrace()
+	}
+	errInvalidTimeout = func(value string) *probe.Error {
+		return probe.NewError(errors.New("Invalid timeout ‘" + value + "’ in host configuration, please use a duration such as ‘30s’.")).Untrace()
+	}
+	errInvalidLookup = func(value string) *probe.Error {
+		return probe.NewError(errors.New("Invalid lookup ‘" + value + "’ in host configuration, please use ‘" + client.LookupAuto + "’, ‘" + client.LookupPath + "’ or ‘" + client.LookupDNS + "’.")).Untrace()
+	}
+	errInvalidClockOffset = func(value string) *probe.Error {
+		return probe.NewError(errors.New("Invalid clock offset ‘" + value + "’ in host configuration, please use a duration such as ‘-90s’ or ‘" + clockOffsetAuto + "’.")).Untrace()
+	}
+	errInvalidFallbackAPI = func(value string) *probe.Error {
+		return probe.NewError(errors.New("Invalid fallback API ‘" + value + "’ in host configuration, please use one of ‘" + strings.Join(client.BackendAPIs(), "’, ‘") + "’.")).Untrace()
+	}
+	errInvalidBodyLimit = func(value string) *probe.Error {
+		return probe.NewError(errors.New("Invalid body limit ‘" + value + "’, please use a size such as ‘64KiB’ or ‘" + bodyLimitUnlimited + "’.")).Untrace()
+	}
+	errInvalidEnvHost = func(name string) *probe.Error {
+		return probe.NewError(errors.New("Invalid ‘" + name + "’, please use ‘" + envHostPrefix + "<alias>=https://<access-key>:<secret-key>[:<session-token>]@<host>’.")).Untrace()
+	}
+	errInvalidCredentialProcess = func(command string) *probe.Error {
+		return probe.NewError(errors.New("Invalid credentials printed by credential_process ‘" + command + "’, expected JSON with ‘Version’ 1, ‘AccessKeyId’ and ‘SecretAccessKey’.")).Untrace()
+	}
+	errInvalidSchedule = func(value string) *probe.Error {
+		return probe.NewError(errors.New("Invalid time of day ‘" + value + "’ in bandwidth schedule, please use ‘HH:MM’.")).Untrace()
+	}
+	errInvalidSecretsStore = func(store string) *probe.Error {
+		return probe.NewError(errors.New("Invalid secrets store ‘" + store + "’, please use ‘" + strings.Join(secretsStores, "’, ‘") + "’.")).Untrace()
+	}
+	errPassphraseRequired = func() *probe.Error {
+		return probe.NewError(errors.New("Passphrase required to access secrets in configuration, please set ‘" + envConfigPassphrase + "’ or run on a terminal.")).Untrace()
+	}
+	errPassphraseMismatch = func() *probe.Error {
+		return probe.NewError(errors.New("Passphrases do not match.")).Untrace()
+	}
+	errWrongPassphrase = func() *probe.Error {
+		return probe.NewError(errors.New("Unable to decrypt secrets in configuration, wrong passphrase.")).Untrace()
+	}
 )
@@ -19,14 +19,16 @@ package main
 import (
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/transfer"
 	"github.com/minio/minio-xl/pkg/probe"
 )
 
 // ``...`` recursiveSeparator
 const (
-	recursiveSeparator = "..."
+	recursiveSeparator = transfer.RecursiveSeparator
 )
 
 // urlJoinPath Join a path to existing URL.
@@ -43,19 +45,12 @@ func urlJoinPath(url1, url2 string) string {
 
 // isURLRecursive - find out if requested url is recursive.
 func isURLRecursive(urlStr string) bool {
-	return strings.HasSuffix(urlStr, recursiveSeparator)
+	return transfer.IsURLRecursive(urlStr)
 }
 
 // stripRecursiveURL - Strip "..." from the URL if present.
 func stripRecursiveURL(urlStr string) string {
-	if !isURLRecursive(urlStr) {
-		return urlStr
-	}
-	urlStr = strings.TrimSuffix(urlStr, recursiveSeparator)
-	if urlStr == "" {
-		urlStr = "."
-	}
-	return urlStr
+	return transfer.StripRecursiveURL(urlStr)
 }
 
 // args2URLs extracts source and target URLs from command-line args.
@@ -73,13 +68,61 @@ func args2URLs(args []string) ([]string, *probe.Error) {
 	return URLs, nil
 }
 
+// statCacheEntry is a memoized successful url2Stat result.
+type statCacheEntry struct {
+	client  client.Client
+	content *client.Content
+}
+
+// statCache memoizes url2Stat while command line arguments are validated, they
+// are otherwise stat'ed several times over. Only access via start/stop functions.
+var statCache = struct {
+	sync.Mutex
+	entries map[string]statCacheEntry
+}{}
+
+// startStatCache - memoize url2Stat results until stopStatCache is called.
+func startStatCache() {
+	statCache.Lock()
+	defer statCache.Unlock()
+	statCache.entries = make(map[string]statCacheEntry)
+}
+
+// stopStatCache - drop memoized url2Stat results, objects may change from here on.
+func stopStatCache() {
+	statCache.Lock()
+	defer statCache.Unlock()
+	statCache.entries = nil
+}
+
 // url2Stat returns stat info for URL.
 func url2Stat(urlStr string) (client client.Client, content *client.Content, err *probe.Error) {
+	statCache.Lock()
+	entry, ok := statCache.entries[urlStr]
+	statCache.Unlock()
+	if !ok {
+		entry.client, entry.content, err = url2StatNoCache(urlStr)
+		if err != nil {
+			return nil, nil, err.Trace(urlStr)
+		}
+		statCache.Lock()
+		if statCache.entries != nil {
+			statCache.entries[urlStr] = entry
+		}
+		statCache.Unlock()
+	}
+	// Callers are free to modify their copy of content.
+	contentCopy := *entry.content
+	return entry.client, &contentCopy, nil
+}
+
+// url2StatNoCache returns stat info for URL, always asking the backend.
+func url2StatNoCache(urlStr string) (client client.Client, content *client.Content, err *probe.Error) {
 	client, err = url2Client(urlStr)
 	if err != nil {
 		return nil, nil, err.Trace(urlStr)
 	}
-	content, err = client.Stat()
+	content, err = client.Stat(globalContext)
 	if err != nil {
 		return nil, nil, err.Trace(urlStr)
 	}
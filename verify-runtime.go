@@ -27,7 +27,7 @@ import (
 	"github.com/minio/minio-xl/pkg/probe"
 )
 
-var minGolangRuntimeVersion = "1.5.1"
+var minGolangRuntimeVersion = "1.13"
 
 // following code handles the current Golang release styles, we might have to update them in future
 // if golang community divulges from the below formatting style.
@@ -55,15 +55,22 @@ func (v1 version) String() string {
 	return fmt.Sprintf("%s%s%s", v1.major, v1.minor, v1.patch)
 }
 
-func (v1 version) Version() int {
-	ver, e := strconv.Atoi(v1.String())
-	fatalIf(probe.NewError(e), "Unable to convert version string to an integer.")
+func (v1 version) Version() []int {
+	var ver []int
+	for _, s := range []string{v1.major, v1.minor, v1.patch} {
+		n, e := strconv.Atoi(s)
+		fatalIf(probe.NewError(e), "Unable to convert version string to an integer.")
+		ver = append(ver, n)
+	}
 	return ver
 }
 
 func (v1 version) LessThan(v2 version) bool {
-	if v1.Version() < v2.Version() {
-		return true
+	ver1, ver2 := v1.Version(), v2.Version()
+	for i := range ver1 {
+		if ver1[i] != ver2[i] {
+			return ver1[i] < ver2[i]
+		}
 	}
 	return false
 }
@@ -86,7 +93,7 @@ func checkGolangRuntimeVersion() {
 	v2 := newVersion(minGolangRuntimeVersion)
 	if v1.LessThan(v2) {
 		fatalIf(errDummy().Trace(),
-			"Old Golang runtime version ‘"+v1.String()+"’ detected., ‘mc’ requires minimum go1.5.1 or later.")
+			"Old Golang runtime version ‘"+v1.String()+"’ detected., ‘mc’ requires minimum go"+minGolangRuntimeVersion+" or later.")
 	}
 }
 